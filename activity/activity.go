@@ -0,0 +1,163 @@
+// Package activity pushes project, application and engagement events to the GraphQL
+// subscriptions connected to this replica, the way messaging does for messages. Events from every
+// replica arrive through the outbox Listener, see Broker.Listen.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before new ones are
+// dropped for it. Dropped events can still be seen by querying again.
+const subscriberBuffer = 16
+
+// hub fans values out to the subscriptions registered under their keys.
+type hub[T any] struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan T]struct{}
+}
+
+func (h *hub[T]) subscribe(ctx context.Context, key uint) <-chan T {
+	ch := make(chan T, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[uint]map[chan T]struct{})
+	}
+	if h.subs[key] == nil {
+		h.subs[key] = make(map[chan T]struct{})
+	}
+	h.subs[key][ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subs[key], ch)
+		if len(h.subs[key]) == 0 {
+			delete(h.subs, key)
+		}
+		h.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+func (h *hub[T]) publish(keys []uint, v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range keys {
+		for ch := range h.subs[key] {
+			select {
+			case ch <- v:
+			default:
+			}
+		}
+	}
+}
+
+// Broker holds this replica's subscriptions to projects' changes and to the applications and
+// engagements of the nonprofits a user belongs to.
+type Broker struct {
+	projects     hub[*model.Project]     // by project ID
+	applications hub[*model.Application] // by recipient user ID
+	engagements  hub[*model.Engagement]  // by recipient user ID
+}
+
+func NewBroker() *Broker {
+	return &Broker{}
+}
+
+// ProjectUpdated returns a channel receiving the project each time its status changes, until ctx
+// is done.
+func (b *Broker) ProjectUpdated(ctx context.Context, projectID uint) <-chan *model.Project {
+	return b.projects.subscribe(ctx, projectID)
+}
+
+// ApplicationReceived returns a channel receiving the new applications to the projects of the
+// nonprofits userID is a member of, until ctx is done.
+func (b *Broker) ApplicationReceived(ctx context.Context, userID uint) <-chan *model.Application {
+	return b.applications.subscribe(ctx, userID)
+}
+
+// EngagementStarted returns a channel receiving the engagements userID starts as a volunteer or
+// that start on the projects of the nonprofits they are a member of, until ctx is done.
+func (b *Broker) EngagementStarted(ctx context.Context, userID uint) <-chan *model.Engagement {
+	return b.engagements.subscribe(ctx, userID)
+}
+
+// Listen registers the handlers publishing ProjectStatusChanged, ApplicationSubmitted and
+// EngagementStarted events to the subscriptions on this replica.
+func (b *Broker) Listen(listener *outbox.Listener, db *gorm.DB) {
+	logger := listener.Logger
+	listener.Handle(outbox.ProjectStatusChanged, func(ctx context.Context, event *outbox.Event) {
+		var payload outbox.ProjectPayload
+		if err := event.Decode(&payload); err != nil {
+			logger.Error().Err(err).Msg("Relaying project update failed")
+			return
+		}
+		var project model.Project
+		if err := db.WithContext(ctx).Preload("SkillsNeeded").Preload("Nonprofit").First(&project, payload.ProjectID).Error; err != nil {
+			logger.Error().Err(err).Uint("project_id", payload.ProjectID).Msg("Relaying project update failed")
+			return
+		}
+		b.projects.publish([]uint{project.ID}, &project)
+	})
+
+	listener.Handle(outbox.ApplicationSubmitted, func(ctx context.Context, event *outbox.Event) {
+		var payload outbox.ApplicationPayload
+		if err := event.Decode(&payload); err != nil {
+			logger.Error().Err(err).Msg("Relaying application failed")
+			return
+		}
+		var application model.Application
+		if err := db.WithContext(ctx).Preload("Project").Preload("Volunteer").First(&application, payload.ApplicationID).Error; err != nil {
+			logger.Error().Err(err).Uint("application_id", payload.ApplicationID).Msg("Relaying application failed")
+			return
+		}
+		recipients, err := members(db.WithContext(ctx), application.Project.NonprofitID)
+		if err != nil {
+			logger.Error().Err(err).Uint("application_id", payload.ApplicationID).Msg("Relaying application failed")
+			return
+		}
+		b.applications.publish(recipients, &application)
+	})
+
+	listener.Handle(outbox.EngagementStarted, func(ctx context.Context, event *outbox.Event) {
+		var payload outbox.EngagementPayload
+		if err := event.Decode(&payload); err != nil {
+			logger.Error().Err(err).Msg("Relaying engagement failed")
+			return
+		}
+		var engagement model.Engagement
+		if err := db.WithContext(ctx).Preload("Project").Preload("Volunteer").First(&engagement, payload.EngagementID).Error; err != nil {
+			logger.Error().Err(err).Uint("engagement_id", payload.EngagementID).Msg("Relaying engagement failed")
+			return
+		}
+		recipients, err := members(db.WithContext(ctx), engagement.Project.NonprofitID)
+		if err != nil {
+			logger.Error().Err(err).Uint("engagement_id", payload.EngagementID).Msg("Relaying engagement failed")
+			return
+		}
+		if !slices.Contains(recipients, engagement.VolunteerID) {
+			recipients = append(recipients, engagement.VolunteerID)
+		}
+		b.engagements.publish(recipients, &engagement)
+	})
+}
+
+// members returns the IDs of the nonprofit's members.
+func members(db *gorm.DB, nonprofitID uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Table("nonprofit_members").Where("nonprofit_id = ?", nonprofitID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit members: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,251 @@
+// Package analytics computes aggregate reports with SQL, so numbers are never built by loading
+// rows into Go.
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"gorm.io/gorm"
+)
+
+// Rejected hours (approved = false) count as neither approved nor pending.
+const (
+	approvedHoursSQL = "COALESCE(SUM(CASE WHEN hours_loggeds.approved THEN hours_loggeds.hours END), 0)"
+	pendingHoursSQL  = "COALESCE(SUM(CASE WHEN hours_loggeds.approved IS NULL THEN hours_loggeds.hours END), 0)"
+)
+
+// topSkillsLimit is how many skills NonprofitStats ranks.
+const topSkillsLimit = 10
+
+var truncUnits = map[model.Granularity]string{
+	model.GranularityDay:   "day",
+	model.GranularityWeek:  "week",
+	model.GranularityMonth: "month",
+}
+
+// UsesRollup reports whether a nonprofit's hours series is read from the daily rollup rather
+// than computed from HoursLogged on every request.
+func UsesRollup(nonprofit *model.Nonprofit) bool {
+	return nonprofit.Size == model.Large || nonprofit.Size == model.VeryLarge
+}
+
+// NonprofitStats builds the impact report for a nonprofit over [from, to].
+func NonprofitStats(db *gorm.DB, nonprofit *model.Nonprofit, from, to time.Time, granularity model.Granularity) (*model.NonprofitStats, error) {
+	unit, ok := truncUnits[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported granularity %s", granularity)
+	}
+
+	stats := &model.NonprofitStats{
+		From:        utils.FormatTime(from),
+		To:          utils.FormatTime(to),
+		Granularity: granularity,
+	}
+	var err error
+	if stats.Hours, err = hoursSeries(db, nonprofit, from, to, unit); err != nil {
+		return nil, err
+	}
+	if stats.Engagements, err = engagementStats(db, nonprofit.ID, from, to); err != nil {
+		return nil, err
+	}
+	if stats.Applications, err = applicationStats(db, nonprofit.ID, from, to); err != nil {
+		return nil, err
+	}
+	if stats.TopSkills, err = topSkills(db, nonprofit.ID, from, to); err != nil {
+		return nil, err
+	}
+	if stats.Retention, err = retention(db, nonprofit.ID, from, to); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func hoursSeries(db *gorm.DB, nonprofit *model.Nonprofit, from, to time.Time, unit string) ([]*model.HoursPeriod, error) {
+	var rows []struct {
+		Period        time.Time
+		ApprovedHours float64
+		PendingHours  float64
+	}
+
+	var query *gorm.DB
+	if UsesRollup(nonprofit) {
+		query = db.Model(&NonprofitDailyStat{}).
+			Select("date_trunc(?, day) AS period, SUM(approved_hours) AS approved_hours, SUM(pending_hours) AS pending_hours", unit).
+			Where("nonprofit_id = ? AND day >= ?::date AND day <= ?::date", nonprofit.ID, from, to)
+	} else {
+		query = db.Table("hours_loggeds").
+			Select("date_trunc(?, hours_loggeds.date) AS period, "+approvedHoursSQL+" AS approved_hours, "+pendingHoursSQL+" AS pending_hours", unit).
+			Joins("JOIN engagements ON engagements.id = hours_loggeds.engagement_id").
+			Joins("JOIN projects ON projects.id = engagements.project_id").
+			Where("projects.nonprofit_id = ? AND hours_loggeds.deleted_at IS NULL", nonprofit.ID).
+			Where("hours_loggeds.date BETWEEN ? AND ?", from, to)
+	}
+	if err := query.Group("period").Order("period").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate hours: %w", err)
+	}
+
+	series := make([]*model.HoursPeriod, len(rows))
+	for i, row := range rows {
+		series[i] = &model.HoursPeriod{
+			PeriodStart:   utils.FormatTime(row.Period),
+			ApprovedHours: row.ApprovedHours,
+			PendingHours:  row.PendingHours,
+		}
+	}
+	return series, nil
+}
+
+func engagementStats(db *gorm.DB, nonprofitID uint, from, to time.Time) (*model.EngagementStats, error) {
+	var rows []struct {
+		Status model.EngagementStatus
+		Count  int32
+	}
+	if err := db.Table("engagements").
+		Select("engagements.status, COUNT(*) AS count").
+		Joins("JOIN projects ON projects.id = engagements.project_id").
+		Where("projects.nonprofit_id = ? AND engagements.deleted_at IS NULL", nonprofitID).
+		Where("engagements.start_date <= ? AND (engagements.end_date IS NULL OR engagements.end_date >= ?)", to, from).
+		Group("engagements.status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count engagements: %w", err)
+	}
+
+	stats := &model.EngagementStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case model.EngagementActive:
+			stats.Active = row.Count
+		case model.EngagementCompleted:
+			stats.Completed = row.Count
+		case model.EngagementCancelled:
+			stats.Cancelled = row.Count
+		}
+	}
+	return stats, nil
+}
+
+func applicationStats(db *gorm.DB, nonprofitID uint, from, to time.Time) (*model.ApplicationStats, error) {
+	scope := func() *gorm.DB {
+		return db.Table("applications").
+			Joins("JOIN projects ON projects.id = applications.project_id").
+			Where("projects.nonprofit_id = ? AND applications.deleted_at IS NULL", nonprofitID).
+			Where("applications.applied_at BETWEEN ? AND ?", from, to)
+	}
+
+	var rows []struct {
+		Status model.ApplicationStatus
+		Count  int32
+	}
+	if err := scope().Select("applications.status, COUNT(*) AS count").
+		Group("applications.status").
+		Order("applications.status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	stats := &model.ApplicationStats{ByStatus: []*model.ApplicationStatusCount{}}
+	var accepted, rejected int32
+	for _, row := range rows {
+		stats.ByStatus = append(stats.ByStatus, &model.ApplicationStatusCount{Status: row.Status, Count: row.Count})
+		stats.Total += row.Count
+		switch row.Status {
+		case model.Accepted:
+			accepted = row.Count
+		case model.Rejected:
+			rejected = row.Count
+		}
+	}
+	if decided := accepted + rejected; decided > 0 {
+		rate := float64(accepted) / float64(decided)
+		stats.AcceptanceRate = &rate
+	}
+
+	// Withdrawals also set DecidedAt but are the volunteer's decision, not the nonprofit's
+	var median struct{ Hours *float64 }
+	if err := scope().
+		Select("percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM applications.decided_at - applications.applied_at)) / 3600 AS hours").
+		Where("applications.status IN ? AND applications.decided_at IS NOT NULL", []model.ApplicationStatus{model.Accepted, model.Rejected}).
+		Scan(&median).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute median decision time: %w", err)
+	}
+	stats.MedianHoursToDecision = median.Hours
+	return stats, nil
+}
+
+func topSkills(db *gorm.DB, nonprofitID uint, from, to time.Time) ([]*model.SkillDemand, error) {
+	var rows []struct {
+		SkillID          uint
+		ProjectCount     int32
+		ApplicationCount int32
+	}
+	if err := db.Table("project_skills").
+		Select("project_skills.skill_id, COUNT(DISTINCT projects.id) AS project_count, COUNT(applications.id) AS application_count").
+		Joins("JOIN projects ON projects.id = project_skills.project_id").
+		Joins("LEFT JOIN applications ON applications.project_id = projects.id AND applications.deleted_at IS NULL AND applications.applied_at BETWEEN ? AND ?", from, to).
+		Where("projects.nonprofit_id = ? AND projects.deleted_at IS NULL AND projects.status <> ? AND projects.created_at <= ?", nonprofitID, model.Draft, to).
+		Group("project_skills.skill_id").
+		Order("project_count DESC, application_count DESC").
+		Limit(topSkillsLimit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank skills: %w", err)
+	}
+	if len(rows) == 0 {
+		return []*model.SkillDemand{}, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.SkillID
+	}
+	var skills []model.Skill
+	if err := db.Where("id IN ?", ids).Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch skills: %w", err)
+	}
+	byID := make(map[uint]*model.Skill, len(skills))
+	for i := range skills {
+		byID[skills[i].ID] = &skills[i]
+	}
+
+	demand := make([]*model.SkillDemand, 0, len(rows))
+	for _, row := range rows {
+		if skill, ok := byID[row.SkillID]; ok {
+			demand = append(demand, &model.SkillDemand{Skill: skill, Projects: row.ProjectCount, Applications: row.ApplicationCount})
+		}
+	}
+	return demand, nil
+}
+
+func retention(db *gorm.DB, nonprofitID uint, from, to time.Time) (*model.RetentionStats, error) {
+	var row struct {
+		Volunteers          int32
+		ReturningVolunteers int32
+	}
+	// Volunteers count once they log hours the nonprofit has not rejected
+	if err := db.Raw(`
+		WITH logged AS (
+			SELECT engagements.volunteer_id, hours_loggeds.date
+			FROM hours_loggeds
+			JOIN engagements ON engagements.id = hours_loggeds.engagement_id
+			JOIN projects ON projects.id = engagements.project_id
+			WHERE projects.nonprofit_id = ? AND hours_loggeds.deleted_at IS NULL
+				AND (hours_loggeds.approved IS NULL OR hours_loggeds.approved)
+		),
+		in_period AS (SELECT DISTINCT volunteer_id FROM logged WHERE date BETWEEN ? AND ?),
+		before_period AS (SELECT DISTINCT volunteer_id FROM logged WHERE date < ?)
+		SELECT
+			(SELECT COUNT(*) FROM in_period) AS volunteers,
+			(SELECT COUNT(*) FROM in_period JOIN before_period USING (volunteer_id)) AS returning_volunteers`,
+		nonprofitID, from, to, from).Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute retention: %w", err)
+	}
+
+	stats := &model.RetentionStats{Volunteers: row.Volunteers, ReturningVolunteers: row.ReturningVolunteers}
+	if row.Volunteers > 0 {
+		rate := float64(row.ReturningVolunteers) / float64(row.Volunteers)
+		stats.RetentionRate = &rate
+	}
+	return stats, nil
+}
@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"gorm.io/gorm"
+)
+
+// SignupsPerDay counts new users per day and role in [from, to].
+func SignupsPerDay(db *gorm.DB, from, to time.Time) ([]*model.SignupCount, error) {
+	var rows []struct {
+		Day   time.Time
+		Role  model.UserRole
+		Count int32
+	}
+	if err := db.Model(&model.User{}).
+		Select("date_trunc('day', created_at) AS day, role, COUNT(*) AS count").
+		Where("created_at BETWEEN ? AND ?", from, to).
+		Group("day, role").
+		Order("day, role").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count signups: %w", err)
+	}
+
+	counts := make([]*model.SignupCount, len(rows))
+	for i, row := range rows {
+		counts[i] = &model.SignupCount{Day: utils.FormatTime(row.Day), Role: row.Role, Count: row.Count}
+	}
+	return counts, nil
+}
+
+// VerificationBacklog summarises the nonprofits waiting for verification.
+func VerificationBacklog(db *gorm.DB) (*model.VerificationBacklog, error) {
+	var row struct {
+		Pending int
+		Oldest  *time.Time
+	}
+	if err := db.Model(&model.Nonprofit{}).
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Where("verified = ?", false).
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarise verification backlog: %w", err)
+	}
+	return &model.VerificationBacklog{Pending: row.Pending, OldestCreatedAt: row.Oldest}, nil
+}
+
+// ProjectFunnel counts projects per status, listing every status even when empty.
+func ProjectFunnel(db *gorm.DB) ([]*model.ProjectStatusCount, error) {
+	var rows []struct {
+		Status model.ProjectStatus
+		Count  int32
+	}
+	if err := db.Model(&model.Project{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count projects by status: %w", err)
+	}
+	byStatus := make(map[model.ProjectStatus]int32, len(rows))
+	for _, row := range rows {
+		byStatus[row.Status] = row.Count
+	}
+
+	statuses := []model.ProjectStatus{model.Draft, model.PendingReview, model.Active, model.InProgress, model.Completed, model.Cancelled}
+	funnel := make([]*model.ProjectStatusCount, len(statuses))
+	for i, status := range statuses {
+		funnel[i] = &model.ProjectStatusCount{Status: status, Count: byStatus[status]}
+	}
+	return funnel, nil
+}
+
+// HoursTotals sums hours logged in [from, to] by review outcome.
+func HoursTotals(db *gorm.DB, from, to time.Time) (*model.HoursTotals, error) {
+	var totals model.HoursTotals
+	if err := db.Model(&model.HoursLogged{}).
+		Select(approvedHoursSQL+" AS approved, "+pendingHoursSQL+" AS pending, "+
+			"COALESCE(SUM(CASE WHEN NOT hours_loggeds.approved THEN hours_loggeds.hours END), 0) AS rejected").
+		Where("date BETWEEN ? AND ?", from, to).
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to total hours: %w", err)
+	}
+	return &totals, nil
+}
+
+// TopCauses ranks causes by how many volunteers and nonprofits support them.
+func TopCauses(db *gorm.DB, limit int) ([]*model.CauseUsage, error) {
+	var rows []struct {
+		CauseID        uint
+		VolunteerCount int32
+		NonprofitCount int32
+	}
+	if err := db.Raw(`
+		SELECT causes.id AS cause_id,
+			(SELECT COUNT(*) FROM user_causes WHERE user_causes.cause_id = causes.id) AS volunteer_count,
+			(SELECT COUNT(*) FROM nonprofit_causes WHERE nonprofit_causes.cause_id = causes.id) AS nonprofit_count
+		FROM causes
+		WHERE causes.deleted_at IS NULL
+		ORDER BY volunteer_count + nonprofit_count DESC, causes.name
+		LIMIT ?`, limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank causes: %w", err)
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.CauseID
+	}
+	var causes []model.Cause
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&causes).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch causes: %w", err)
+		}
+	}
+	byID := make(map[uint]*model.Cause, len(causes))
+	for i := range causes {
+		byID[causes[i].ID] = &causes[i]
+	}
+
+	usage := make([]*model.CauseUsage, 0, len(rows))
+	for _, row := range rows {
+		if cause, ok := byID[row.CauseID]; ok {
+			usage = append(usage, &model.CauseUsage{Cause: cause, Volunteers: row.VolunteerCount, Nonprofits: row.NonprofitCount})
+		}
+	}
+	return usage, nil
+}
+
+// TopSkills ranks skills by how many volunteers offer them and projects need them.
+func TopSkills(db *gorm.DB, limit int) ([]*model.SkillUsage, error) {
+	var rows []struct {
+		SkillID        uint
+		VolunteerCount int32
+		ProjectCount   int32
+	}
+	if err := db.Raw(`
+		SELECT skills.id AS skill_id,
+			(SELECT COUNT(*) FROM user_skills WHERE user_skills.skill_id = skills.id) AS volunteer_count,
+			(SELECT COUNT(*) FROM project_skills WHERE project_skills.skill_id = skills.id) AS project_count
+		FROM skills
+		WHERE skills.deleted_at IS NULL
+		ORDER BY volunteer_count + project_count DESC, skills.name
+		LIMIT ?`, limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank skills: %w", err)
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.SkillID
+	}
+	var skills []model.Skill
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&skills).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch skills: %w", err)
+		}
+	}
+	byID := make(map[uint]*model.Skill, len(skills))
+	for i := range skills {
+		byID[skills[i].ID] = &skills[i]
+	}
+
+	usage := make([]*model.SkillUsage, 0, len(rows))
+	for _, row := range rows {
+		if skill, ok := byID[row.SkillID]; ok {
+			usage = append(usage, &model.SkillUsage{Skill: skill, Volunteers: row.VolunteerCount, Projects: row.ProjectCount})
+		}
+	}
+	return usage, nil
+}
+
+// SearchUsers finds users whose name, email, bio, profile links or skills contain query,
+// case-insensitively.
+func SearchUsers(db *gorm.DB, query string, role *model.UserRole, limit, offset int) ([]*model.User, error) {
+	pattern := "%" + escapeLike(strings.TrimSpace(query)) + "%"
+	q := db.Model(&model.User{}).
+		Where(`users.email ILIKE @p OR users.first_name ILIKE @p OR users.last_name ILIKE @p
+			OR (users.first_name || ' ' || users.last_name) ILIKE @p
+			OR users.bio ILIKE @p OR users.linked_in_url ILIKE @p OR users.portfolio_url ILIKE @p
+			OR EXISTS (
+				SELECT 1 FROM user_skills JOIN skills ON skills.id = user_skills.skill_id
+				WHERE user_skills.user_id = users.id AND skills.name ILIKE @p
+			)`, map[string]interface{}{"p": pattern})
+	if role != nil {
+		q = q.Where("users.role = ?", *role)
+	}
+
+	var users []*model.User
+	if err := q.Order("users.created_at DESC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	return users, nil
+}
+
+// escapeLike makes LIKE wildcards in user input match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// PlatformCounts fills the row counts of a PlatformHealth summary.
+func PlatformCounts(db *gorm.DB, health *model.PlatformHealth) error {
+	return db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE deleted_at IS NULL) AS users,
+			(SELECT COUNT(*) FROM nonprofits WHERE deleted_at IS NULL) AS nonprofits,
+			(SELECT COUNT(*) FROM projects WHERE deleted_at IS NULL AND status IN (?, ?)) AS active_projects,
+			(SELECT COUNT(*) FROM projects WHERE deleted_at IS NULL AND status = ?) AS pending_moderation,
+			(SELECT COUNT(*) FROM hours_loggeds WHERE deleted_at IS NULL AND approved IS NULL) AS unreviewed_hours`,
+		model.Active, model.InProgress, model.PendingReview).Scan(health).Error
+}
@@ -0,0 +1,68 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RollupWindow is how far back RefreshRecent recomputes the daily rollup. Hours logged or
+// reviewed later than this after the fact only show up after a RefreshDailyRollup backfill.
+const RollupWindow = 30 * 24 * time.Hour
+
+// NonprofitDailyStat is one nonprofit's hours for one day, precomputed so reports for large
+// organisations do not scan every HoursLogged row.
+type NonprofitDailyStat struct {
+	NonprofitID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Day           time.Time `gorm:"primaryKey;type:date"`
+	ApprovedHours float64
+	PendingHours  float64
+	UpdatedAt     time.Time
+}
+
+// RefreshDailyRollup recomputes the rollup for every day in [from, to). It can run on several
+// replicas at once: rows are upserted, and the window is rewritten in one transaction.
+func RefreshDailyRollup(db *gorm.DB, from, to time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day >= ? AND day < ?", from, to).Delete(&NonprofitDailyStat{}).Error; err != nil {
+			return fmt.Errorf("failed to clear daily rollup: %w", err)
+		}
+		err := tx.Exec(`
+			INSERT INTO nonprofit_daily_stats (nonprofit_id, day, approved_hours, pending_hours, updated_at)
+			SELECT projects.nonprofit_id, date_trunc('day', hours_loggeds.date)::date,
+				`+approvedHoursSQL+`, `+pendingHoursSQL+`, now()
+			FROM hours_loggeds
+			JOIN engagements ON engagements.id = hours_loggeds.engagement_id
+			JOIN projects ON projects.id = engagements.project_id
+			WHERE hours_loggeds.deleted_at IS NULL AND hours_loggeds.date >= ? AND hours_loggeds.date < ?
+			GROUP BY 1, 2
+			ON CONFLICT (nonprofit_id, day) DO UPDATE SET
+				approved_hours = EXCLUDED.approved_hours,
+				pending_hours = EXCLUDED.pending_hours,
+				updated_at = EXCLUDED.updated_at`, from, to).Error
+		if err != nil {
+			return fmt.Errorf("failed to refresh daily rollup: %w", err)
+		}
+		return nil
+	})
+}
+
+// KindRefreshRecent is the job that runs RefreshRecent.
+const KindRefreshRecent = "analytics.refresh_recent"
+
+// RefreshRecent refreshes the last RollupWindow up to and including today. On an empty rollup
+// table it backfills the full history instead.
+func RefreshRecent(db *gorm.DB, now time.Time) error {
+	to := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	from := to.Add(-RollupWindow)
+
+	var count int64
+	if err := db.Model(&NonprofitDailyStat{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check daily rollup: %w", err)
+	}
+	if count == 0 {
+		from = time.Time{}
+	}
+	return RefreshDailyRollup(db, from, to)
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/reviews"
+	"gorm.io/gorm"
+)
+
+// VolunteerImpact summarises a volunteer's track record. Only engagements that were not
+// cancelled count towards nonprofits helped, causes served and skills used; skills used are the
+// volunteer's own skills that those projects needed. Endorsements are the volunteer's skill
+// badges, see reviews.Badges.
+func VolunteerImpact(db *gorm.DB, userID uint) (*model.ImpactSummary, error) {
+	var totals struct {
+		TotalApprovedHours float64
+		NonprofitsHelped   int32
+		ProjectsCompleted  int32
+	}
+	if err := db.Raw(`
+		SELECT
+			(SELECT COALESCE(SUM(hours_loggeds.hours), 0)
+				FROM hours_loggeds JOIN engagements ON engagements.id = hours_loggeds.engagement_id
+				WHERE engagements.volunteer_id = @user AND hours_loggeds.approved AND hours_loggeds.deleted_at IS NULL
+			) AS total_approved_hours,
+			(SELECT COUNT(DISTINCT projects.nonprofit_id)
+				FROM engagements JOIN projects ON projects.id = engagements.project_id
+				WHERE engagements.volunteer_id = @user AND engagements.status <> @cancelled AND engagements.deleted_at IS NULL
+			) AS nonprofits_helped,
+			(SELECT COUNT(*) FROM engagements
+				WHERE engagements.volunteer_id = @user AND engagements.status = @completed AND engagements.deleted_at IS NULL
+			) AS projects_completed`,
+		map[string]interface{}{
+			"user":      userID,
+			"cancelled": model.EngagementCancelled,
+			"completed": model.EngagementCompleted,
+		}).Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to total volunteer impact: %w", err)
+	}
+
+	engaged := db.Table("engagements").
+		Select("engagements.project_id").
+		Where("engagements.volunteer_id = ? AND engagements.status <> ? AND engagements.deleted_at IS NULL", userID, model.EngagementCancelled)
+
+	var causes []*model.Cause
+	if err := db.Where(`id IN (
+			SELECT nonprofit_causes.cause_id FROM nonprofit_causes
+			JOIN projects ON projects.nonprofit_id = nonprofit_causes.nonprofit_id
+			WHERE projects.id IN (?)
+		)`, engaged).
+		Order("name").
+		Find(&causes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch causes served: %w", err)
+	}
+
+	var skills []*model.Skill
+	if err := db.Where("id IN (SELECT skill_id FROM project_skills WHERE project_id IN (?))", engaged).
+		Where("id IN (SELECT skill_id FROM user_skills WHERE user_id = ?)", userID).
+		Order("name").
+		Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch skills used: %w", err)
+	}
+
+	endorsements, err := reviews.Badges(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ImpactSummary{
+		TotalApprovedHours: totals.TotalApprovedHours,
+		NonprofitsHelped:   totals.NonprofitsHelped,
+		ProjectsCompleted:  totals.ProjectsCompleted,
+		CausesServed:       causes,
+		SkillsUsed:         skills,
+		Endorsements:       endorsements,
+	}, nil
+}
@@ -0,0 +1,240 @@
+// Package audit keeps the append-only log of who did what. Extension records every mutation with
+// its actor, client IP and request ID. Mutations that change sensitive records, such as verifying
+// a nonprofit or rejecting an application, record their own event with a diff of the record in
+// the transaction making the change, see Record.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/ratelimit"
+	"gorm.io/gorm"
+)
+
+// Entry describes an audited change to one record. Before and After are the record as it was and
+// as it is now, nil when it didn't exist.
+type Entry struct {
+	// Action defaults to the name of the mutation being resolved.
+	Action      string
+	TargetType  string
+	TargetID    uint
+	NonprofitID *uint
+	Before      any
+	After       any
+}
+
+// Change describes a change to a record belonging to nonprofitID. before is nil for a new record
+// and after for a deleted one.
+func Change(targetType string, id, nonprofitID uint, before, after any) Entry {
+	return Entry{TargetType: targetType, TargetID: id, NonprofitID: &nonprofitID, Before: before, After: after}
+}
+
+type contextKey string
+
+const recorderKey = contextKey("auditRecorder")
+
+// recorder tells Extension whether the mutation recorded its own event.
+type recorder struct {
+	recorded bool
+}
+
+// Record adds an event for entry to the log, on behalf of the user and request in ctx. Call it
+// with the transaction making the change, so that the change and its event commit together.
+func Record(ctx context.Context, tx *gorm.DB, entry Entry) error {
+	event := newEvent(ctx, entry.Action)
+	event.TargetType = entry.TargetType
+	if entry.TargetID != 0 {
+		id := strconv.FormatUint(uint64(entry.TargetID), 10)
+		event.TargetID = &id
+	}
+	event.NonprofitID = entry.NonprofitID
+	event.Changes = Diff(entry.Before, entry.After)
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	if rec, ok := ctx.Value(recorderKey).(*recorder); ok {
+		rec.recorded = true
+	}
+	return nil
+}
+
+// newEvent starts an event for the actor, client IP and request ID in ctx.
+func newEvent(ctx context.Context, action string) *model.AuditEvent {
+	if action == "" {
+		if fc := graphql.GetFieldContext(ctx); fc != nil {
+			action = fc.Field.Name
+		}
+	}
+	event := &model.AuditEvent{Action: action}
+	if id, ok := auth.UserIDFromContext(ctx); ok {
+		event.ActorID = &id
+	}
+	if ip := ratelimit.ClientIPFromContext(ctx); ip != "" {
+		event.IP = &ip
+	}
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		event.RequestID = &requestID
+	}
+	return event
+}
+
+// ignoredFields change on every write and say nothing about what was done.
+var ignoredFields = map[string]bool{"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true}
+
+// redacted is recorded instead of the value of fields holding credentials, matched by name
+// ignoring case, so the log shows that they changed but not what to.
+const redacted = `"[redacted]"`
+
+var secretFields = []string{"password", "passwordhash", "secret", "token", "refreshtoken"}
+
+// privateFields are left out altogether, at any depth and matched by name ignoring case: message
+// bodies are private to the conversation.
+var privateFields = []string{"body"}
+
+// Diff lists the fields that differ between before and after, by their JSON encoding. Fields of
+// nested objects are compared one by one, e.g. Location.City; lists of objects, such as
+// preloaded associations, are left out.
+func Diff(before, after any) model.AuditChanges {
+	from, to := fields(before), fields(after)
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := model.AuditChanges{}
+	for _, name := range names {
+		if from[name] == to[name] {
+			continue
+		}
+		changes = append(changes, &model.AuditChange{Field: name, From: value(name, from[name]), To: value(name, to[name])})
+	}
+	return changes
+}
+
+// fields encodes v and returns its fields as JSON, leaving out nulls.
+func fields(v any) map[string]string {
+	if v == nil {
+		return nil
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	out := make(map[string]string)
+	flatten("", body, out)
+	return out
+}
+
+func flatten(prefix string, body json.RawMessage, out map[string]string) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return
+	}
+	for name, r := range raw {
+		if ignoredFields[name] || slices.Contains(privateFields, strings.ToLower(name)) {
+			continue
+		}
+		r = bytes.TrimSpace(r)
+		switch {
+		case string(r) == "null" || len(r) == 0 || listOfObjects(r):
+		case r[0] == '{':
+			flatten(prefix+name+".", r, out)
+		default:
+			out[prefix+name] = string(r)
+		}
+	}
+}
+
+// listOfObjects reports whether r is a list holding objects.
+func listOfObjects(r json.RawMessage) bool {
+	if r[0] != '[' {
+		return false
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(r, &items); err != nil {
+		return false
+	}
+	for _, item := range items {
+		if item = bytes.TrimSpace(item); len(item) > 0 && item[0] == '{' {
+			return true
+		}
+	}
+	return false
+}
+
+func value(name, raw string) *string {
+	if raw == "" {
+		return nil
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	if slices.Contains(secretFields, strings.ToLower(name)) {
+		raw = redacted
+	}
+	return &raw
+}
+
+// Filter narrows down List. Zero fields don't filter.
+type Filter struct {
+	ActorID     *uint
+	Action      string
+	TargetType  string
+	TargetID    string
+	NonprofitID *uint
+	From        *time.Time
+	To          *time.Time
+}
+
+// List returns the events matching filter, newest first, starting before the event beforeID
+// unless it is 0.
+func List(db *gorm.DB, filter Filter, beforeID uint, limit int) ([]*model.AuditEvent, error) {
+	query := db.Model(&model.AuditEvent{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.NonprofitID != nil {
+		query = query.Where("nonprofit_id = ?", *filter.NonprofitID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var events []*model.AuditEvent
+	if err := query.Preload("Actor").Order("id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestDiff(t *testing.T) {
+	conversation := "80"
+	tests := []struct {
+		name  string
+		after any
+		want  map[string]string // field to recorded value; absent fields must not be recorded
+	}{
+		{
+			name:  "nested message body",
+			after: map[string]any{"input": model.SendMessageInput{ConversationID: &conversation, Body: "My phone number is 555-0100"}},
+			want:  map[string]string{"input.conversationId": `"80"`},
+		},
+		{
+			name:  "top-level message body",
+			after: map[string]any{"conversationId": "80", "body": "My phone number is 555-0100"},
+			want:  map[string]string{"conversationId": `"80"`},
+		},
+		{
+			name:  "nested password",
+			after: map[string]any{"input": map[string]any{"email": "a@example.org", "password": "hunter22"}},
+			want:  map[string]string{"input.email": `"a@example.org"`, "input.password": redacted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			for _, change := range Diff(nil, tt.after) {
+				if change.From != nil {
+					t.Errorf("%s: from = %s, want nil", change.Field, *change.From)
+				}
+				got[change.Field] = *change.To
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("recorded %v, want %v", got, tt.want)
+			}
+			for field, want := range tt.want {
+				if got[field] != want {
+					t.Errorf("%s = %q, want %q", field, got[field], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const auditExtension = "Audit"
+
+// Extension is a gqlgen handler extension that records every successful mutation that didn't
+// record its own event. The target is taken from the mutation's result and the arguments are
+// recorded as the changes, with credentials redacted and message bodies left out.
+type Extension struct {
+	DB     *gorm.DB
+	Logger *zerolog.Logger
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = &Extension{}
+
+func (e *Extension) ExtensionName() string {
+	return auditExtension
+}
+
+func (e *Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e *Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" {
+		return next(ctx)
+	}
+
+	rec := &recorder{}
+	res, err := next(context.WithValue(ctx, recorderKey, rec))
+	if err != nil || rec.recorded {
+		return res, err
+	}
+
+	// The mutation has committed by now, so a failure to record it is logged rather than returned
+	event := newEvent(ctx, fc.Field.Name)
+	event.TargetType, event.TargetID, event.NonprofitID = target(reflect.ValueOf(res))
+	if id, ok := fc.Args["id"].(string); ok && event.TargetID == nil {
+		// Deletions and the like only return whether they succeeded
+		event.TargetID = &id
+	}
+	event.Changes = Diff(nil, fc.Args)
+	if err := e.DB.Create(event).Error; err != nil {
+		e.Logger.Error().Err(err).Str("action", event.Action).Msg("Recording audit event failed")
+	}
+	return res, err
+}
+
+// target finds the record a mutation returned: a struct with an ID, or one holding a User, like
+// the result of login. Its nonprofit is the record itself for a Nonprofit, or else its own or its
+// project's NonprofitID.
+func target(v reflect.Value) (targetType string, id *string, nonprofitID *uint) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", nil, nil
+	}
+
+	idField := v.FieldByName("ID")
+	if !idField.IsValid() || idField.Kind() != reflect.Uint {
+		if user := v.FieldByName("User"); user.IsValid() {
+			return target(user)
+		}
+		return "", nil, nil
+	}
+	targetType = v.Type().Name()
+	s := strconv.FormatUint(idField.Uint(), 10)
+	id = &s
+
+	if targetType == "Nonprofit" {
+		n := uint(idField.Uint())
+		return targetType, id, &n
+	}
+	nonprofitID = uintField(v, "NonprofitID")
+	if nonprofitID == nil {
+		if project := v.FieldByName("Project"); project.IsValid() {
+			for project.Kind() == reflect.Pointer && !project.IsNil() {
+				project = project.Elem()
+			}
+			if project.Kind() == reflect.Struct {
+				nonprofitID = uintField(project, "NonprofitID")
+			}
+		}
+	}
+	return targetType, id, nonprofitID
+}
+
+// uintField returns v's non-zero uint or *uint field name.
+func uintField(v reflect.Value, name string) *uint {
+	f := v.FieldByName(name)
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	if f.Kind() != reflect.Uint || f.Uint() == 0 {
+		return nil
+	}
+	n := uint(f.Uint())
+	return &n
+}
@@ -14,14 +14,16 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/prkagrawal/cosmos-bk2/database"
 	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/rs/zerolog"
 )
 
 type AuthService struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Logger *zerolog.Logger
 }
 
-func NewAuthService(db *gorm.DB) *AuthService {
-	return &AuthService{DB: db}
+func NewAuthService(db *gorm.DB, logger *zerolog.Logger) *AuthService {
+	return &AuthService{DB: db, Logger: logger}
 }
 
 func (a *AuthService) CreateUser(ctx context.Context, input model.SignupInput) (*model.User, error) {
@@ -46,19 +48,72 @@ func (a *AuthService) CreateUser(ctx context.Context, input model.SignupInput) (
 	return &user, nil
 }
 
+// Account lockout policy. After lockoutThreshold consecutive failures the account is locked for
+// lockoutBase, doubling with every further failure up to lockoutMax.
+const (
+	lockoutThreshold = 5
+	lockoutBase      = time.Minute
+	lockoutMax       = 24 * time.Hour
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
 func (a *AuthService) Authenticate(ctx context.Context, email, password string) (*model.User, error) {
 	var user model.User
 	if err := a.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+
+	// Check the lock before running bcrypt so a locked account costs us nothing to reject. The
+	// caller is told no more than for a wrong password, so the lock doesn't give away that the
+	// email is registered.
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		a.Logger.Warn().Uint("user_id", user.ID).Time("locked_until", *user.LockedUntil).Msg("Login to locked account rejected")
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		if err := a.recordFailedLogin(&user); err != nil {
+			a.Logger.Error().Err(err).Uint("user_id", user.ID).Msg("Recording failed login failed")
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := a.DB.Model(&user).Updates(map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil}).Error; err != nil {
+			a.Logger.Error().Err(err).Uint("user_id", user.ID).Msg("Resetting failed logins failed")
+		}
 	}
 
 	return &user, nil
 }
 
+// recordFailedLogin increments the failure counter atomically and locks the account once the
+// threshold is reached.
+func (a *AuthService) recordFailedLogin(user *model.User) error {
+	if err := a.DB.Model(user).
+		UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+		return fmt.Errorf("failed to count failed login: %w", err)
+	}
+	if err := a.DB.Model(user).Select("failed_login_attempts").First(user).Error; err != nil {
+		return fmt.Errorf("failed to fetch failed login count: %w", err)
+	}
+
+	if user.FailedLoginAttempts < lockoutThreshold {
+		return nil
+	}
+	lockFor := lockoutBase << (user.FailedLoginAttempts - lockoutThreshold)
+	if lockFor > lockoutMax || lockFor <= 0 {
+		lockFor = lockoutMax
+	}
+	lockedUntil := time.Now().Add(lockFor)
+	if err := a.DB.Model(user).UpdateColumn("locked_until", lockedUntil).Error; err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	a.Logger.Warn().Uint("user_id", user.ID).Int("failed_attempts", user.FailedLoginAttempts).Time("locked_until", lockedUntil).Msg("Account locked after repeated failed logins")
+	return nil
+}
+
 // GenerateJWT needs to be consistent. If it stores ID as uint, the middleware/GetuserFromContext must handle it.
 // The default jwt.MapClaims will likely store uint as float64.
 func GenerateJWT(user *model.User) (string, error) {
@@ -71,6 +126,27 @@ func GenerateJWT(user *model.User) (string, error) {
 	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 }
 
+// UserIDFromContext returns the ID in the verified token's sub claim without hitting the database.
+// It is meant for cheap lookups such as rate limit keys; use GetUserFromContext to load the user.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	token, ok := ctx.Value(userContextKey).(*jwt.Token)
+	if !ok {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	switch sub := claims["sub"].(type) {
+	case float64:
+		return uint(sub), true
+	case string:
+		id, err := strconv.ParseUint(sub, 10, 0)
+		return uint(id), err == nil
+	}
+	return 0, false
+}
+
 // GetUserFromContext retrieves the user from the context.
 // It now expects the value associated with userContextKey.
 func GetUserFromContext(ctx context.Context) (*model.User, error) {
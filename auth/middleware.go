@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
 )
 
 // Define a custom key type for context to avoid collisions
@@ -40,15 +41,8 @@ func AuthMiddleware() func(http.Handler) http.Handler {
 			tokenStr := parts[1]
 
 			// Parse and validate the token
-			token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-				// Make sure the signing method is what you expect:
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid // Or a more specific error
-				}
-				return []byte(os.Getenv("JWT_SECRET")), nil
-			})
-
-			if err != nil || !token.Valid {
+			token, err := parseToken(tokenStr)
+			if err != nil {
 				// Token is invalid or expired.
 				// You could send an HTTP 401 here, but for GraphQL,
 				// it's often preferred to let the request proceed.
@@ -68,3 +62,49 @@ func AuthMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequirePlatformAdmin only lets platform admins through to next, for internal endpoints such
+// as /debug/vars. It must run after AuthMiddleware.
+func RequirePlatformAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := GetUserFromContext(r.Context())
+		if err != nil {
+			http.Error(w, "Unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		if user.Role != model.PlatformAdmin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ContextWithToken validates a bearer token and packs it into ctx the way AuthMiddleware does.
+// It is used where there are no request headers to read, such as a websocket's connection_init
+// payload.
+func ContextWithToken(ctx context.Context, tokenStr string) (context.Context, error) {
+	token, err := parseToken(strings.TrimPrefix(tokenStr, "Bearer "))
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, userContextKey, token), nil
+}
+
+// parseToken parses tokenStr and checks its signature and expiry.
+func parseToken(tokenStr string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		// Make sure the signing method is what you expect:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid // Or a more specific error
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return token, nil
+}
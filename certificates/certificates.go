@@ -0,0 +1,147 @@
+// Package certificates issues statements of a volunteer's approved hours, renders them as PDF and
+// lets third parties verify them by code.
+package certificates
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound = errors.New("certificate not found")
+	ErrNoHours  = errors.New("no approved hours in the requested period")
+)
+
+// codeAlphabet leaves out characters that are easily confused when typed from paper (0/O, 1/I).
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// newCode returns a random code such as "K7P2-M9QX-4TBR".
+func newCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate certificate code: %w", err)
+	}
+	var b strings.Builder
+	for i, c := range buf {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(codeAlphabet[int(c)%len(codeAlphabet)])
+	}
+	return b.String(), nil
+}
+
+// NormalizeCode accepts codes typed in lower case or with spaces instead of dashes.
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, " ", "-")
+}
+
+// VerifyURL is the public page a certificate's QR code points at.
+func VerifyURL(code string) string {
+	return strings.TrimRight(os.Getenv("FRONTEND_URL"), "/") + "/verify/" + code
+}
+
+// Issue snapshots the volunteer's approved hours between from and to (either may be nil for an
+// open range) into a new certificate.
+func Issue(db *gorm.DB, volunteer *model.User, from, to *time.Time) (*model.Certificate, error) {
+	query := db.Table("hours_loggeds").
+		Select(`nonprofits.name AS nonprofit_name, projects.title AS project_title,
+			TRIM(COALESCE(approvers.first_name, '') || ' ' || COALESCE(approvers.last_name, '')) AS approver_name,
+			SUM(hours_loggeds.hours) AS hours`).
+		Joins("JOIN engagements ON engagements.id = hours_loggeds.engagement_id").
+		Joins("JOIN projects ON projects.id = engagements.project_id").
+		Joins("JOIN nonprofits ON nonprofits.id = projects.nonprofit_id").
+		Joins("LEFT JOIN users AS approvers ON approvers.id = hours_loggeds.approved_by_id").
+		Where("engagements.volunteer_id = ? AND hours_loggeds.approved = ? AND hours_loggeds.deleted_at IS NULL", volunteer.ID, true)
+	if from != nil {
+		query = query.Where("hours_loggeds.date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("hours_loggeds.date <= ?", *to)
+	}
+
+	var lines []model.CertificateLine
+	if err := query.Group("nonprofits.name, projects.title, approver_name").
+		Order("nonprofits.name, projects.title, approver_name").
+		Scan(&lines).Error; err != nil {
+		return nil, fmt.Errorf("failed to total approved hours: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, ErrNoHours
+	}
+
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+	cert := model.Certificate{
+		Code:        code,
+		VolunteerID: volunteer.ID,
+		From:        from,
+		To:          to,
+		Lines:       lines,
+	}
+	for _, line := range lines {
+		cert.TotalHours += line.Hours
+	}
+	cert.Digest = Digest(&cert, volunteer)
+
+	if err := db.Create(&cert).Error; err != nil {
+		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+	cert.Volunteer = *volunteer
+	return &cert, nil
+}
+
+// Digest hashes everything a certificate attests to. Anyone holding the PDF can compare the
+// printed digest with the one verifyCertificate returns.
+func Digest(cert *model.Certificate, volunteer *model.User) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s %s\n%s\n%s\n%.2f\n", cert.Code, volunteer.FirstName, volunteer.LastName,
+		formatDate(cert.From), formatDate(cert.To), cert.TotalHours)
+	for _, line := range cert.Lines {
+		fmt.Fprintf(h, "%s\t%s\t%s\t%.2f\n", line.NonprofitName, line.ProjectTitle, line.ApproverName, line.Hours)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Find loads a certificate with its volunteer and lines by ID.
+func Find(db *gorm.DB, id uint) (*model.Certificate, error) {
+	return find(db.Where("id = ?", id))
+}
+
+// Verify loads a certificate by its public code.
+func Verify(db *gorm.DB, code string) (*model.Certificate, error) {
+	return find(db.Where("code = ?", NormalizeCode(code)))
+}
+
+func find(query *gorm.DB) (*model.Certificate, error) {
+	var cert model.Certificate
+	err := query.Preload("Volunteer").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&cert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
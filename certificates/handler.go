@@ -0,0 +1,56 @@
+package certificates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"gorm.io/gorm"
+)
+
+// PDFHandler serves GET /certificates/{id}.pdf to the certificate's volunteer and platform admins.
+// Third parties check a certificate with the verifyCertificate query instead.
+func PDFHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.GetUserFromContext(r.Context())
+		if err != nil {
+			http.Error(w, "Unauthenticated", http.StatusUnauthorized)
+			return
+		}
+
+		id, err := utils.IdToUint(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid certificate ID", http.StatusBadRequest)
+			return
+		}
+		cert, err := Find(db, id)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "Certificate not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to fetch certificate", http.StatusInternalServerError)
+			return
+		}
+		if cert.VolunteerID != user.ID && user.Role != model.PlatformAdmin {
+			// Same response as a missing certificate so IDs cannot be probed
+			http.Error(w, "Certificate not found", http.StatusNotFound)
+			return
+		}
+
+		// Render fully before writing so a failure can still produce an error status
+		var buf bytes.Buffer
+		if err := WritePDF(&buf, cert); err != nil {
+			http.Error(w, "Failed to render certificate", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="hours-statement-%s.pdf"`, cert.Code))
+		w.Write(buf.Bytes())
+	}
+}
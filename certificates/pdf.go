@@ -0,0 +1,97 @@
+package certificates
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/skip2/go-qrcode"
+)
+
+// WritePDF renders a certificate as a one-page (or longer, for many lines) A4 PDF.
+func WritePDF(w io.Writer, cert *model.Certificate) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	// The core fonts are Latin-1; translate so accented names render correctly
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Volunteer hours statement "+cert.Code, true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 12, "Volunteer Hours Statement", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 11)
+	name := tr(cert.Volunteer.FirstName + " " + cert.Volunteer.LastName)
+	pdf.MultiCell(0, 6, fmt.Sprintf("This certifies that %s contributed %.2f approved volunteer hours %s.",
+		name, cert.TotalHours, period(cert.From, cert.To)), "", "L", false)
+	pdf.Ln(4)
+
+	// Hours table
+	widths := []float64{55, 65, 45, 25}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for i, heading := range []string{"Nonprofit", "Project", "Approved by", "Hours"} {
+		align := "L"
+		if i == 3 {
+			align = "R"
+		}
+		pdf.CellFormat(widths[i], 8, heading, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range cert.Lines {
+		pdf.CellFormat(widths[0], 7, truncate(pdf, tr(line.NonprofitName), widths[0]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, truncate(pdf, tr(line.ProjectTitle), widths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 7, truncate(pdf, tr(line.ApproverName), widths[2]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], 7, fmt.Sprintf("%.2f", line.Hours), "1", 0, "R", false, 0, "")
+		pdf.Ln(-1)
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 8, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 8, fmt.Sprintf("%.2f", cert.TotalHours), "1", 1, "R", false, 0, "")
+	pdf.Ln(8)
+
+	// Verification block: QR code linking to the public check, plus code and digest
+	png, err := qrcode.Encode(VerifyURL(cert.Code), qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("failed to render QR code: %w", err)
+	}
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	y := pdf.GetY()
+	pdf.ImageOptions("qr", 10, y, 35, 35, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetXY(50, y)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Issued %s\nVerification code: %s\nVerify at %s\nSHA-256: %s",
+		cert.CreatedAt.Format("January 2, 2006"), cert.Code, VerifyURL(cert.Code), cert.Digest), "", "L", false)
+
+	return pdf.Output(w)
+}
+
+func period(from, to *time.Time) string {
+	const layout = "January 2, 2006"
+	switch {
+	case from != nil && to != nil:
+		return fmt.Sprintf("between %s and %s", from.Format(layout), to.Format(layout))
+	case from != nil:
+		return "since " + from.Format(layout)
+	case to != nil:
+		return "up to " + to.Format(layout)
+	}
+	return "to date"
+}
+
+// truncate shortens text to fit a table cell.
+func truncate(pdf *fpdf.Fpdf, text string, width float64) string {
+	const margin = 3
+	if pdf.GetStringWidth(text) <= width-margin {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width-margin {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/prkagrawal/cosmos-bk2/analytics"
 	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/prkagrawal/cosmos-bk2/persisted"
+	"github.com/prkagrawal/cosmos-bk2/ratelimit"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -38,11 +43,63 @@ func Migrate() error {
 		&model.Application{},
 		&model.Engagement{},
 		&model.HoursLogged{},
+		&model.ProjectStatusChange{},
+		&model.ProjectRoleSlot{},
+		&model.ApplicationQuestion{},
+		&model.ApplicationAnswer{},
+		&model.Shift{},
+		&model.ShiftSignup{},
+		&model.Certificate{},
+		&model.CertificateLine{},
+		&model.Review{},
+		&model.ReviewFlag{},
+		&model.SkillEndorsement{},
+		&model.Conversation{},
+		&model.Message{},
+		&model.ConversationRead{},
+		&model.Notification{},
+		&model.NotificationPreference{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.AuditEvent{},
+		&model.SentDigest{},
+		&model.SentDigestProject{},
+		&ratelimit.RateLimitBucket{},
+		&persisted.PersistedOperation{},
+		&analytics.NonprofitDailyStat{},
+		&jobs.Job{},
+		&jobs.ScheduledRun{},
+		&outbox.Event{},
+		&outbox.Consumption{},
+		&outbox.ConsumerStart{},
+		&outbox.Failure{},
 	)
 	if err != nil {
 		return err
 	}
 
+	// The audit log is append-only, whoever connects to the database
+	for _, stmt := range []string{
+		`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'audit_events is append-only';
+			END
+			$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`,
+		`CREATE TRIGGER audit_events_append_only
+			BEFORE UPDATE OR DELETE ON audit_events
+			FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()`,
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	// Receivers' answers are no longer kept, only their status codes
+	if err := DB.Exec(`ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body`).Error; err != nil {
+		return err
+	}
+
 	// Manually handle the days_available column conversion
 	return DB.Transaction(func(tx *gorm.DB) error {
 		// Add new temporary column
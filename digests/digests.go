@@ -0,0 +1,196 @@
+// Package digests sends users a periodic email of newly active projects that match their skills
+// and causes, together with any notifications they asked to receive by digest.
+package digests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// CheckInterval is how often SendDue is scheduled to run. A digest becomes due this much
+	// before its full period is up, so daily digests go out at roughly the same time each day.
+	CheckInterval = time.Hour
+
+	// maxProjects and maxNotifications cap what a single digest lists.
+	maxProjects      = 20
+	maxNotifications = 50
+)
+
+// Period is how often a user with the given frequency gets a digest; 0 for NEVER.
+func Period(frequency model.DigestFrequency) time.Duration {
+	switch frequency {
+	case model.DigestDaily:
+		return 24 * time.Hour
+	case model.DigestWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// SendDue sends every digest that is due at now and returns how many were sent. Each user is
+// handled in its own transaction with their row locked (SKIP LOCKED), so it is safe to run on
+// every replica. A digest that fails is logged and put off until the next run, without holding
+// up everyone else's.
+func SendDue(ctx context.Context, db *gorm.DB, m mailer.Mailer, logger *zerolog.Logger, now time.Time) (int, error) {
+	sent := 0
+	for {
+		var handled bool
+		var user model.User
+		err := db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where(`((digest_frequency = ? AND (last_digest_at IS NULL OR last_digest_at <= ?))
+					OR (digest_frequency = ? AND (last_digest_at IS NULL OR last_digest_at <= ?)))`,
+					model.DigestDaily, now.Add(-Period(model.DigestDaily)+CheckInterval),
+					model.DigestWeekly, now.Add(-Period(model.DigestWeekly)+CheckInterval)).
+				Where("digest_retry_at IS NULL OR digest_retry_at <= ?", now).
+				First(&user).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to fetch users due a digest: %w", err)
+			}
+			handled = true
+
+			ok, err := send(ctx, tx, m, &user, now)
+			if ok {
+				sent++
+			}
+			return err
+		})
+		if !handled {
+			return sent, err
+		}
+		if err != nil {
+			logger.Error().Err(err).Uint("user_id", user.ID).Msg("Failed to send digest")
+			// LastDigestAt stays put so the retry still covers everything this one would have.
+			// Half an interval, so the retry comes up on the next run
+			if err := db.Model(&model.User{}).Where("id = ?", user.ID).
+				UpdateColumn("digest_retry_at", now.Add(CheckInterval/2)).Error; err != nil {
+				return sent, fmt.Errorf("failed to postpone digest: %w", err)
+			}
+		}
+	}
+}
+
+// Content is what goes into one user's digest.
+type Content struct {
+	User          *model.User
+	Projects      []*ProjectMatch
+	Notifications []*model.Notification
+}
+
+// ProjectMatch is a project listed in a digest, with the name of the nonprofit behind it.
+type ProjectMatch struct {
+	model.Project
+	NonprofitName string
+}
+
+// Build collects what user's digest covers: ACTIVE projects that went live since since, match
+// their skills or their causes, and that they have neither been sent before nor applied to; and
+// their DIGEST notifications that have not gone out yet.
+func Build(db *gorm.DB, user *model.User, since time.Time) (*Content, error) {
+	content := &Content{User: user}
+
+	err := db.Model(&model.Project{}).
+		Select("projects.*, nonprofits.name AS nonprofit_name").
+		Joins("JOIN nonprofits ON nonprofits.id = projects.nonprofit_id").
+		Where("projects.status = ?", model.Active).
+		Where("projects.id IN (SELECT project_id FROM project_status_changes WHERE to_status = ? AND created_at > ? AND deleted_at IS NULL)", model.Active, since).
+		Where(`(EXISTS (SELECT 1 FROM project_skills ps JOIN user_skills us ON us.skill_id = ps.skill_id
+					WHERE ps.project_id = projects.id AND us.user_id = ?)
+				OR EXISTS (SELECT 1 FROM nonprofit_causes nc JOIN user_causes uc ON uc.cause_id = nc.cause_id
+					WHERE nc.nonprofit_id = projects.nonprofit_id AND uc.user_id = ?))`, user.ID, user.ID).
+		Where("projects.id NOT IN (SELECT project_id FROM sent_digest_projects WHERE user_id = ? AND deleted_at IS NULL)", user.ID).
+		Where("projects.id NOT IN (SELECT project_id FROM applications WHERE volunteer_id = ? AND deleted_at IS NULL)", user.ID).
+		Order("projects.created_at DESC").
+		Limit(maxProjects).
+		Find(&content.Projects).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch matching projects: %w", err)
+	}
+
+	if err := db.Where("user_id = ? AND digest = ? AND digested_at IS NULL", user.ID, true).
+		Order("created_at").
+		Limit(maxNotifications).
+		Find(&content.Notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch digest notifications: %w", err)
+	}
+	return content, nil
+}
+
+// send emails user their digest, if there is anything to tell them, and records it. The user's
+// LastDigestAt moves on either way. ok reports whether an email went out.
+func send(ctx context.Context, tx *gorm.DB, m mailer.Mailer, user *model.User, now time.Time) (ok bool, err error) {
+	since := now.Add(-Period(user.DigestFrequency))
+	if user.LastDigestAt != nil {
+		since = *user.LastDigestAt
+	}
+
+	content, err := Build(tx, user, since)
+	if err != nil {
+		return false, err
+	}
+
+	if len(content.Projects) > 0 || len(content.Notifications) > 0 {
+		digest := model.SentDigest{
+			UserID:            user.ID,
+			Frequency:         user.DigestFrequency,
+			ProjectCount:      len(content.Projects),
+			NotificationCount: len(content.Notifications),
+		}
+		if err := tx.Create(&digest).Error; err != nil {
+			return false, fmt.Errorf("failed to record digest: %w", err)
+		}
+		for _, project := range content.Projects {
+			if err := tx.Create(&model.SentDigestProject{SentDigestID: digest.ID, UserID: user.ID, ProjectID: project.ID}).Error; err != nil {
+				return false, fmt.Errorf("failed to record digest project: %w", err)
+			}
+		}
+		if len(content.Notifications) > 0 {
+			ids := make([]uint, len(content.Notifications))
+			for i, notification := range content.Notifications {
+				ids[i] = notification.ID
+			}
+			if err := tx.Model(&model.Notification{}).Where("id IN ?", ids).Update("digested_at", now).Error; err != nil {
+				return false, fmt.Errorf("failed to mark notifications digested: %w", err)
+			}
+		}
+
+		// Sent last, so a failed email rolls the records back and the digest is retried
+		msg, err := Render(content)
+		if err != nil {
+			return false, err
+		}
+		if err := m.Send(ctx, *msg); err != nil {
+			return false, fmt.Errorf("failed to send digest to user %d: %w", user.ID, err)
+		}
+		ok = true
+	}
+
+	if err := tx.Model(user).UpdateColumn("last_digest_at", now).Error; err != nil {
+		return ok, fmt.Errorf("failed to update last digest time: %w", err)
+	}
+	return ok, nil
+}
+
+// KindSendDue is the job that runs SendDue.
+const KindSendDue = "digests.send_due"
+
+// Handler returns the KindSendDue job handler.
+func Handler(db *gorm.DB, m mailer.Mailer, logger *zerolog.Logger) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) error {
+		_, err := SendDue(ctx, db, m, logger, time.Now())
+		return err
+	}
+}
@@ -0,0 +1,93 @@
+package digests
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+)
+
+// templateData is what the digest templates render.
+type templateData struct {
+	*Content
+	FrontendURL    string
+	UnsubscribeURL string
+}
+
+var funcs = map[string]any{
+	"projectURL": func(frontendURL string, project *ProjectMatch) string {
+		return fmt.Sprintf("%s/projects/%d", frontendURL, project.ID)
+	},
+}
+
+var htmlBody = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Your volunteering digest</title></head>
+<body>
+<p>Hi {{.User.FirstName}},</p>
+{{if .Projects}}<h2>New projects that match your skills and causes</h2>
+<ul>
+{{range .Projects}}<li><a href="{{projectURL $.FrontendURL .}}">{{.Title}}</a> &middot; {{.NonprofitName}}</li>
+{{end}}</ul>{{end}}
+{{if .Notifications}}<h2>What you missed</h2>
+<ul>
+{{range .Notifications}}<li>{{if .Link}}<a href="{{$.FrontendURL}}{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</li>
+{{end}}</ul>{{end}}
+<p style="font-size:small">You get this digest because of your email settings.
+<a href="{{.UnsubscribeURL}}">Unsubscribe</a></p>
+</body>
+</html>
+`))
+
+var textBody = texttemplate.Must(texttemplate.New("digest.txt").Funcs(funcs).Parse(`Hi {{.User.FirstName}},
+{{if .Projects}}
+New projects that match your skills and causes:
+{{range .Projects}}
+- {{.Title}} ({{.NonprofitName}})
+  {{projectURL $.FrontendURL .}}
+{{end}}{{end}}{{if .Notifications}}
+What you missed:
+{{range .Notifications}}
+- {{.Title}}{{if .Link}}
+  {{$.FrontendURL}}{{.Link}}{{end}}
+{{end}}{{end}}
+You get this digest because of your email settings. Unsubscribe: {{.UnsubscribeURL}}
+`))
+
+// Render turns content into the digest email, with one-click unsubscribe headers (RFC 8058).
+func Render(content *Content) (*mailer.Message, error) {
+	data := templateData{
+		Content:        content,
+		FrontendURL:    strings.TrimRight(os.Getenv("FRONTEND_URL"), "/"),
+		UnsubscribeURL: UnsubscribeURL(content.User.ID),
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlBody.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := textBody.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := "Your volunteering digest"
+	if n := len(content.Projects); n == 1 {
+		subject = "1 new project for you"
+	} else if n > 1 {
+		subject = fmt.Sprintf("%d new projects for you", n)
+	}
+	return &mailer.Message{
+		To:      content.User.Email,
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + data.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
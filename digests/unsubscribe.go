@@ -0,0 +1,112 @@
+package digests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// UnsubscribePath is where the unsubscribe handler is mounted.
+const UnsubscribePath = "/digests/unsubscribe"
+
+// signingKey signs unsubscribe links. UNSUBSCRIBE_SECRET can be set to rotate it independently
+// of login tokens.
+func signingKey() []byte {
+	if secret := os.Getenv("UNSUBSCRIBE_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// Sign returns the signature that lets an unsubscribe link act for userID without logging in.
+func Sign(userID uint) string {
+	mac := hmac.New(sha256.New, signingKey())
+	fmt.Fprintf(mac, "digest-unsubscribe:%d", userID)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeURL is the signed one-click unsubscribe link for userID. PUBLIC_URL is the API's
+// own public address, since the link is handled here rather than by the frontend.
+func UnsubscribeURL(userID uint) string {
+	base := strings.TrimRight(os.Getenv("PUBLIC_URL"), "/")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	query := url.Values{"user": {strconv.FormatUint(uint64(userID), 10)}, "sig": {Sign(userID)}}
+	return base + UnsubscribePath + "?" + query.Encode()
+}
+
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="user" value="{{.User}}">
+<input type="hidden" name="sig" value="{{.Sig}}">
+<p>Stop getting digest emails?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+var unsubscribedPage = template.Must(template.New("unsubscribed").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Unsubscribed</title></head>
+<body>
+<p>You won't get digest emails any more. You can turn them back on in your <a href="{{.}}">settings</a>.</p>
+</body>
+</html>
+`))
+
+// UnsubscribeHandler turns digests off for the user a signed link was made for. GET, the link
+// clicked from the email, only asks to confirm: mail scanners and link previews follow links, so
+// the change is made on POST, either from that form or as the one-click request mail clients
+// send on the user's behalf (RFC 8058). POST is idempotent.
+func UnsubscribeHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		userID, err := strconv.ParseUint(r.Form.Get("user"), 10, 0)
+		if err != nil || !hmac.Equal([]byte(r.Form.Get("sig")), []byte(Sign(uint(userID)))) {
+			http.Error(w, "invalid unsubscribe link", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			confirmPage.Execute(w, map[string]string{"Action": UnsubscribePath, "User": r.Form.Get("user"), "Sig": r.Form.Get("sig")})
+			return
+		}
+
+		if err := db.Model(&model.User{}).Where("id = ?", userID).
+			Update("digest_frequency", model.DigestNever).Error; err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if r.PostForm.Get("List-Unsubscribe") == "One-Click" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		unsubscribedPage.Execute(w, strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")+"/settings")
+	}
+}
@@ -0,0 +1,143 @@
+// Package export serves downloadable reports over plain HTTP, for clients that want a file
+// rather than a GraphQL response.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/services"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"gorm.io/gorm"
+)
+
+// ApplicationsCSVHandler serves GET /projects/{id}/applications.csv: one row per application with
+// a column per screening question. Only members of the owning nonprofit and platform admins may
+// download it.
+func ApplicationsCSVHandler(db *gorm.DB, nonprofits services.NonprofitRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.GetUserFromContext(r.Context())
+		if err != nil {
+			http.Error(w, "Unauthenticated", http.StatusUnauthorized)
+			return
+		}
+
+		projectID, err := utils.IdToUint(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid project ID", http.StatusBadRequest)
+			return
+		}
+		var project model.Project
+		if err := db.First(&project, projectID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				http.Error(w, "Project not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to fetch project", http.StatusInternalServerError)
+			return
+		}
+
+		if _, ok, err := services.ProjectActor(nonprofits, user, project.NonprofitID); err != nil {
+			http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+			return
+		} else if !ok {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		rows, err := applicationRows(db, project.ID)
+		if err != nil {
+			http.Error(w, "Failed to build export", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d-applications.csv"`, project.ID))
+		out := csv.NewWriter(w)
+		out.WriteAll(rows) // headers are sent, nothing useful to do on error
+	}
+}
+
+// applicationRows builds the CSV for a project, header row first.
+func applicationRows(db *gorm.DB, projectID uint) ([][]string, error) {
+	var questions []model.ApplicationQuestion
+	if err := db.Where("project_id = ?", projectID).Order("position ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application questions: %w", err)
+	}
+	var applications []model.Application
+	if err := db.Where("project_id = ?", projectID).Preload("Volunteer").Order("applied_at ASC").Find(&applications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch applications: %w", err)
+	}
+	var slots []model.ProjectRoleSlot
+	if err := db.Unscoped().Where("project_id = ?", projectID).Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role slots: %w", err)
+	}
+	slotTitles := make(map[uint]string, len(slots))
+	for _, slot := range slots {
+		slotTitles[slot.ID] = slot.Title
+	}
+
+	ids := make([]uint, len(applications))
+	for i, application := range applications {
+		ids[i] = application.ID
+	}
+	var answers []model.ApplicationAnswer
+	if len(ids) > 0 {
+		if err := db.Where("application_id IN ?", ids).Find(&answers).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch application answers: %w", err)
+		}
+	}
+	type answerKey struct{ application, question uint }
+	values := make(map[answerKey][]string, len(answers))
+	for _, answer := range answers {
+		values[answerKey{answer.ApplicationID, answer.QuestionID}] = answer.Values
+	}
+
+	header := []string{"application_id", "applied_at", "status", "volunteer_name", "volunteer_email", "role_slot", "message"}
+	for _, question := range questions {
+		// Prompts are written by the nonprofit, but still end up in someone's spreadsheet
+		header = append(header, escapeFormula(question.Prompt))
+	}
+	rows := [][]string{header}
+
+	for _, application := range applications {
+		row := []string{
+			fmt.Sprintf("%d", application.ID),
+			utils.FormatTime(application.AppliedAt),
+			string(application.Status),
+			application.Volunteer.FirstName + " " + application.Volunteer.LastName,
+			application.Volunteer.Email,
+			"",
+			"",
+		}
+		if application.SlotID != nil {
+			row[5] = slotTitles[*application.SlotID]
+		}
+		if application.Message != nil {
+			row[6] = *application.Message
+		}
+		for _, question := range questions {
+			row = append(row, strings.Join(values[answerKey{application.ID, question.ID}], "; "))
+		}
+		for i := range row {
+			row[i] = escapeFormula(row[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// escapeFormula stops spreadsheet applications from evaluating user-supplied text that starts
+// like a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
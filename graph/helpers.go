@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/services"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+)
+
+// Helpers shared by resolvers. Kept out of schema.resolvers.go so gqlgen leaves them alone.
+
+// profileVisible reports whether the current user may see user's track record (impact, ratings,
+// reviews and badges): anyone for public profiles, else only the user themselves and platform
+// admins.
+func profileVisible(ctx context.Context, user *model.User) bool {
+	if user.ProfileVisibility == model.ProfilePublic {
+		return true
+	}
+	currentUser, err := auth.GetUserFromContext(ctx)
+	return err == nil && (currentUser.ID == user.ID || currentUser.Role == model.PlatformAdmin)
+}
+
+// maxPageSize caps the limit argument of list queries.
+const maxPageSize = 100
+
+// clampLimit turns an optional limit argument into a page size between 1 and maxPageSize.
+func clampLimit(limit *int32, fallback int) int {
+	if limit == nil || *limit <= 0 {
+		return fallback
+	}
+	if *limit > maxPageSize {
+		return maxPageSize
+	}
+	return int(*limit)
+}
+
+// findConversation loads a conversation by its GraphQL ID.
+func (r *Resolver) findConversation(id string) (*model.Conversation, error) {
+	conversationID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := r.Repos.Conversations.Find(conversationID)
+	if errors.Is(err, services.ErrNotFound) {
+		return nil, fmt.Errorf("conversation with ID %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+// findWebhookSubscription loads a webhook subscription by its GraphQL ID, returning a "not found"
+// error if it does not exist.
+func (r *Resolver) findWebhookSubscription(id string) (*model.WebhookSubscription, error) {
+	subscriptionID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := r.Repos.Webhooks.Subscription(subscriptionID)
+	if errors.Is(err, services.ErrNotFound) {
+		return nil, fmt.Errorf("webhook subscription with ID %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// auditQuery turns the GraphQL audit log filter and cursor into an audit.Filter and event ID.
+func auditQuery(filter *model.AuditLogFilter, before *string) (audit.Filter, uint, error) {
+	var query audit.Filter
+	var beforeID uint
+	if before != nil {
+		id, err := utils.IdToUint(*before)
+		if err != nil {
+			return query, 0, err
+		}
+		beforeID = id
+	}
+	if filter == nil {
+		return query, beforeID, nil
+	}
+
+	v := validation.New()
+	if filter.ActorID != nil {
+		id, err := utils.IdToUint(*filter.ActorID)
+		v.Check(err == nil, "filter.actorId", "must be a valid ID")
+		query.ActorID = &id
+	}
+	if filter.NonprofitID != nil {
+		id, err := utils.IdToUint(*filter.NonprofitID)
+		v.Check(err == nil, "filter.nonprofitId", "must be a valid ID")
+		query.NonprofitID = &id
+	}
+	if filter.Action != nil {
+		query.Action = *filter.Action
+	}
+	if filter.TargetType != nil {
+		query.TargetType = *filter.TargetType
+	}
+	if filter.TargetID != nil {
+		query.TargetID = *filter.TargetID
+	}
+	if filter.From != nil {
+		query.From = v.DateTime(*filter.From, "filter.from")
+	}
+	if filter.To != nil {
+		query.To = v.DateTime(*filter.To, "filter.to")
+	}
+	return query, beforeID, v.Err()
+}
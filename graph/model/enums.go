@@ -8,6 +8,17 @@ type EngagementStatus string
 type NonprofitSize string
 type TimeCommitment string
 type UrgencyLevel string
+type ImageSize string
+type ImageFormat string
+type QuestionType string
+type Granularity string
+type ProfileVisibility string
+type ReviewDirection string
+type NotificationEvent string
+type NotificationChannel string
+type DigestFrequency string
+type WebhookEvent string
+type WebhookDeliveryStatus string
 
 const (
 	Monday    Weekday = "MONDAY"
@@ -35,10 +46,12 @@ const (
 )
 
 const (
-	Pending   ApplicationStatus = "PENDING"
-	Accepted  ApplicationStatus = "ACCEPTED"
-	Rejected  ApplicationStatus = "REJECTED"
-	Withdrawn ApplicationStatus = "WITHDRAWN"
+	Pending    ApplicationStatus = "PENDING"
+	Accepted   ApplicationStatus = "ACCEPTED"
+	Rejected   ApplicationStatus = "REJECTED"
+	Withdrawn  ApplicationStatus = "WITHDRAWN"
+	Waitlisted ApplicationStatus = "WAITLISTED"
+	Expired    ApplicationStatus = "EXPIRED"
 )
 
 const (
@@ -67,3 +80,83 @@ const (
 	High     UrgencyLevel = "HIGH"
 	Critical UrgencyLevel = "CRITICAL"
 )
+
+const (
+	ImageSmall  ImageSize = "SMALL"
+	ImageMedium ImageSize = "MEDIUM"
+	ImageLarge  ImageSize = "LARGE"
+)
+
+const (
+	ImagePng  ImageFormat = "PNG"
+	ImageWebp ImageFormat = "WEBP"
+)
+
+const (
+	QuestionText           QuestionType = "TEXT"
+	QuestionURL            QuestionType = "URL"
+	QuestionYesNo          QuestionType = "YES_NO"
+	QuestionSingleChoice   QuestionType = "SINGLE_CHOICE"
+	QuestionMultipleChoice QuestionType = "MULTIPLE_CHOICE"
+)
+
+const (
+	GranularityDay   Granularity = "DAY"
+	GranularityWeek  Granularity = "WEEK"
+	GranularityMonth Granularity = "MONTH"
+)
+
+const (
+	ProfilePrivate ProfileVisibility = "PRIVATE"
+	ProfilePublic  ProfileVisibility = "PUBLIC"
+)
+
+const (
+	ReviewOfNonprofit ReviewDirection = "OF_NONPROFIT"
+	ReviewOfVolunteer ReviewDirection = "OF_VOLUNTEER"
+)
+
+const (
+	NotifyApplicationReceived   NotificationEvent = "APPLICATION_RECEIVED"
+	NotifyApplicationAccepted   NotificationEvent = "APPLICATION_ACCEPTED"
+	NotifyApplicationWaitlisted NotificationEvent = "APPLICATION_WAITLISTED"
+	NotifyApplicationRejected   NotificationEvent = "APPLICATION_REJECTED"
+	NotifyHoursApproved         NotificationEvent = "HOURS_APPROVED"
+	NotifyHoursRejected         NotificationEvent = "HOURS_REJECTED"
+	NotifyProjectStatusChanged  NotificationEvent = "PROJECT_STATUS_CHANGED"
+	NotifyMessageReceived       NotificationEvent = "MESSAGE_RECEIVED"
+	NotifyApplicationExpired    NotificationEvent = "APPLICATION_EXPIRED"
+	NotifyEngagementIdle        NotificationEvent = "ENGAGEMENT_IDLE"
+	NotifyEngagementCancelled   NotificationEvent = "ENGAGEMENT_CANCELLED"
+	NotifyHoursReminder         NotificationEvent = "HOURS_REMINDER"
+)
+
+const (
+	ChannelInApp  NotificationChannel = "IN_APP"
+	ChannelEmail  NotificationChannel = "EMAIL"
+	ChannelDigest NotificationChannel = "DIGEST"
+)
+
+const (
+	DigestNever  DigestFrequency = "NEVER"
+	DigestDaily  DigestFrequency = "DAILY"
+	DigestWeekly DigestFrequency = "WEEKLY"
+)
+
+const (
+	WebhookApplicationSubmitted     WebhookEvent = "APPLICATION_SUBMITTED"
+	WebhookApplicationStatusChanged WebhookEvent = "APPLICATION_STATUS_CHANGED"
+	WebhookEngagementStarted        WebhookEvent = "ENGAGEMENT_STARTED"
+	WebhookEngagementCompleted      WebhookEvent = "ENGAGEMENT_COMPLETED"
+	WebhookEngagementCancelled      WebhookEvent = "ENGAGEMENT_CANCELLED"
+	WebhookHoursLogged              WebhookEvent = "HOURS_LOGGED"
+	WebhookHoursReviewed            WebhookEvent = "HOURS_REVIEWED"
+	WebhookProjectStatusChanged     WebhookEvent = "PROJECT_STATUS_CHANGED"
+	WebhookPing                     WebhookEvent = "PING"
+)
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "PENDING"
+	DeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	DeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
@@ -25,6 +25,21 @@ func (w Weekdays) Value() (driver.Value, error) {
 	return json.Marshal(w)
 }
 
+// Strings is a string list stored as a jsonb column.
+type Strings []string
+
+func (s *Strings) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid string array")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s Strings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
 type User struct {
 	gorm.Model
 	Email        string `gorm:"uniqueIndex;not null"`
@@ -40,6 +55,20 @@ type User struct {
 	Causes       []Cause      `gorm:"many2many:user_causes;"`
 	Availability Availability `gorm:"embedded"`
 
+	// Public impact profile, see analytics.VolunteerImpact. ProfileSlug is assigned the first
+	// time the profile is made public and kept so shared links stay valid.
+	ProfileVisibility ProfileVisibility `gorm:"type:varchar(20);default:PRIVATE"`
+	ProfileSlug       *string           `gorm:"uniqueIndex;type:varchar(64)"`
+
+	// Email digest of new matching projects and DIGEST notifications, see digests.SendDue
+	DigestFrequency DigestFrequency `gorm:"type:varchar(10);default:WEEKLY"`
+	LastDigestAt    *time.Time
+	DigestRetryAt   *time.Time // after a failed digest, when to try again
+
+	// Login brute-force protection, see auth.AuthService.Authenticate
+	FailedLoginAttempts int
+	LockedUntil         *time.Time
+
 	// Relationships
 	Applications []Application `gorm:"foreignKey:VolunteerID"`
 	Engagements  []Engagement  `gorm:"foreignKey:VolunteerID"`
@@ -73,11 +102,42 @@ type Project struct {
 	EndDate        *time.Time
 	NonprofitID    uint
 
+	// Timezone is the IANA zone the project's shifts are scheduled in
+	Timezone string `gorm:"type:varchar(50);default:UTC"`
+
+	// VolunteersNeeded caps accepted applications; 0 means no limit. RoleSlots optionally split
+	// that capacity by role, see lifecycle.AcceptOrWaitlist.
+	VolunteersNeeded int
+
 	// Relationships
 	Applications []Application `gorm:"foreignKey:ProjectID"`
 	Engagements  []Engagement  `gorm:"foreignKey:ProjectID"`
 }
 
+// ProjectRoleSlot reserves part of a project's capacity for one role, optionally tied to one
+// of the project's SkillsNeeded.
+type ProjectRoleSlot struct {
+	gorm.Model
+	ProjectID uint `gorm:"index"`
+	Title     string
+	Capacity  int
+
+	SkillID *uint
+}
+
+// ProjectStatusChange is one entry in a project's status history, see lifecycle.TransitionProject.
+type ProjectStatusChange struct {
+	gorm.Model
+	ProjectID  uint           `gorm:"index"`
+	FromStatus *ProjectStatus `gorm:"type:varchar(20)"` // nil for the status a project was created with
+	ToStatus   ProjectStatus  `gorm:"type:varchar(20)"`
+	Reason     *string
+
+	// Relationships
+	ChangedByID *uint
+	ChangedBy   *User `gorm:"foreignKey:ChangedByID"` // nil for system changes
+}
+
 type Availability struct {
 	HoursPerWeek  int      `gorm:"type:int"`
 	DaysAvailable Weekdays `gorm:"type:jsonb"`
@@ -91,14 +151,268 @@ type Location struct {
 	Remote  bool
 }
 
+// Shift is a time-boxed slot of work on a project, e.g. an event on Saturday 9-12. Times are
+// stored as instants; they are entered and shown in the project's Timezone.
+type Shift struct {
+	gorm.Model
+	ProjectID uint      `gorm:"index"`
+	StartsAt  time.Time `gorm:"index"`
+	EndsAt    time.Time `gorm:"index"`
+	Capacity  int
+	Location  string
+
+	// HoursPrefilledAt is set once HoursLogged entries were created for the shift's volunteers
+	HoursPrefilledAt *time.Time
+}
+
+// ShiftSignup is a volunteer's place on a shift. Cancelled signups are kept for the record.
+type ShiftSignup struct {
+	gorm.Model
+	ShiftID      uint `gorm:"index"`
+	VolunteerID  uint `gorm:"index"`
+	EngagementID uint
+	CancelledAt  *time.Time
+}
+
+// Certificate is a statement of a volunteer's approved hours, issued for a date range. Its lines
+// are a snapshot taken at issue time, so the PDF and the public verification keep showing what
+// was certified even if the underlying hours change later.
+type Certificate struct {
+	gorm.Model
+	Code        string `gorm:"uniqueIndex;type:varchar(32)"`
+	VolunteerID uint   `gorm:"index"`
+	Volunteer   User   `gorm:"foreignKey:VolunteerID"`
+	From        *time.Time
+	To          *time.Time
+	TotalHours  float64
+	// Digest is a SHA-256 over the certified content, printed on the PDF for comparison
+	Digest string `gorm:"type:varchar(64)"`
+
+	Lines []CertificateLine `gorm:"foreignKey:CertificateID"`
+}
+
+// CertificateLine is the approved hours on one project signed off by one approver.
+type CertificateLine struct {
+	gorm.Model
+	CertificateID uint `gorm:"index"`
+	NonprofitName string
+	ProjectTitle  string
+	ApproverName  string
+	Hours         float64
+}
+
+// Review is one side's rating of a completed engagement: the volunteer rating the nonprofit
+// (and, separately, the project) or a coordinator rating the volunteer. Hidden reviews were taken
+// down by moderation and are left out of listings and aggregates.
+type Review struct {
+	gorm.Model
+	EngagementID  uint            `gorm:"uniqueIndex:idx_review_engagement_direction"`
+	Direction     ReviewDirection `gorm:"type:varchar(20);uniqueIndex:idx_review_engagement_direction"`
+	ReviewerID    uint
+	VolunteerID   uint `gorm:"index"`
+	NonprofitID   uint `gorm:"index"`
+	ProjectID     uint `gorm:"index"`
+	Rating        int
+	ProjectRating *int
+	Comment       *string
+	Hidden        bool
+}
+
+// ReviewFlag is a report that a review breaks the rules, waiting for a platform admin.
+type ReviewFlag struct {
+	gorm.Model
+	ReviewID     uint `gorm:"index"`
+	FlaggedByID  uint
+	Reason       string
+	ResolvedAt   *time.Time
+	ResolvedByID *uint
+}
+
+// SkillEndorsement is a coordinator vouching that a volunteer used a skill on an engagement.
+type SkillEndorsement struct {
+	gorm.Model
+	EngagementID uint `gorm:"uniqueIndex:idx_endorsement_engagement_skill"`
+	SkillID      uint `gorm:"uniqueIndex:idx_endorsement_engagement_skill"`
+	VolunteerID  uint `gorm:"index"`
+	NonprofitID  uint
+	EndorserID   uint
+}
+
+// Conversation is the message thread between a volunteer and the nonprofit behind a project,
+// opened from an application and carried over to the engagement that follows it. Every member of
+// the nonprofit takes part on the nonprofit's side.
+type Conversation struct {
+	gorm.Model
+	ApplicationID *uint `gorm:"uniqueIndex"`
+	EngagementID  *uint `gorm:"uniqueIndex"`
+	ProjectID     uint  `gorm:"index"`
+	NonprofitID   uint  `gorm:"index"`
+	VolunteerID   uint  `gorm:"index"`
+	LastMessageAt *time.Time
+}
+
+// Message is a single message posted to a conversation.
+type Message struct {
+	gorm.Model
+	ConversationID uint `gorm:"index"`
+	SenderID       uint
+	Body           string
+}
+
+// ConversationRead records up to when a user has read a conversation; anything newer from
+// someone else counts as unread.
+type ConversationRead struct {
+	ConversationID uint `gorm:"primaryKey"`
+	UserID         uint `gorm:"primaryKey"`
+	LastReadAt     time.Time
+}
+
+// Notification tells a user that something happened. InApp notifications are listed in the
+// notification center; Digest ones wait to be included in the user's next email digest. Which of
+// the two (and whether it is also emailed straight away) follows the user's preferences.
+type Notification struct {
+	gorm.Model
+	UserID uint              `gorm:"index"`
+	Event  NotificationEvent `gorm:"type:varchar(40)"`
+	Title  string
+	Body   string
+	Link   string // frontend path, e.g. /projects/12
+	InApp  bool
+	Digest bool
+	ReadAt *time.Time
+
+	DigestedAt *time.Time // when it went out in a digest
+}
+
+// SentDigest records a digest email sent to a user.
+type SentDigest struct {
+	gorm.Model
+	UserID            uint            `gorm:"index"`
+	Frequency         DigestFrequency `gorm:"type:varchar(10)"`
+	ProjectCount      int
+	NotificationCount int
+}
+
+// SentDigestProject records that a project was included in a user's digest, so it is never
+// sent to them again.
+type SentDigestProject struct {
+	gorm.Model
+	SentDigestID uint `gorm:"index"`
+	UserID       uint `gorm:"uniqueIndex:idx_sent_digest_project_user_project"`
+	ProjectID    uint `gorm:"uniqueIndex:idx_sent_digest_project_user_project"`
+}
+
+// WebhookSubscription pushes a nonprofit's events to one of its own systems, such as a CRM.
+// Events lists the WebhookEvents it receives; payloads are signed with Secret.
+type WebhookSubscription struct {
+	gorm.Model
+	NonprofitID uint `gorm:"index"`
+	URL         string
+	Secret      string
+	Events      Strings `gorm:"type:jsonb"`
+	Active      bool
+	CreatedByID *uint
+}
+
+// WebhookDelivery is one event sent to a WebhookSubscription, kept as the delivery log.
+// Redelivering creates a new delivery of the same payload pointing back at the original.
+type WebhookDelivery struct {
+	gorm.Model
+	SubscriptionID uint                  `gorm:"index"`
+	Event          WebhookEvent          `gorm:"type:varchar(40)"`
+	Payload        string                `gorm:"type:jsonb"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(10)"`
+	Attempts       int32
+	ResponseStatus *int32
+	Error          *string
+	DeliveredAt    *time.Time
+	RedeliveryOfID *uint
+}
+
+// AuditEvent records who did what to which record, for answering questions like "who verified
+// this nonprofit". Rows are only ever inserted. TargetID is the GraphQL ID of the target and
+// NonprofitID, when set, lets the nonprofit's members see the event too.
+type AuditEvent struct {
+	ID          uint         `gorm:"primaryKey"`
+	CreatedAt   time.Time    `gorm:"index"`
+	ActorID     *uint        `gorm:"index"`
+	Actor       *User        `gorm:"foreignKey:ActorID"`
+	Action      string       `gorm:"type:varchar(60);index"`
+	TargetType  string       `gorm:"type:varchar(40);index:idx_audit_event_target"`
+	TargetID    *string      `gorm:"type:varchar(40);index:idx_audit_event_target"`
+	NonprofitID *uint        `gorm:"index"`
+	Changes     AuditChanges `gorm:"type:jsonb"`
+	IP          *string      `gorm:"type:varchar(64)"`
+	RequestID   *string      `gorm:"type:varchar(64)"`
+}
+
+// AuditChange is one field an audited action changed. From and To hold JSON values and are nil
+// when the record didn't exist before or after it.
+type AuditChange struct {
+	Field string  `json:"field"`
+	From  *string `json:"from,omitempty"`
+	To    *string `json:"to,omitempty"`
+}
+
+// AuditChanges is the list of changes of an AuditEvent, stored as a jsonb column.
+type AuditChanges []*AuditChange
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid audit changes")
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// NotificationPreference is a user's choice of channels for one event. Events without a row use
+// notifications.DefaultChannels.
+type NotificationPreference struct {
+	gorm.Model
+	UserID uint              `gorm:"uniqueIndex:idx_notification_preference_user_event"`
+	Event  NotificationEvent `gorm:"type:varchar(40);uniqueIndex:idx_notification_preference_user_event"`
+	InApp  bool
+	Email  bool
+	Digest bool
+}
+
+// ApplicationQuestion is a screening question a project asks of everyone who applies. Options
+// lists the allowed answers for the choice types.
+type ApplicationQuestion struct {
+	gorm.Model
+	ProjectID uint `gorm:"index"`
+	Position  int
+	Prompt    string
+	Type      QuestionType `gorm:"type:varchar(20)"`
+	Required  bool
+	Options   Strings `gorm:"type:jsonb"`
+}
+
+// ApplicationAnswer is an applicant's answer to one ApplicationQuestion. Values holds a single
+// entry for every type except MULTIPLE_CHOICE; YES_NO answers are "true" or "false".
+type ApplicationAnswer struct {
+	gorm.Model
+	ApplicationID uint    `gorm:"uniqueIndex:idx_application_question"`
+	QuestionID    uint    `gorm:"uniqueIndex:idx_application_question"`
+	Values        Strings `gorm:"type:jsonb"`
+}
+
 type Application struct {
 	gorm.Model
 	Message   *string
 	Status    ApplicationStatus `gorm:"type:varchar(20);index"`
 	AppliedAt time.Time
 	DecidedAt *time.Time
+	// WaitlistedAt orders the waitlist; set when an application is accepted past capacity.
+	WaitlistedAt *time.Time
 
 	// Relationships
+	SlotID *uint
+
 	VolunteerID uint
 	Volunteer   User `gorm:"foreignKey:VolunteerID"`
 
@@ -114,6 +428,10 @@ type Engagement struct {
 	Feedback            *string
 	FeedbackSubmittedAt *time.Time
 
+	// Reminders sent by the maintenance jobs, see maintenance.NudgeIdleEngagements
+	IdleNudgedAt    *time.Time
+	HoursRemindedAt *time.Time
+
 	// Relationships
 	VolunteerID uint
 	Volunteer   User `gorm:"foreignKey:VolunteerID"`
@@ -152,6 +470,19 @@ type Cause struct {
 	Description string
 }
 
+// AdminDashboard is the period the platform admin dashboard reports on; each of its sections is
+// computed by its own field resolver, only when requested.
+type AdminDashboard struct {
+	From time.Time
+	To   time.Time
+}
+
+// VerificationBacklog summarises nonprofits still waiting for verification.
+type VerificationBacklog struct {
+	Pending         int
+	OldestCreatedAt *time.Time
+}
+
 // Upload represents a file upload in GraphQL.
 type Upload struct {
 	Filename string
@@ -6,6 +6,40 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 )
 
+type AnswerInput struct {
+	QuestionID string   `json:"questionId"`
+	Values     []string `json:"values"`
+}
+
+type ApplicationQuestionInput struct {
+	Prompt   string       `json:"prompt"`
+	Type     QuestionType `json:"type"`
+	Required bool         `json:"required"`
+	Options  []string     `json:"options,omitempty"`
+}
+
+type ApplicationStats struct {
+	Total                 int32                     `json:"total"`
+	ByStatus              []*ApplicationStatusCount `json:"byStatus"`
+	AcceptanceRate        *float64                  `json:"acceptanceRate,omitempty"`
+	MedianHoursToDecision *float64                  `json:"medianHoursToDecision,omitempty"`
+}
+
+type ApplicationStatusCount struct {
+	Status ApplicationStatus `json:"status"`
+	Count  int32             `json:"count"`
+}
+
+type AuditLogFilter struct {
+	ActorID     *string `json:"actorId,omitempty"`
+	Action      *string `json:"action,omitempty"`
+	TargetType  *string `json:"targetType,omitempty"`
+	TargetID    *string `json:"targetId,omitempty"`
+	NonprofitID *string `json:"nonprofitId,omitempty"`
+	From        *string `json:"from,omitempty"`
+	To          *string `json:"to,omitempty"`
+}
+
 type AuthPayload struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refreshToken"`
@@ -23,6 +57,39 @@ type AvailabilityInput struct {
 	Timezone      string    `json:"timezone"`
 }
 
+type CauseUsage struct {
+	Cause      *Cause `json:"cause"`
+	Volunteers int32  `json:"volunteers"`
+	Nonprofits int32  `json:"nonprofits"`
+}
+
+type EngagementStats struct {
+	Active    int32 `json:"active"`
+	Completed int32 `json:"completed"`
+	Cancelled int32 `json:"cancelled"`
+}
+
+type HoursPeriod struct {
+	PeriodStart   string  `json:"periodStart"`
+	ApprovedHours float64 `json:"approvedHours"`
+	PendingHours  float64 `json:"pendingHours"`
+}
+
+type HoursTotals struct {
+	Approved float64 `json:"approved"`
+	Pending  float64 `json:"pending"`
+	Rejected float64 `json:"rejected"`
+}
+
+type ImpactSummary struct {
+	TotalApprovedHours float64       `json:"totalApprovedHours"`
+	NonprofitsHelped   int32         `json:"nonprofitsHelped"`
+	ProjectsCompleted  int32         `json:"projectsCompleted"`
+	CausesServed       []*Cause      `json:"causesServed"`
+	SkillsUsed         []*Skill      `json:"skillsUsed"`
+	Endorsements       []*SkillBadge `json:"endorsements"`
+}
+
 type LocationInput struct {
 	City    string `json:"city"`
 	State   string `json:"state"`
@@ -30,6 +97,20 @@ type LocationInput struct {
 	Remote  bool   `json:"remote"`
 }
 
+type ModerationQueueFilter struct {
+	NonprofitID           *string       `json:"nonprofitId,omitempty"`
+	VerifiedNonprofitOnly *bool         `json:"verifiedNonprofitOnly,omitempty"`
+	Urgency               *UrgencyLevel `json:"urgency,omitempty"`
+	OverdueOnly           *bool         `json:"overdueOnly,omitempty"`
+}
+
+type ModerationQueueItem struct {
+	Project     *Project `json:"project"`
+	SubmittedAt string   `json:"submittedAt"`
+	AgeHours    float64  `json:"ageHours"`
+	Overdue     bool     `json:"overdue"`
+}
+
 type Mutation struct {
 }
 
@@ -44,6 +125,37 @@ type NonprofitInput struct {
 	Logo        *graphql.Upload `json:"logo,omitempty"`
 }
 
+type NonprofitStats struct {
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	Granularity  Granularity       `json:"granularity"`
+	Hours        []*HoursPeriod    `json:"hours"`
+	Engagements  *EngagementStats  `json:"engagements"`
+	Applications *ApplicationStats `json:"applications"`
+	TopSkills    []*SkillDemand    `json:"topSkills"`
+	Retention    *RetentionStats   `json:"retention"`
+}
+
+type PersistedQueryRegistration struct {
+	Version string `json:"version"`
+	Added   int32  `json:"added"`
+	Total   int32  `json:"total"`
+}
+
+type PlatformHealth struct {
+	DatabaseOk        bool    `json:"databaseOk"`
+	DatabaseLatencyMs float64 `json:"databaseLatencyMs"`
+	OpenConnections   int32   `json:"openConnections"`
+	UptimeSeconds     float64 `json:"uptimeSeconds"`
+	Users             int32   `json:"users"`
+	Nonprofits        int32   `json:"nonprofits"`
+	ActiveProjects    int32   `json:"activeProjects"`
+	PendingModeration int32   `json:"pendingModeration"`
+	OverdueModeration int32   `json:"overdueModeration"`
+	UnreviewedHours   int32   `json:"unreviewedHours"`
+	PersistedQueries  int32   `json:"persistedQueries"`
+}
+
 type ProfileInput struct {
 	FirstName *string         `json:"firstName,omitempty"`
 	LastName  *string         `json:"lastName,omitempty"`
@@ -55,19 +167,73 @@ type ProfileInput struct {
 }
 
 type ProjectInput struct {
-	NonprofitID    string         `json:"nonprofitId"`
-	Title          string         `json:"title"`
-	Description    string         `json:"description"`
-	SkillsNeeded   []string       `json:"skillsNeeded"`
-	TimeCommitment TimeCommitment `json:"timeCommitment"`
-	Urgency        UrgencyLevel   `json:"urgency"`
-	StartDate      *string        `json:"startDate,omitempty"`
-	EndDate        *string        `json:"endDate,omitempty"`
+	NonprofitID      string                      `json:"nonprofitId"`
+	Title            string                      `json:"title"`
+	Description      string                      `json:"description"`
+	SkillsNeeded     []string                    `json:"skillsNeeded"`
+	TimeCommitment   TimeCommitment              `json:"timeCommitment"`
+	Urgency          UrgencyLevel                `json:"urgency"`
+	StartDate        *string                     `json:"startDate,omitempty"`
+	EndDate          *string                     `json:"endDate,omitempty"`
+	Timezone         *string                     `json:"timezone,omitempty"`
+	VolunteersNeeded *int32                      `json:"volunteersNeeded,omitempty"`
+	RoleSlots        []*RoleSlotInput            `json:"roleSlots,omitempty"`
+	Questions        []*ApplicationQuestionInput `json:"questions,omitempty"`
+}
+
+type ProjectStatusCount struct {
+	Status ProjectStatus `json:"status"`
+	Count  int32         `json:"count"`
 }
 
 type Query struct {
 }
 
+type RatingSummary struct {
+	Average *float64 `json:"average,omitempty"`
+	Count   int32    `json:"count"`
+}
+
+type RetentionStats struct {
+	Volunteers          int32    `json:"volunteers"`
+	ReturningVolunteers int32    `json:"returningVolunteers"`
+	RetentionRate       *float64 `json:"retentionRate,omitempty"`
+}
+
+type ReviewInput struct {
+	Rating         int32    `json:"rating"`
+	ProjectRating  *int32   `json:"projectRating,omitempty"`
+	Comment        *string  `json:"comment,omitempty"`
+	EndorsedSkills []string `json:"endorsedSkills,omitempty"`
+}
+
+type RoleSlotInput struct {
+	Title    string  `json:"title"`
+	Capacity int32   `json:"capacity"`
+	Skill    *string `json:"skill,omitempty"`
+}
+
+type SendMessageInput struct {
+	ConversationID *string `json:"conversationId,omitempty"`
+	ApplicationID  *string `json:"applicationId,omitempty"`
+	EngagementID   *string `json:"engagementId,omitempty"`
+	Body           string  `json:"body"`
+}
+
+type ShiftInput struct {
+	Date      string  `json:"date"`
+	StartTime string  `json:"startTime"`
+	EndTime   string  `json:"endTime"`
+	Capacity  int32   `json:"capacity"`
+	Location  *string `json:"location,omitempty"`
+}
+
+type SignupCount struct {
+	Day   string   `json:"day"`
+	Role  UserRole `json:"role"`
+	Count int32    `json:"count"`
+}
+
 type SignupInput struct {
 	Email     string   `json:"email"`
 	Password  string   `json:"password"`
@@ -76,5 +242,29 @@ type SignupInput struct {
 	Role      UserRole `json:"role"`
 }
 
+type SkillBadge struct {
+	Skill        *Skill `json:"skill"`
+	Endorsements int32  `json:"endorsements"`
+	Verified     bool   `json:"verified"`
+}
+
+type SkillDemand struct {
+	Skill        *Skill `json:"skill"`
+	Projects     int32  `json:"projects"`
+	Applications int32  `json:"applications"`
+}
+
+type SkillUsage struct {
+	Skill      *Skill `json:"skill"`
+	Volunteers int32  `json:"volunteers"`
+	Projects   int32  `json:"projects"`
+}
+
 type Subscription struct {
 }
+
+type WebhookSubscriptionInput struct {
+	URL    string         `json:"url"`
+	Events []WebhookEvent `json:"events"`
+	Active *bool          `json:"active,omitempty"`
+}
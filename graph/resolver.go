@@ -1,8 +1,15 @@
 package graph
 
 import (
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/activity"
 	"github.com/prkagrawal/cosmos-bk2/auth"
-	"github.com/prkagrawal/cosmos-bk2/database"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/messaging"
+	"github.com/prkagrawal/cosmos-bk2/persisted"
+	"github.com/prkagrawal/cosmos-bk2/services"
+	"github.com/prkagrawal/cosmos-bk2/storage"
 	"gorm.io/gorm"
 )
 
@@ -12,8 +19,26 @@ import (
 
 type Resolver struct {
 	// todos []*model.Todo
-	DB          *gorm.DB
-	AuthService *auth.AuthService
+	DB               *gorm.DB
+	AuthService      *auth.AuthService
+	PersistedQueries *persisted.Store
+	Storage          storage.Store
+	Moderation       lifecycle.ModerationPolicy
+	StartedAt        time.Time
+	Messages         *messaging.Broker
+	Activity         *activity.Broker
+	Repos            services.Repositories
+
+	UserService         *services.UserService
+	NonprofitService    *services.NonprofitService
+	ProjectService      *services.ProjectService
+	ApplicationService  *services.ApplicationService
+	EngagementService   *services.EngagementService
+	ShiftService        *services.ShiftService
+	ReviewService       *services.ReviewService
+	MessageService      *services.MessageService
+	NotificationService *services.NotificationService
+	WebhookService      *services.WebhookService
 }
 
 // type Resolver struct {
@@ -26,9 +51,30 @@ type Resolver struct {
 // 	return &applicationResolver{r}
 // }
 
-func NewResolver(db *gorm.DB) *Resolver {
+func NewResolver(db *gorm.DB, store storage.Store, authService *auth.AuthService) *Resolver {
+	repos := services.NewRepositories(db)
+	work := services.NewUnitOfWork(db)
+	moderation := lifecycle.ModerationPolicyFromEnv()
 	return &Resolver{
-		DB:          db,
-		AuthService: auth.NewAuthService(database.DB),
+		DB:               db,
+		AuthService:      authService,
+		PersistedQueries: persisted.NewStore(db),
+		Storage:          store,
+		Moderation:       moderation,
+		StartedAt:        time.Now(),
+		Messages:         messaging.NewBroker(),
+		Activity:         activity.NewBroker(),
+		Repos:            repos,
+
+		UserService:         services.NewUserService(work, repos, store),
+		NonprofitService:    services.NewNonprofitService(work, repos, store),
+		ProjectService:      services.NewProjectService(work, repos, moderation),
+		ApplicationService:  services.NewApplicationService(work, repos),
+		EngagementService:   services.NewEngagementService(work, repos),
+		ShiftService:        services.NewShiftService(work, repos),
+		ReviewService:       services.NewReviewService(work, repos),
+		MessageService:      services.NewMessageService(work, repos),
+		NotificationService: services.NewNotificationService(work),
+		WebhookService:      services.NewWebhookService(work, repos),
 	}
 }
@@ -8,14 +8,68 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/prkagrawal/cosmos-bk2/analytics"
+	"github.com/prkagrawal/cosmos-bk2/audit"
 	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/certificates"
 	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/media"
+	"github.com/prkagrawal/cosmos-bk2/messaging"
+	"github.com/prkagrawal/cosmos-bk2/notifications"
+	"github.com/prkagrawal/cosmos-bk2/persisted"
+	"github.com/prkagrawal/cosmos-bk2/profiles"
+	"github.com/prkagrawal/cosmos-bk2/reviews"
+	"github.com/prkagrawal/cosmos-bk2/services"
+	"github.com/prkagrawal/cosmos-bk2/storage"
 	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
 	"gorm.io/gorm"
 )
 
+// From is the resolver for the from field.
+func (r *adminDashboardResolver) From(ctx context.Context, obj *model.AdminDashboard) (string, error) {
+	return utils.FormatTime(obj.From), nil
+}
+
+// To is the resolver for the to field.
+func (r *adminDashboardResolver) To(ctx context.Context, obj *model.AdminDashboard) (string, error) {
+	return utils.FormatTime(obj.To), nil
+}
+
+// SignupsPerDay is the resolver for the signupsPerDay field.
+func (r *adminDashboardResolver) SignupsPerDay(ctx context.Context, obj *model.AdminDashboard) ([]*model.SignupCount, error) {
+	return analytics.SignupsPerDay(r.DB, obj.From, obj.To)
+}
+
+// VerificationBacklog is the resolver for the verificationBacklog field.
+func (r *adminDashboardResolver) VerificationBacklog(ctx context.Context, obj *model.AdminDashboard) (*model.VerificationBacklog, error) {
+	return analytics.VerificationBacklog(r.DB)
+}
+
+// ProjectFunnel is the resolver for the projectFunnel field.
+func (r *adminDashboardResolver) ProjectFunnel(ctx context.Context, obj *model.AdminDashboard) ([]*model.ProjectStatusCount, error) {
+	return analytics.ProjectFunnel(r.DB)
+}
+
+// HoursLogged is the resolver for the hoursLogged field.
+func (r *adminDashboardResolver) HoursLogged(ctx context.Context, obj *model.AdminDashboard) (*model.HoursTotals, error) {
+	return analytics.HoursTotals(r.DB, obj.From, obj.To)
+}
+
+// TopCauses is the resolver for the topCauses field.
+func (r *adminDashboardResolver) TopCauses(ctx context.Context, obj *model.AdminDashboard, limit *int32) ([]*model.CauseUsage, error) {
+	return analytics.TopCauses(r.DB, clampLimit(limit, 10))
+}
+
+// TopSkills is the resolver for the topSkills field.
+func (r *adminDashboardResolver) TopSkills(ctx context.Context, obj *model.AdminDashboard, limit *int32) ([]*model.SkillUsage, error) {
+	return analytics.TopSkills(r.DB, clampLimit(limit, 10))
+}
+
 // ID is the resolver for the id field.
 func (r *applicationResolver) ID(ctx context.Context, obj *model.Application) (string, error) {
 	// obj is the *model.Application fetched by the parent resolver
@@ -32,6 +86,102 @@ func (r *applicationResolver) DecidedAt(ctx context.Context, obj *model.Applicat
 	return utils.FormatNullableTime(obj.DecidedAt), nil
 }
 
+// WaitlistPosition is the resolver for the waitlistPosition field.
+func (r *applicationResolver) WaitlistPosition(ctx context.Context, obj *model.Application) (*int32, error) {
+	if obj.Status != model.Waitlisted || obj.WaitlistedAt == nil {
+		return nil, nil
+	}
+	// Position is one more than the number of applicants waitlisted ahead of this one
+	var ahead int64
+	if err := r.DB.Model(&model.Application{}).
+		Where("project_id = ? AND status = ?", obj.ProjectID, model.Waitlisted).
+		Where("waitlisted_at < ? OR (waitlisted_at = ? AND id < ?)", obj.WaitlistedAt, obj.WaitlistedAt, obj.ID).
+		Count(&ahead).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute waitlist position: %w", err)
+	}
+	position := int32(ahead) + 1
+	return &position, nil
+}
+
+// Slot is the resolver for the slot field.
+func (r *applicationResolver) Slot(ctx context.Context, obj *model.Application) (*model.ProjectRoleSlot, error) {
+	if obj.SlotID == nil {
+		return nil, nil
+	}
+	var slot model.ProjectRoleSlot
+	if err := r.DB.First(&slot, *obj.SlotID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // slot was removed from the project since
+		}
+		return nil, fmt.Errorf("failed to fetch role slot: %w", err)
+	}
+	return &slot, nil
+}
+
+// Answers is the resolver for the answers field.
+func (r *applicationResolver) Answers(ctx context.Context, obj *model.Application) ([]*model.ApplicationAnswer, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return []*model.ApplicationAnswer{}, nil
+	}
+	return r.ApplicationService.Answers(currentUser, obj.ID)
+}
+
+// Question is the resolver for the question field.
+func (r *applicationAnswerResolver) Question(ctx context.Context, obj *model.ApplicationAnswer) (*model.ApplicationQuestion, error) {
+	var question model.ApplicationQuestion
+	// Unscoped: the question may have been removed from the project after the answer was given
+	if err := r.DB.Unscoped().First(&question, obj.QuestionID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application question: %w", err)
+	}
+	return &question, nil
+}
+
+// Values is the resolver for the values field.
+func (r *applicationAnswerResolver) Values(ctx context.Context, obj *model.ApplicationAnswer) ([]string, error) {
+	return []string(obj.Values), nil
+}
+
+// ID is the resolver for the id field.
+func (r *applicationQuestionResolver) ID(ctx context.Context, obj *model.ApplicationQuestion) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Options is the resolver for the options field.
+func (r *applicationQuestionResolver) Options(ctx context.Context, obj *model.ApplicationQuestion) ([]string, error) {
+	if obj.Options == nil {
+		return []string{}, nil
+	}
+	return []string(obj.Options), nil
+}
+
+// ID is the resolver for the id field.
+func (r *auditEventResolver) ID(ctx context.Context, obj *model.AuditEvent) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// NonprofitID is the resolver for the nonprofitId field.
+func (r *auditEventResolver) NonprofitID(ctx context.Context, obj *model.AuditEvent) (*string, error) {
+	if obj.NonprofitID == nil {
+		return nil, nil
+	}
+	id := fmt.Sprintf("%d", *obj.NonprofitID)
+	return &id, nil
+}
+
+// Changes is the resolver for the changes field.
+func (r *auditEventResolver) Changes(ctx context.Context, obj *model.AuditEvent) ([]*model.AuditChange, error) {
+	if obj.Changes == nil {
+		return []*model.AuditChange{}, nil
+	}
+	return []*model.AuditChange(obj.Changes), nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *auditEventResolver) CreatedAt(ctx context.Context, obj *model.AuditEvent) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
 // HoursPerWeek converts int to int32 for GraphQL Int.
 func (r *availabilityResolver) HoursPerWeek(ctx context.Context, obj *model.Availability) (int32, error) {
 	// Availability is embedded, obj is already populated by parent resolver
@@ -50,6 +200,124 @@ func (r *causeResolver) ID(ctx context.Context, obj *model.Cause) (string, error
 	return fmt.Sprintf("%d", obj.ID), nil // Convert uint to string
 }
 
+// ID is the resolver for the id field.
+func (r *certificateResolver) ID(ctx context.Context, obj *model.Certificate) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// VolunteerName is the resolver for the volunteerName field.
+func (r *certificateResolver) VolunteerName(ctx context.Context, obj *model.Certificate) (string, error) {
+	return obj.Volunteer.FirstName + " " + obj.Volunteer.LastName, nil
+}
+
+// From is the resolver for the from field.
+func (r *certificateResolver) From(ctx context.Context, obj *model.Certificate) (*string, error) {
+	return utils.FormatNullableTime(obj.From), nil
+}
+
+// To is the resolver for the to field.
+func (r *certificateResolver) To(ctx context.Context, obj *model.Certificate) (*string, error) {
+	return utils.FormatNullableTime(obj.To), nil
+}
+
+// IssuedAt is the resolver for the issuedAt field.
+func (r *certificateResolver) IssuedAt(ctx context.Context, obj *model.Certificate) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// PDFURL is the resolver for the pdfUrl field.
+func (r *certificateResolver) PDFURL(ctx context.Context, obj *model.Certificate) (string, error) {
+	return fmt.Sprintf("/certificates/%d.pdf", obj.ID), nil
+}
+
+// ID is the resolver for the id field.
+func (r *conversationResolver) ID(ctx context.Context, obj *model.Conversation) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Project is the resolver for the project field.
+func (r *conversationResolver) Project(ctx context.Context, obj *model.Conversation) (*model.Project, error) {
+	var project model.Project
+	if err := r.DB.First(&project, obj.ProjectID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	return &project, nil
+}
+
+// Nonprofit is the resolver for the nonprofit field.
+func (r *conversationResolver) Nonprofit(ctx context.Context, obj *model.Conversation) (*model.Nonprofit, error) {
+	var nonprofit model.Nonprofit
+	if err := r.DB.First(&nonprofit, obj.NonprofitID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
+	}
+	return &nonprofit, nil
+}
+
+// Volunteer is the resolver for the volunteer field.
+func (r *conversationResolver) Volunteer(ctx context.Context, obj *model.Conversation) (*model.User, error) {
+	var volunteer model.User
+	if err := r.DB.First(&volunteer, obj.VolunteerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteer: %w", err)
+	}
+	return &volunteer, nil
+}
+
+// Application is the resolver for the application field.
+func (r *conversationResolver) Application(ctx context.Context, obj *model.Conversation) (*model.Application, error) {
+	if obj.ApplicationID == nil {
+		return nil, nil
+	}
+	var application model.Application
+	if err := r.DB.First(&application, *obj.ApplicationID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+	return &application, nil
+}
+
+// Engagement is the resolver for the engagement field.
+func (r *conversationResolver) Engagement(ctx context.Context, obj *model.Conversation) (*model.Engagement, error) {
+	if obj.EngagementID == nil {
+		return nil, nil
+	}
+	var engagement model.Engagement
+	if err := r.DB.First(&engagement, *obj.EngagementID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch engagement: %w", err)
+	}
+	return &engagement, nil
+}
+
+// LastMessage is the resolver for the lastMessage field.
+func (r *conversationResolver) LastMessage(ctx context.Context, obj *model.Conversation) (*model.Message, error) {
+	var message model.Message
+	if err := r.DB.Where("conversation_id = ?", obj.ID).Order("id DESC").First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch last message: %w", err)
+	}
+	return &message, nil
+}
+
+// LastMessageAt is the resolver for the lastMessageAt field.
+func (r *conversationResolver) LastMessageAt(ctx context.Context, obj *model.Conversation) (*string, error) {
+	return utils.FormatNullableTime(obj.LastMessageAt), nil
+}
+
+// UnreadCount is the resolver for the unreadCount field.
+func (r *conversationResolver) UnreadCount(ctx context.Context, obj *model.Conversation) (int32, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return 0, errors.New("unauthenticated: " + err.Error())
+	}
+	count, err := messaging.UnreadCount(r.DB, obj.ID, currentUser.ID)
+	return int32(count), err
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *conversationResolver) CreatedAt(ctx context.Context, obj *model.Conversation) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
 // ID is the resolver for the id field.
 func (r *engagementResolver) ID(ctx context.Context, obj *model.Engagement) (string, error) {
 	// obj is the *model.Engagement fetched by the parent resolver
@@ -71,6 +339,17 @@ func (r *engagementResolver) FeedbackSubmittedAt(ctx context.Context, obj *model
 	return utils.FormatNullableTime(obj.FeedbackSubmittedAt), nil
 }
 
+// Reviews is the resolver for the reviews field.
+func (r *engagementResolver) Reviews(ctx context.Context, obj *model.Engagement) ([]*model.Review, error) {
+	var reviewList []*model.Review
+	if err := r.DB.Where("engagement_id = ? AND hidden = ?", obj.ID, false).
+		Order("created_at ASC").
+		Find(&reviewList).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return reviewList, nil
+}
+
 // ID is the resolver for the id field.
 func (r *hoursLoggedResolver) ID(ctx context.Context, obj *model.HoursLogged) (string, error) {
 	// obj is the *model.User fetched by the parent resolver
@@ -87,6 +366,34 @@ func (r *hoursLoggedResolver) ApprovedAt(ctx context.Context, obj *model.HoursLo
 	return utils.FormatNullableTime(obj.ApprovedAt), nil
 }
 
+// ID is the resolver for the id field.
+func (r *messageResolver) ID(ctx context.Context, obj *model.Message) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Conversation is the resolver for the conversation field.
+func (r *messageResolver) Conversation(ctx context.Context, obj *model.Message) (*model.Conversation, error) {
+	var conversation model.Conversation
+	if err := r.DB.First(&conversation, obj.ConversationID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch conversation: %w", err)
+	}
+	return &conversation, nil
+}
+
+// Sender is the resolver for the sender field.
+func (r *messageResolver) Sender(ctx context.Context, obj *model.Message) (*model.User, error) {
+	var sender model.User
+	if err := r.DB.First(&sender, obj.SenderID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sender: %w", err)
+	}
+	return &sender, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *messageResolver) CreatedAt(ctx context.Context, obj *model.Message) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
 // Login is the resolver for the login field.
 func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
 	user, err := r.AuthService.Authenticate(ctx, email, password)
@@ -115,6 +422,10 @@ func (r *mutationResolver) Login(ctx context.Context, email string, password str
 
 // Signup is the resolver for the signup field.
 func (r *mutationResolver) Signup(ctx context.Context, input model.SignupInput) (*model.AuthPayload, error) {
+	if err := validation.SignupInput(input); err != nil {
+		return nil, err
+	}
+
 	// Access the AuthService from the receiver 'r'
 	newUser, err := r.AuthService.CreateUser(ctx, input)
 	if err != nil {
@@ -180,65 +491,7 @@ func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.Profil
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-
-	// Apply updates from input
-	if input.FirstName != nil {
-		currentUser.FirstName = *input.FirstName
-	}
-	if input.LastName != nil {
-		currentUser.LastName = *input.LastName
-	}
-	if input.Bio != nil {
-		currentUser.Bio = *input.Bio
-	}
-	if input.LinkedIn != nil {
-		currentUser.LinkedInURL = *input.LinkedIn
-	}
-	if input.Portfolio != nil {
-		currentUser.PortfolioURL = *input.Portfolio
-	}
-
-	// Handle avatar upload
-	if input.Avatar != nil {
-		// Save the uploaded file and get its URL/path
-		// The "avatars" subdirectory is an example.
-		avatar := &model.Upload{
-			Filename: input.Avatar.Filename,
-			File:     input.Avatar.File,
-		}
-		avatarURL, err := utils.SaveUpload(avatar, "avatars")
-		if err != nil {
-			// Log error: fmt.Printf("Failed to upload avatar: %v\n", err)
-			return nil, fmt.Errorf("failed to save avatar: %w", err)
-		}
-		currentUser.AvatarURL = avatarURL
-	}
-
-	// Handle causes (assuming input.Causes are names of causes)
-	if input.Causes != nil {
-		var causesToSet []*model.Cause
-		for _, causeName := range input.Causes {
-			var cause model.Cause
-			if err := r.DB.Where("name = ?", causeName).First(&cause).Error; err == nil {
-				causesToSet = append(causesToSet, &cause)
-			} else {
-				// Optionally create new causes or return an error if a cause doesn't exist
-				// For now, we'll ignore causes not found or you can return an error:
-				// return nil, fmt.Errorf("cause '%s' not found", causeName)
-			}
-		}
-		// GORM's Association `Replace` is good for many2many updates
-		if err := r.DB.Model(currentUser).Association("Causes").Replace(causesToSet); err != nil {
-			return nil, fmt.Errorf("failed to update user causes: %w", err)
-		}
-	}
-
-	if err := r.DB.Save(currentUser).Error; err != nil {
-		// Log error
-		return nil, fmt.Errorf("failed to update profile: %w", err)
-	}
-
-	return currentUser, nil
+	return r.UserService.UpdateProfile(ctx, currentUser, input)
 }
 
 // AddSkills is the resolver for the addSkills field.
@@ -247,27 +500,7 @@ func (r *mutationResolver) AddSkills(ctx context.Context, skills []string) (*mod
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-
-	var skillsToAdd []*model.Skill
-	for _, skillName := range skills {
-		var skill model.Skill
-		// Find or create skill
-		if err := r.DB.Where("name = ?", skillName).FirstOrCreate(&skill, model.Skill{Name: skillName, Category: "Default" /* Or derive category */}).Error; err != nil {
-			return nil, fmt.Errorf("failed to find or create skill '%s': %w", skillName, err)
-		}
-		skillsToAdd = append(skillsToAdd, &skill)
-	}
-
-	// Append new skills to user's existing skills
-	if err := r.DB.Model(currentUser).Association("Skills").Append(skillsToAdd); err != nil {
-		return nil, fmt.Errorf("failed to add skills: %w", err)
-	}
-
-	// Reload user to get updated skills list in the response (GORM doesn't auto-populate after Append sometimes)
-	// Or manually append to currentUser.Skills if you don't want another DB hit.
-	// For consistency, let's refetch or ensure the association is loaded.
-	r.DB.Preload("Skills").Preload("Causes").First(currentUser, currentUser.ID)
-	return currentUser, nil
+	return r.UserService.AddSkills(ctx, currentUser, skills)
 }
 
 // RemoveSkill is the resolver for the removeSkill field.
@@ -276,20 +509,7 @@ func (r *mutationResolver) RemoveSkill(ctx context.Context, skill string) (*mode
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-
-	var skillToRemove model.Skill
-	if err := r.DB.Where("name = ?", skill).First(&skillToRemove).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("skill '%s' not found", skill)
-		}
-		return nil, fmt.Errorf("failed to find skill '%s': %w", skill, err)
-	}
-
-	if err := r.DB.Model(currentUser).Association("Skills").Delete(&skillToRemove); err != nil {
-		return nil, fmt.Errorf("failed to remove skill: %w", err)
-	}
-	r.DB.Preload("Skills").Preload("Causes").First(currentUser, currentUser.ID)
-	return currentUser, nil
+	return r.UserService.RemoveSkill(ctx, currentUser, skill)
 }
 
 // SetAvailability is the resolver for the setAvailability field.
@@ -298,16 +518,16 @@ func (r *mutationResolver) SetAvailability(ctx context.Context, input model.Avai
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
+	return r.UserService.SetAvailability(ctx, currentUser, input)
+}
 
-	currentUser.Availability.HoursPerWeek = int(input.HoursPerWeek)              // Cast int32 to int
-	currentUser.Availability.DaysAvailable = model.Weekdays(input.DaysAvailable) // Cast []model.Weekday to model.Weekdays
-	currentUser.Availability.Timezone = input.Timezone
-
-	if err := r.DB.Save(currentUser).Error; err != nil {
-		return nil, fmt.Errorf("failed to set availability: %w", err)
+// SetProfileVisibility is the resolver for the setProfileVisibility field.
+func (r *mutationResolver) SetProfileVisibility(ctx context.Context, visibility model.ProfileVisibility) (*model.User, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-	// No need to reload, Availability is embedded
-	return currentUser, nil
+	return r.UserService.SetProfileVisibility(ctx, currentUser, visibility)
 }
 
 // CreateNonprofit is the resolver for the createNonprofit field.
@@ -316,87 +536,12 @@ func (r *mutationResolver) CreateNonprofit(ctx context.Context, input model.Nonp
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-	// Authorization: Only certain roles (e.g., platform admin or verified user) might create nonprofits.
-	// For example:
-	// if currentUser.Role != model.PlatformAdmin { // Or some other role logic
-	//  return nil, errors.New("unauthorized: insufficient permissions to create nonprofit")
-	// }
-
-	// Handle logo upload
-	logoURL := ""
-	if input.Logo != nil {
-		var uploadErr error
-		// Convert *graphql.Upload to *model.Upload
-		convertedLogo := &model.Upload{
-			Filename: input.Logo.Filename,
-			File:     input.Logo.File,
-		}
-		logoURL, uploadErr = utils.SaveUpload(convertedLogo, "nonprofit_logos")
-		if uploadErr != nil {
-			return nil, fmt.Errorf("failed to save nonprofit logo: %w", uploadErr)
-		}
-	}
-
-	newNonprofit := model.Nonprofit{
-		Name:        input.Name,
-		Description: input.Description,
-		Website:     input.Website,
-		EIN:         input.Ein,
-		Size:        input.Size,
-		LogoURL:     logoURL,
-		Verified:    false, // Or based on policy, e.g., true if created by admin
-		Location: model.Location{
-			City:    input.Location.City,
-			State:   input.Location.State,
-			Country: input.Location.Country,
-			Remote:  input.Location.Remote,
-		},
-		// Members: This is tricky on creation. Typically, the creator becomes a member.
-		// You might want to add currentUser as a NonprofitAdmin member.
-	}
-
-	// Handle causes
-	var causesToSet []*model.Cause
-	for _, causeName := range input.Causes {
-		var cause model.Cause
-		if err := r.DB.Where("name = ?", causeName).FirstOrCreate(&cause, model.Cause{Name: causeName, Description: "" /* Or provide default */}).Error; err != nil {
-			return nil, fmt.Errorf("failed to find or create cause '%s': %w", causeName, err)
-		}
-		causesToSet = append(causesToSet, &cause)
-	}
-
-	// GORM transaction for atomicity
-	err = r.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(&newNonprofit).Error; err != nil {
-			return fmt.Errorf("failed to create nonprofit: %w", err)
-		}
-		if len(causesToSet) > 0 {
-			if err := tx.Model(&newNonprofit).Association("Causes").Replace(causesToSet); err != nil {
-				return fmt.Errorf("failed to set nonprofit causes: %w", err)
-			}
-		}
-		// Add current user as a member (e.g., NonprofitAdmin)
-		// This assumes NonprofitMembers is a many2many relationship setup in GORM
-		// You might need a join table model if there are extra fields on the membership
-		if err := tx.Model(&newNonprofit).Association("Members").Append(currentUser); err != nil {
-			return fmt.Errorf("failed to add creator as nonprofit member: %w", err)
-		}
-		// TODO: Potentially assign a role to this member in the nonprofit_members join table if it exists
-		return nil
-	})
-
-	if err != nil {
-		return nil, err // Error already formatted
-	}
-
-	// Preload associations for the response
-	r.DB.Preload("Causes").Preload("Members").First(&newNonprofit, newNonprofit.ID)
-	return &newNonprofit, nil
+	return r.NonprofitService.Create(ctx, currentUser, input)
 }
 
 // UpdateNonprofit is the resolver for the updateNonprofit field.
 func (r *mutationResolver) UpdateNonprofit(ctx context.Context, id string, input model.NonprofitInput) (*model.Nonprofit, error) {
-	_, err := auth.GetUserFromContext(ctx) // Ensure authenticated
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
@@ -405,63 +550,7 @@ func (r *mutationResolver) UpdateNonprofit(ctx context.Context, id string, input
 	if err != nil {
 		return nil, err
 	}
-
-	var nonprofitToUpdate model.Nonprofit
-	if err := r.DB.Preload("Causes").First(&nonprofitToUpdate, nonprofitID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("nonprofit with ID %s not found", id)
-		}
-		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
-	}
-
-	// Authorization: Check if currentUser is an admin of this nonprofit or platform admin
-	// Example: if !isNonprofitAdmin(currentUser, nonprofitToUpdate.ID) && currentUser.Role != model.PlatformAdmin {
-	//  return nil, errors.New("unauthorized to update this nonprofit")
-	// }
-
-	// Apply updates
-	nonprofitToUpdate.Name = input.Name
-	nonprofitToUpdate.Description = input.Description
-	nonprofitToUpdate.Website = input.Website
-	nonprofitToUpdate.EIN = input.Ein // Be careful with EIN updates if it's sensitive
-	nonprofitToUpdate.Size = input.Size
-	nonprofitToUpdate.Location = model.Location{
-		City: input.Location.City, State: input.Location.State, Country: input.Location.Country, Remote: input.Location.Remote,
-	}
-
-	if input.Logo != nil {
-		convertedLogo := &model.Upload{
-			Filename: input.Logo.Filename,
-			File:     input.Logo.File,
-		}
-		logoURL, uploadErr := utils.SaveUpload(convertedLogo, "nonprofit_logos")
-		if uploadErr != nil {
-			return nil, fmt.Errorf("failed to save nonprofit logo: %w", uploadErr)
-		}
-		nonprofitToUpdate.LogoURL = logoURL
-	}
-
-	// Handle causes update
-	if input.Causes != nil {
-		var causesToSet []*model.Cause
-		for _, causeName := range input.Causes {
-			var cause model.Cause
-			if err := r.DB.Where("name = ?", causeName).FirstOrCreate(&cause, model.Cause{Name: causeName}).Error; err != nil {
-				return nil, fmt.Errorf("failed to process cause '%s': %w", causeName, err)
-			}
-			causesToSet = append(causesToSet, &cause)
-		}
-		if err := r.DB.Model(&nonprofitToUpdate).Association("Causes").Replace(causesToSet); err != nil {
-			return nil, fmt.Errorf("failed to update nonprofit causes: %w", err)
-		}
-	}
-
-	if err := r.DB.Save(&nonprofitToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to update nonprofit: %w", err)
-	}
-
-	r.DB.Preload("Causes").Preload("Members").First(&nonprofitToUpdate, nonprofitToUpdate.ID) // Re-fetch with associations
-	return &nonprofitToUpdate, nil
+	return r.NonprofitService.Update(ctx, currentUser, nonprofitID, input)
 }
 
 // VerifyNonprofit is the resolver for the verifyNonprofit field.
@@ -471,31 +560,11 @@ func (r *mutationResolver) VerifyNonprofit(ctx context.Context, id string) (*mod
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// Authorization: Only PlatformAdmins can verify nonprofits
-	if currentUser.Role != model.PlatformAdmin {
-		return nil, errors.New("unauthorized: only platform admins can verify nonprofits")
-	}
-
 	nonprofitID, err := utils.IdToUint(id)
 	if err != nil {
 		return nil, err
 	}
-
-	var nonprofitToVerify model.Nonprofit
-	if err := r.DB.First(&nonprofitToVerify, nonprofitID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("nonprofit with ID %s not found", id)
-		}
-		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
-	}
-
-	nonprofitToVerify.Verified = true
-	if err := r.DB.Save(&nonprofitToVerify).Error; err != nil {
-		return nil, fmt.Errorf("failed to verify nonprofit: %w", err)
-	}
-
-	r.DB.Preload("Causes").Preload("Members").First(&nonprofitToVerify, nonprofitToVerify.ID)
-	return &nonprofitToVerify, nil
+	return r.NonprofitService.Verify(ctx, currentUser, nonprofitID)
 }
 
 // CreateProject is the resolver for the createProject field.
@@ -504,91 +573,26 @@ func (r *mutationResolver) CreateProject(ctx context.Context, input model.Projec
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
+	return r.ProjectService.Create(ctx, currentUser, input)
+}
 
-	nonprofitID, err := utils.IdToUint(input.NonprofitID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid nonprofit ID: %w", err)
-	}
-
-	// Authorization: Check if currentUser is an admin of the nonprofitID
-	// or a platform admin.
-	// This requires a helper function like `isNonprofitAdmin(user, nonprofitID)`
-	// For now, basic check:
-	var nonprofit model.Nonprofit
-	if err := r.DB.Select("id").First(&nonprofit, nonprofitID).Error; err != nil {
-		return nil, fmt.Errorf("nonprofit with ID %s not found", input.NonprofitID)
-	}
-	// Add more robust check if user is a member of this nonprofit with admin role.
-	isMember := false
-	if err := r.DB.Model(&nonprofit).Where("user_id = ?", currentUser.ID).Association("Members").Find(currentUser); err == nil { // This check needs refinement
-		// A proper check would involve querying the nonprofit_members join table
-		// and checking the role if roles are stored per membership.
-		// For simplicity, let's assume if they are in Members, they can create (placeholder logic)
-		// A better check:
-		var count int64
-		r.DB.Table("nonprofit_members").Where("nonprofit_id = ? AND user_id = ?", nonprofitID, currentUser.ID).Count(&count)
-		if count > 0 {
-			isMember = true // Further check role if applicable
-		}
-	}
-
-	if !isMember && currentUser.Role != model.PlatformAdmin && currentUser.Role != model.NonprofitAdmin { // Refine role check
-		return nil, errors.New("unauthorized: you must be an admin of the nonprofit or a platform admin to create projects")
-	}
-
-	startDate, err := utils.ParseNullableDateTimeString(input.StartDate)
-	if err != nil {
-		return nil, fmt.Errorf("invalid start date: %w", err)
-	}
-	endDate, err := utils.ParseNullableDateTimeString(input.EndDate)
+// UpdateProject is the resolver for the updateProject field.
+func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input model.ProjectInput) (*model.Project, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date: %w", err)
-	}
-
-	newProject := model.Project{
-		NonprofitID:    nonprofitID,
-		Title:          input.Title,
-		Description:    input.Description,
-		TimeCommitment: input.TimeCommitment,
-		Urgency:        input.Urgency,
-		Status:         model.Draft, // Default status
-		StartDate:      startDate,
-		EndDate:        endDate,
-	}
-
-	// Handle skills needed
-	var skillsToSet []*model.Skill
-	for _, skillName := range input.SkillsNeeded {
-		var skill model.Skill
-		if err := r.DB.Where("name = ?", skillName).FirstOrCreate(&skill, model.Skill{Name: skillName, Category: "Default"}).Error; err != nil {
-			return nil, fmt.Errorf("failed to find or create skill '%s': %w", skillName, err)
-		}
-		skillsToSet = append(skillsToSet, &skill)
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	err = r.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(&newProject).Error; err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
-		}
-		if len(skillsToSet) > 0 {
-			if err := tx.Model(&newProject).Association("SkillsNeeded").Replace(skillsToSet); err != nil {
-				return fmt.Errorf("failed to set project skills: %w", err)
-			}
-		}
-		return nil
-	})
-
+	projectID, err := utils.IdToUint(id)
 	if err != nil {
 		return nil, err
 	}
-
-	r.DB.Preload("SkillsNeeded").Preload("Nonprofit").First(&newProject, newProject.ID)
-	return &newProject, nil
+	return r.ProjectService.Update(ctx, currentUser, projectID, input)
 }
 
-// UpdateProject is the resolver for the updateProject field.
-func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input model.ProjectInput) (*model.Project, error) {
-	_, err := auth.GetUserFromContext(ctx)
+// ChangeProjectStatus is the resolver for the changeProjectStatus field.
+func (r *mutationResolver) ChangeProjectStatus(ctx context.Context, id string, status model.ProjectStatus, reason *string) (*model.Project, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
@@ -597,371 +601,506 @@ func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input m
 	if err != nil {
 		return nil, err
 	}
+	return r.ProjectService.ChangeStatus(ctx, currentUser, projectID, status, reason)
+}
 
-	var projectToUpdate model.Project
-	if err := r.DB.Preload("SkillsNeeded").First(&projectToUpdate, projectID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("project with ID %s not found", id)
+// ApplyToProject is the resolver for the applyToProject field.
+func (r *mutationResolver) ApplyToProject(ctx context.Context, projectID string, message *string, slotID *string, answers []*model.AnswerInput) (*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	projectIDUsable, err := utils.IdToUint(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var slotIDUsable *uint
+	if slotID != nil {
+		id, err := utils.IdToUint(*slotID)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to fetch project: %w", err)
+		slotIDUsable = &id
 	}
+	return r.ApplicationService.Apply(ctx, currentUser, projectIDUsable, message, slotIDUsable, answers)
+}
 
-	// Authorization: Check if currentUser is an admin of the project's nonprofit or platform admin.
-	// Similar logic to CreateProject authorization
-	// Example: if !isProjectAdmin(currentUser, projectToUpdate) ...
+// AcceptApplication is the resolver for the acceptApplication field.
+func (r *mutationResolver) AcceptApplication(ctx context.Context, applicationID string, autoStartEngagement *bool) (*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	_, err = utils.IdToUint(input.NonprofitID) // Input has NonprofitID, should this be updatable?
+	applicationIDUsable, err := utils.IdToUint(applicationID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid nonprofit ID in input: %w", err)
+		return nil, err
 	}
-	// Typically, you might not allow changing the nonprofit of a project.
-	// If allowed, ensure auth for the new nonprofit too.
-	// For this example, let's assume projectToUpdate.NonprofitID is the source of truth for auth.
-	// if projectToUpdate.NonprofitID != nonprofitID {
-	//  return nil, errors.New("changing the parent nonprofit of a project is not allowed or requires special permissions")
-	// }
+	return r.ApplicationService.Accept(ctx, currentUser, applicationIDUsable, autoStartEngagement != nil && *autoStartEngagement)
+}
 
-	// Apply updates
-	projectToUpdate.Title = input.Title
-	projectToUpdate.Description = input.Description
-	projectToUpdate.TimeCommitment = input.TimeCommitment
-	projectToUpdate.Urgency = input.Urgency
-	// projectToUpdate.NonprofitID = nonprofitID; // If you allow changing this
+// RejectApplication is the resolver for the rejectApplication field.
+func (r *mutationResolver) RejectApplication(ctx context.Context, applicationID string) (*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	startDate, err := utils.ParseNullableDateTimeString(input.StartDate)
+	applicationIDUsable, err := utils.IdToUint(applicationID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date: %w", err)
+		return nil, err
 	}
-	endDate, err := utils.ParseNullableDateTimeString(input.EndDate)
+	return r.ApplicationService.Reject(ctx, currentUser, applicationIDUsable)
+}
+
+// WithdrawApplication is the resolver for the withdrawApplication field.
+func (r *mutationResolver) WithdrawApplication(ctx context.Context, applicationID string) (*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date: %w", err)
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-	projectToUpdate.StartDate = startDate
-	projectToUpdate.EndDate = endDate
 
-	// Handle skills needed update
-	if input.SkillsNeeded != nil {
-		var skillsToSet []*model.Skill
-		for _, skillName := range input.SkillsNeeded {
-			var skill model.Skill
-			if err := r.DB.Where("name = ?", skillName).FirstOrCreate(&skill, model.Skill{Name: skillName}).Error; err != nil {
-				return nil, fmt.Errorf("failed to process skill '%s': %w", skillName, err)
-			}
-			skillsToSet = append(skillsToSet, &skill)
-		}
-		if err := r.DB.Model(&projectToUpdate).Association("SkillsNeeded").Replace(skillsToSet); err != nil {
-			return nil, fmt.Errorf("failed to update project skills: %w", err)
-		}
+	applicationIDUsable, err := utils.IdToUint(applicationID)
+	if err != nil {
+		return nil, err
 	}
+	return r.ApplicationService.Withdraw(ctx, currentUser, applicationIDUsable)
+}
 
-	if err := r.DB.Save(&projectToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to update project: %w", err)
+// ReopenApplication is the resolver for the reopenApplication field.
+func (r *mutationResolver) ReopenApplication(ctx context.Context, applicationID string) (*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	r.DB.Preload("SkillsNeeded").Preload("Nonprofit").First(&projectToUpdate, projectToUpdate.ID)
-	return &projectToUpdate, nil
+	applicationIDUsable, err := utils.IdToUint(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Reopen(ctx, currentUser, applicationIDUsable)
 }
 
-// ChangeProjectStatus is the resolver for the changeProjectStatus field.
-func (r *mutationResolver) ChangeProjectStatus(ctx context.Context, id string, status model.ProjectStatus) (*model.Project, error) {
-	_, err := auth.GetUserFromContext(ctx) // Auth check
+// SubmitProjectForReview is the resolver for the submitProjectForReview field.
+func (r *mutationResolver) SubmitProjectForReview(ctx context.Context, id string) (*model.Project, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-	// TODO: Add authorization logic (e.g., only project admin or platform admin)
 
 	projectID, err := utils.IdToUint(id)
 	if err != nil {
 		return nil, err
 	}
+	return r.ProjectService.SubmitForReview(ctx, currentUser, projectID)
+}
 
-	var projectToUpdate model.Project
-	if err := r.DB.First(&projectToUpdate, projectID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("project with ID %s not found", id)
-		}
-		return nil, fmt.Errorf("failed to fetch project: %w", err)
+// ApproveProject is the resolver for the approveProject field.
+func (r *mutationResolver) ApproveProject(ctx context.Context, id string, comment *string) (*model.Project, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// TODO: Validate status transition (e.g., can't go from COMPLETED to DRAFT)
-	projectToUpdate.Status = status
-	if err := r.DB.Save(&projectToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to change project status: %w", err)
+	projectID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
 	}
-
-	r.DB.Preload("SkillsNeeded").Preload("Nonprofit").First(&projectToUpdate, projectToUpdate.ID)
-	return &projectToUpdate, nil
+	return r.ProjectService.Approve(ctx, currentUser, projectID, comment)
 }
 
-// ApplyToProject is the resolver for the applyToProject field.
-func (r *mutationResolver) ApplyToProject(ctx context.Context, projectID string, message *string) (*model.Application, error) {
+// RequestProjectChanges is the resolver for the requestProjectChanges field.
+func (r *mutationResolver) RequestProjectChanges(ctx context.Context, id string, comment string) (*model.Project, error) {
 	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
-	if currentUser.Role != model.Volunteer {
-		return nil, errors.New("only volunteers can apply to projects")
-	}
 
-	projectUintID, err := utils.IdToUint(projectID)
+	projectID, err := utils.IdToUint(id)
 	if err != nil {
 		return nil, err
 	}
+	return r.ProjectService.RequestChanges(ctx, currentUser, projectID, comment)
+}
 
-	// Check if project exists and is active
-	var project model.Project
-	if err := r.DB.Where("status = ?", model.Active).First(&project, projectID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("active project with ID %s not found or not accepting applications", projectID)
-		}
-		return nil, fmt.Errorf("failed to fetch project: %w", err)
+// StartVolunteering is the resolver for the startVolunteering field.
+func (r *mutationResolver) StartVolunteering(ctx context.Context, projectID string) (*model.Engagement, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// Check if user has already applied
-	var existingApplication model.Application
-	err = r.DB.Where("volunteer_id = ? AND project_id = ?", currentUser.ID, projectID).First(&existingApplication).Error
-	if err == nil { // Found an existing application
-		return nil, errors.New("you have already applied to this project")
+	projectIDUsable, err := utils.IdToUint(projectID)
+	if err != nil {
+		return nil, err
 	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) { // Some other DB error
-		return nil, fmt.Errorf("error checking existing application: %w", err)
+	return r.EngagementService.Start(ctx, currentUser, projectIDUsable)
+}
+
+// CompleteEngagement is the resolver for the completeEngagement field.
+func (r *mutationResolver) CompleteEngagement(ctx context.Context, engagementID string, feedback *string) (*model.Engagement, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	newApplication := model.Application{
-		VolunteerID: currentUser.ID,
-		ProjectID:   projectUintID,
-		Message:     message,
-		Status:      model.Pending,
-		AppliedAt:   time.Now(),
+	engagementIDUsable, err := utils.IdToUint(engagementID)
+	if err != nil {
+		return nil, err
 	}
+	return r.EngagementService.Complete(ctx, currentUser, engagementIDUsable, feedback)
+}
 
-	if err := r.DB.Create(&newApplication).Error; err != nil {
-		return nil, fmt.Errorf("failed to create application: %w", err)
+// CancelEngagement is the resolver for the cancelEngagement field.
+func (r *mutationResolver) CancelEngagement(ctx context.Context, engagementID string) (*model.Engagement, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	r.DB.Preload("Volunteer").Preload("Project").First(&newApplication, newApplication.ID)
-	return &newApplication, nil
+	engagementIDUsable, err := utils.IdToUint(engagementID)
+	if err != nil {
+		return nil, err
+	}
+	return r.EngagementService.Cancel(ctx, currentUser, engagementIDUsable)
 }
 
-// AcceptApplication is the resolver for the acceptApplication field.
-func (r *mutationResolver) AcceptApplication(ctx context.Context, applicationID string) (*model.Application, error) {
-	_, err := auth.GetUserFromContext(ctx)
+// CreateShift is the resolver for the createShift field.
+func (r *mutationResolver) CreateShift(ctx context.Context, projectID string, input model.ShiftInput) (*model.Shift, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	applicationIDStr, err := utils.IdToUint(applicationID)
+	projectIDUsable, err := utils.IdToUint(projectID)
 	if err != nil {
 		return nil, err
 	}
+	return r.ShiftService.Create(ctx, currentUser, projectIDUsable, input)
+}
 
-	var applicationToUpdate model.Application
-	if err := r.DB.Preload("Project").First(&applicationToUpdate, applicationIDStr).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("application with ID %s not found", applicationID)
-		}
-		return nil, fmt.Errorf("failed to fetch application: %w", err)
+// DeleteShift is the resolver for the deleteShift field.
+func (r *mutationResolver) DeleteShift(ctx context.Context, id string) (bool, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return false, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// Authorization: CurrentUser must be an admin of applicationToUpdate.Project.NonprofitID
-	// or platform admin. Add this check.
-	// Example: if !isNonprofitAdminOfProject(currentUser, applicationToUpdate.ProjectID) ...
-
-	if applicationToUpdate.Status != model.Pending {
-		return nil, fmt.Errorf("application is not pending (current status: %s)", applicationToUpdate.Status)
+	shiftID, err := utils.IdToUint(id)
+	if err != nil {
+		return false, err
 	}
+	if err := r.ShiftService.Delete(ctx, currentUser, shiftID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	applicationToUpdate.Status = model.Accepted
-	now := time.Now()
-	applicationToUpdate.DecidedAt = &now
+// SignUpForShift is the resolver for the signUpForShift field.
+func (r *mutationResolver) SignUpForShift(ctx context.Context, shiftID string) (*model.Shift, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	if err := r.DB.Save(&applicationToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to accept application: %w", err)
+	shiftIDUsable, err := utils.IdToUint(shiftID)
+	if err != nil {
+		return nil, err
 	}
+	return r.ShiftService.SignUp(ctx, currentUser, shiftIDUsable)
+}
 
-	// TODO: Potentially create an Engagement record here if business logic dictates.
-	// Or trigger a notification.
+// CancelShiftSignup is the resolver for the cancelShiftSignup field.
+func (r *mutationResolver) CancelShiftSignup(ctx context.Context, shiftID string) (*model.Shift, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	r.DB.Preload("Volunteer").Preload("Project").First(&applicationToUpdate, applicationToUpdate.ID)
-	return &applicationToUpdate, nil
+	shiftIDUsable, err := utils.IdToUint(shiftID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ShiftService.CancelSignup(ctx, currentUser, shiftIDUsable)
 }
 
-// RejectApplication is the resolver for the rejectApplication field.
-func (r *mutationResolver) RejectApplication(ctx context.Context, applicationID string) (*model.Application, error) {
-	// Similar logic to AcceptApplication for auth and fetching
-	_, err := auth.GetUserFromContext(ctx)
+// LogHours is the resolver for the logHours field.
+func (r *mutationResolver) LogHours(ctx context.Context, engagementID string, hours float64, date string, description *string) (*model.HoursLogged, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	applicationIDUsable, err := utils.IdToUint(applicationID)
+	engagementIDUsable, err := utils.IdToUint(engagementID)
 	if err != nil {
 		return nil, err
 	}
+	return r.EngagementService.LogHours(ctx, currentUser, engagementIDUsable, hours, date, description)
+}
 
-	var applicationToUpdate model.Application
-	if err := r.DB.Preload("Project").First(&applicationToUpdate, applicationIDUsable).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch application: %w", err)
+// ReviewEngagement is the resolver for the reviewEngagement field.
+func (r *mutationResolver) ReviewEngagement(ctx context.Context, engagementID string, input model.ReviewInput) (*model.Review, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	engagementIDUsable, err := utils.IdToUint(engagementID)
+	if err != nil {
+		return nil, err
 	}
+	return r.ReviewService.Submit(ctx, currentUser, engagementIDUsable, input)
+}
 
-	// Authorization check here...
+// FlagReview is the resolver for the flagReview field.
+func (r *mutationResolver) FlagReview(ctx context.Context, reviewID string, reason string) (bool, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return false, errors.New("unauthenticated: " + err.Error())
+	}
 
-	if applicationToUpdate.Status != model.Pending {
-		return nil, fmt.Errorf("application is not pending (current status: %s)", applicationToUpdate.Status)
+	reviewIDUsable, err := utils.IdToUint(reviewID)
+	if err != nil {
+		return false, err
+	}
+	if err := r.ReviewService.Flag(ctx, currentUser, reviewIDUsable, reason); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	applicationToUpdate.Status = model.Rejected
-	now := time.Now()
-	applicationToUpdate.DecidedAt = &now
+// ModerateReview is the resolver for the moderateReview field.
+func (r *mutationResolver) ModerateReview(ctx context.Context, reviewID string, hidden bool) (*model.Review, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	if err := r.DB.Save(&applicationToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to reject application: %w", err)
+	reviewIDUsable, err := utils.IdToUint(reviewID)
+	if err != nil {
+		return nil, err
 	}
-	r.DB.Preload("Volunteer").Preload("Project").First(&applicationToUpdate, applicationToUpdate.ID)
-	return &applicationToUpdate, nil
+	return r.ReviewService.Moderate(ctx, currentUser, reviewIDUsable, hidden)
 }
 
-// StartVolunteering is the resolver for the startVolunteering field.
-func (r *mutationResolver) StartVolunteering(ctx context.Context, projectID string) (*model.Engagement, error) {
+// ReviewHours is the resolver for the reviewHours field.
+func (r *mutationResolver) ReviewHours(ctx context.Context, id string, approved bool) (*model.HoursLogged, error) {
 	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	projectIDUsable, err := utils.IdToUint(projectID)
+	hoursID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.EngagementService.ReviewHours(ctx, currentUser, hoursID, approved)
+}
+
+// GenerateHoursStatement is the resolver for the generateHoursStatement field.
+func (r *mutationResolver) GenerateHoursStatement(ctx context.Context, from *string, to *string) (*model.Certificate, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	v := validation.New()
+	var fromTime, toTime *time.Time
+	if from != nil {
+		fromTime = v.DateTime(*from, "from")
+	}
+	if to != nil {
+		toTime = v.DateTime(*to, "to")
+	}
+	if fromTime != nil && toTime != nil {
+		v.Check(!toTime.Before(*fromTime), "to", "must not be before from")
+	}
+	if err := v.Err(); err != nil {
 		return nil, err
 	}
 
-	// This mutation implies an accepted application exists for the current user and project.
-	// Or, it could be initiated by a nonprofit admin for a volunteer.
-	// Let's assume it's for a volunteer who has an accepted application.
+	return r.EngagementService.IssueHoursStatement(ctx, currentUser, fromTime, toTime)
+}
 
-	var acceptedApplication model.Application
-	err = r.DB.Where("volunteer_id = ? AND project_id = ? AND status = ?",
-		currentUser.ID, projectIDUsable, model.Accepted).First(&acceptedApplication).Error
+// SendMessage is the resolver for the sendMessage field.
+func (r *mutationResolver) SendMessage(ctx context.Context, input model.SendMessageInput) (*model.Message, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no accepted application found for you on this project, or you are not the volunteer")
-		}
-		return nil, fmt.Errorf("failed to find accepted application: %w", err)
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return r.MessageService.Send(ctx, currentUser, input)
+}
+
+// MarkRead is the resolver for the markRead field.
+func (r *mutationResolver) MarkRead(ctx context.Context, conversationID string) (*model.Conversation, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// Check if an engagement already exists for this application/project/volunteer
-	var existingEngagement model.Engagement
-	err = r.DB.Where("volunteer_id = ? AND project_id = ?", currentUser.ID, projectIDUsable).First(&existingEngagement).Error
-	if err == nil { // Engagement already exists
-		return nil, errors.New("an engagement for this project and volunteer already exists")
+	conversationIDUsable, err := utils.IdToUint(conversationID)
+	if err != nil {
+		return nil, err
 	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("error checking existing engagement: %w", err)
+	return r.MessageService.MarkRead(ctx, currentUser, conversationIDUsable)
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return 0, errors.New("unauthenticated: " + err.Error())
 	}
 
-	newEngagement := model.Engagement{
-		VolunteerID: currentUser.ID,  // Or from application.VolunteerID
-		ProjectID:   projectIDUsable, // Or from application.ProjectID
-		StartDate:   time.Now(),
-		Status:      model.EngagementActive,
+	var notificationIDs []uint
+	if ids != nil {
+		notificationIDs = make([]uint, 0, len(ids))
+		for _, id := range ids {
+			notificationID, err := utils.IdToUint(id)
+			if err != nil {
+				return 0, err
+			}
+			notificationIDs = append(notificationIDs, notificationID)
+		}
 	}
+	count, err := r.NotificationService.MarkRead(ctx, currentUser, notificationIDs)
+	return int32(count), err
+}
 
-	if err := r.DB.Create(&newEngagement).Error; err != nil {
-		return nil, fmt.Errorf("failed to start engagement: %w", err)
+// SetNotificationPreference is the resolver for the setNotificationPreference field.
+func (r *mutationResolver) SetNotificationPreference(ctx context.Context, event model.NotificationEvent, channels []model.NotificationChannel) (*model.NotificationPreference, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
+	return r.NotificationService.SetPreference(ctx, currentUser, event, channels)
+}
 
-	// Optionally, update project status to IN_PROGRESS if not already
-	r.DB.Model(&model.Project{}).Where("id = ?", projectIDUsable).Update("status", model.InProgress)
+// SetDigestFrequency is the resolver for the setDigestFrequency field.
+func (r *mutationResolver) SetDigestFrequency(ctx context.Context, frequency model.DigestFrequency) (*model.User, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	r.DB.Preload("Volunteer").Preload("Project").First(&newEngagement, newEngagement.ID)
-	return &newEngagement, nil
+	return r.UserService.SetDigestFrequency(ctx, currentUser, frequency)
 }
 
-// CompleteEngagement is the resolver for the completeEngagement field.
-func (r *mutationResolver) CompleteEngagement(ctx context.Context, engagementID string, feedback *string) (*model.Engagement, error) {
+// CreateWebhookSubscription is the resolver for the createWebhookSubscription field.
+func (r *mutationResolver) CreateWebhookSubscription(ctx context.Context, nonprofitID string, input model.WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
 	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	engagementIDUsable, err := utils.IdToUint(engagementID)
+	nonprofitIDUsable, err := utils.IdToUint(nonprofitID)
 	if err != nil {
 		return nil, err
 	}
+	return r.WebhookService.Create(ctx, currentUser, nonprofitIDUsable, input)
+}
 
-	var engagementToUpdate model.Engagement
-	if err := r.DB.First(&engagementToUpdate, engagementIDUsable).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch engagement: %w", err)
+// UpdateWebhookSubscription is the resolver for the updateWebhookSubscription field.
+func (r *mutationResolver) UpdateWebhookSubscription(ctx context.Context, id string, input model.WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	// Authorization: Only the volunteer or nonprofit admin related to this engagement can complete it.
-	if engagementToUpdate.VolunteerID != currentUser.ID /* && !isNonprofitAdminOfProject(currentUser, engagementToUpdate.ProjectID) */ {
-		return nil, errors.New("unauthorized to complete this engagement")
+	subscriptionID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
 	}
+	return r.WebhookService.Update(ctx, currentUser, subscriptionID, input)
+}
 
-	if engagementToUpdate.Status != model.EngagementActive {
-		return nil, fmt.Errorf("engagement is not active (current status: %s)", engagementToUpdate.Status)
+// DeleteWebhookSubscription is the resolver for the deleteWebhookSubscription field.
+func (r *mutationResolver) DeleteWebhookSubscription(ctx context.Context, id string) (bool, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return false, errors.New("unauthenticated: " + err.Error())
 	}
 
-	engagementToUpdate.Status = model.EngagementCompleted
-	now := time.Now()
-	engagementToUpdate.EndDate = &now
-	if feedback != nil {
-		engagementToUpdate.Feedback = feedback
-		engagementToUpdate.FeedbackSubmittedAt = &now
+	subscriptionID, err := utils.IdToUint(id)
+	if err != nil {
+		return false, err
 	}
-
-	if err := r.DB.Save(&engagementToUpdate).Error; err != nil {
-		return nil, fmt.Errorf("failed to complete engagement: %w", err)
+	if err := r.WebhookService.Delete(ctx, currentUser, subscriptionID); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	// Optionally, if all engagements for a project are complete, update project status.
+// RotateWebhookSecret is the resolver for the rotateWebhookSecret field.
+func (r *mutationResolver) RotateWebhookSecret(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
 
-	r.DB.Preload("Volunteer").Preload("Project").First(&engagementToUpdate, engagementToUpdate.ID)
-	return &engagementToUpdate, nil
+	subscriptionID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.WebhookService.RotateSecret(ctx, currentUser, subscriptionID)
 }
 
-// LogHours is the resolver for the logHours field.
-func (r *mutationResolver) LogHours(ctx context.Context, engagementID string, hours float64, date string, description *string) (*model.HoursLogged, error) {
+// RedeliverWebhook is the resolver for the redeliverWebhook field.
+func (r *mutationResolver) RedeliverWebhook(ctx context.Context, deliveryID string) (*model.WebhookDelivery, error) {
 	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
 		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	engagementIDUsable, err := utils.IdToUint(engagementID)
+	deliveryIDUsable, err := utils.IdToUint(deliveryID)
 	if err != nil {
 		return nil, err
 	}
+	return r.WebhookService.Redeliver(ctx, currentUser, deliveryIDUsable)
+}
 
-	var engagement model.Engagement
-	if err := r.DB.Where("id = ? AND volunteer_id = ?", engagementID, currentUser.ID).First(&engagement).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("active engagement not found for you, or you are not the volunteer")
-		}
-		return nil, fmt.Errorf("failed to fetch engagement: %w", err)
+// PingWebhook is the resolver for the pingWebhook field.
+func (r *mutationResolver) PingWebhook(ctx context.Context, subscriptionID string) (*model.WebhookDelivery, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
 	}
 
-	if engagement.Status != model.EngagementActive {
-		return nil, errors.New("cannot log hours for an engagement that is not active")
+	subscriptionIDUsable, err := utils.IdToUint(subscriptionID)
+	if err != nil {
+		return nil, err
 	}
+	return r.WebhookService.Ping(ctx, currentUser, subscriptionIDUsable)
+}
 
-	dateUsable, err := utils.ParseDateTimeString(date)
+// RegisterPersistedQueries is the resolver for the registerPersistedQueries field.
+func (r *mutationResolver) RegisterPersistedQueries(ctx context.Context, version string, manifest string) (*model.PersistedQueryRegistration, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date for hours logged: %w", err)
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	// Authorization: Only PlatformAdmins can change which operations production accepts
+	if currentUser.Role != model.PlatformAdmin {
+		return nil, errors.New("unauthorized: only platform admins can register persisted queries")
 	}
 
-	newHoursLogged := model.HoursLogged{
-		EngagementID: engagementIDUsable,
-		Date:         dateUsable,
-		Hours:        hours,
-		Description:  description,
-		// Approved defaults to null/false depending on DB. Approval is a separate step.
+	parsed, err := persisted.ParseManifest([]byte(manifest), version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
 	}
 
-	if err := r.DB.Create(&newHoursLogged).Error; err != nil {
-		return nil, fmt.Errorf("failed to log hours: %w", err)
+	added, err := r.PersistedQueries.Register(ctx, parsed)
+	if err != nil {
+		return nil, err
 	}
 
-	r.DB.Preload("Engagement").Preload("ApprovedBy").First(&newHoursLogged, newHoursLogged.ID)
-	return &newHoursLogged, nil
+	return &model.PersistedQueryRegistration{
+		Version: version,
+		Added:   int32(added),
+		Total:   int32(len(parsed.Operations)),
+	}, nil
 }
 
 // ID is the resolver for the id field.
@@ -971,14 +1110,9 @@ func (r *nonprofitResolver) ID(ctx context.Context, obj *model.Nonprofit) (strin
 }
 
 // Logo is the resolver for the logo field.
-func (r *nonprofitResolver) Logo(ctx context.Context, obj *model.Nonprofit) (*string, error) {
-	if obj.LogoURL == "" {
-		return nil, nil
-	}
-	// This assumes LogoURL is a relative path or full URL.
-	// If it's a relative path, you might need to prefix it with your domain.
-	// e.g., return "https://yourdomain.com/" + obj.LogoURL, nil
-	return &obj.LogoURL, nil
+func (r *nonprofitResolver) Logo(ctx context.Context, obj *model.Nonprofit, size model.ImageSize, format model.ImageFormat) (*string, error) {
+	// LogoURL holds the base storage key of the logo's variants; pick one and resolve it to a URL.
+	return storage.ResolveURL(ctx, r.Storage, media.VariantKey(obj.LogoURL, size, format))
 }
 
 // CreatedAt is the resolver for the createdAt field.
@@ -991,15 +1125,51 @@ func (r *nonprofitResolver) UpdatedAt(ctx context.Context, obj *model.Nonprofit)
 	return utils.FormatTime(obj.UpdatedAt), nil
 }
 
-// ID is the resolver for the id field.
-func (r *projectResolver) ID(ctx context.Context, obj *model.Project) (string, error) {
-	// obj is the *model.Project fetched by the parent resolver
-	return fmt.Sprintf("%d", obj.ID), nil // Convert uint to string
+// Rating is the resolver for the rating field.
+func (r *nonprofitResolver) Rating(ctx context.Context, obj *model.Nonprofit) (*model.RatingSummary, error) {
+	return reviews.NonprofitRating(r.DB, obj.ID)
 }
 
-// StartDate is the resolver for the startDate field.
-func (r *projectResolver) StartDate(ctx context.Context, obj *model.Project) (*string, error) {
-	return utils.FormatNullableTime(obj.StartDate), nil
+// Reviews is the resolver for the reviews field.
+func (r *nonprofitResolver) Reviews(ctx context.Context, obj *model.Nonprofit) ([]*model.Review, error) {
+	var reviewList []*model.Review
+	if err := r.DB.Where("nonprofit_id = ? AND direction = ? AND hidden = ?", obj.ID, model.ReviewOfNonprofit, false).
+		Order("created_at DESC").
+		Find(&reviewList).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return reviewList, nil
+}
+
+// ID is the resolver for the id field.
+func (r *notificationResolver) ID(ctx context.Context, obj *model.Notification) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Read is the resolver for the read field.
+func (r *notificationResolver) Read(ctx context.Context, obj *model.Notification) (bool, error) {
+	return obj.ReadAt != nil, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *notificationResolver) CreatedAt(ctx context.Context, obj *model.Notification) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// Channels is the resolver for the channels field.
+func (r *notificationPreferenceResolver) Channels(ctx context.Context, obj *model.NotificationPreference) ([]model.NotificationChannel, error) {
+	return notifications.PreferenceChannels(obj), nil
+}
+
+// ID is the resolver for the id field.
+func (r *projectResolver) ID(ctx context.Context, obj *model.Project) (string, error) {
+	// obj is the *model.Project fetched by the parent resolver
+	return fmt.Sprintf("%d", obj.ID), nil // Convert uint to string
+}
+
+// StartDate is the resolver for the startDate field.
+func (r *projectResolver) StartDate(ctx context.Context, obj *model.Project) (*string, error) {
+	return utils.FormatNullableTime(obj.StartDate), nil
 }
 
 // EndDate is the resolver for the endDate field.
@@ -1007,6 +1177,21 @@ func (r *projectResolver) EndDate(ctx context.Context, obj *model.Project) (*str
 	return utils.FormatNullableTime(obj.EndDate), nil
 }
 
+// VolunteersNeeded is the resolver for the volunteersNeeded field.
+func (r *projectResolver) VolunteersNeeded(ctx context.Context, obj *model.Project) (int32, error) {
+	return int32(obj.VolunteersNeeded), nil
+}
+
+// OpenSlots is the resolver for the openSlots field.
+func (r *projectResolver) OpenSlots(ctx context.Context, obj *model.Project) (*int32, error) {
+	open, err := lifecycle.OpenSlots(r.DB, obj)
+	if err != nil || open == nil {
+		return nil, err
+	}
+	result := int32(*open)
+	return &result, nil
+}
+
 // CreatedAt is the resolver for the createdAt field.
 func (r *projectResolver) CreatedAt(ctx context.Context, obj *model.Project) (string, error) {
 	return utils.FormatTime(obj.CreatedAt), nil
@@ -1041,6 +1226,125 @@ func (r *projectResolver) Nonprofit(ctx context.Context, obj *model.Project) (*m
 	return &nonprofit, nil // Return pointer
 }
 
+// StatusHistory is the resolver for the statusHistory field.
+func (r *projectResolver) StatusHistory(ctx context.Context, obj *model.Project) ([]*model.ProjectStatusChange, error) {
+	var history []*model.ProjectStatusChange
+	if err := r.DB.Where("project_id = ?", obj.ID).
+		Preload("ChangedBy").
+		Order("created_at ASC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project status history: %w", err)
+	}
+	return history, nil
+}
+
+// RoleSlots is the resolver for the roleSlots field.
+func (r *projectResolver) RoleSlots(ctx context.Context, obj *model.Project) ([]*model.ProjectRoleSlot, error) {
+	var slots []*model.ProjectRoleSlot
+	if err := r.DB.Where("project_id = ?", obj.ID).Order("id ASC").Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role slots: %w", err)
+	}
+	return slots, nil
+}
+
+// Waitlist is the resolver for the waitlist field.
+func (r *projectResolver) Waitlist(ctx context.Context, obj *model.Project) ([]*model.Application, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return []*model.Application{}, nil
+	}
+	return r.ProjectService.Waitlist(currentUser, obj)
+}
+
+// Questions is the resolver for the questions field.
+func (r *projectResolver) Questions(ctx context.Context, obj *model.Project) ([]*model.ApplicationQuestion, error) {
+	var questions []*model.ApplicationQuestion
+	if err := r.DB.Where("project_id = ?", obj.ID).Order("position ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application questions: %w", err)
+	}
+	return questions, nil
+}
+
+// Shifts is the resolver for the shifts field.
+func (r *projectResolver) Shifts(ctx context.Context, obj *model.Project, upcomingOnly *bool) ([]*model.Shift, error) {
+	query := r.DB.Where("project_id = ?", obj.ID)
+	if upcomingOnly != nil && *upcomingOnly {
+		query = query.Where("starts_at > ?", time.Now())
+	}
+	var shifts []*model.Shift
+	if err := query.Order("starts_at ASC").Find(&shifts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	return shifts, nil
+}
+
+// Rating is the resolver for the rating field.
+func (r *projectResolver) Rating(ctx context.Context, obj *model.Project) (*model.RatingSummary, error) {
+	return reviews.ProjectRating(r.DB, obj.ID)
+}
+
+// ID is the resolver for the id field.
+func (r *projectRoleSlotResolver) ID(ctx context.Context, obj *model.ProjectRoleSlot) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Capacity is the resolver for the capacity field.
+func (r *projectRoleSlotResolver) Capacity(ctx context.Context, obj *model.ProjectRoleSlot) (int32, error) {
+	return int32(obj.Capacity), nil
+}
+
+// Filled is the resolver for the filled field.
+func (r *projectRoleSlotResolver) Filled(ctx context.Context, obj *model.ProjectRoleSlot) (int32, error) {
+	filled, err := lifecycle.AcceptedCount(r.DB, obj.ProjectID, &obj.ID)
+	if err != nil {
+		return 0, err
+	}
+	return int32(filled), nil
+}
+
+// Skill is the resolver for the skill field.
+func (r *projectRoleSlotResolver) Skill(ctx context.Context, obj *model.ProjectRoleSlot) (*model.Skill, error) {
+	if obj.SkillID == nil {
+		return nil, nil
+	}
+	var skill model.Skill
+	if err := r.DB.First(&skill, *obj.SkillID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch skill: %w", err)
+	}
+	return &skill, nil
+}
+
+// ID is the resolver for the id field.
+func (r *projectStatusChangeResolver) ID(ctx context.Context, obj *model.ProjectStatusChange) (string, error) {
+	// obj is the *model.ProjectStatusChange fetched by the parent resolver
+	return fmt.Sprintf("%d", obj.ID), nil // Convert uint to string
+}
+
+// ChangedAt is the resolver for the changedAt field.
+func (r *projectStatusChangeResolver) ChangedAt(ctx context.Context, obj *model.ProjectStatusChange) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// Avatar is the resolver for the avatar field.
+func (r *publicProfileResolver) Avatar(ctx context.Context, obj *model.User, size model.ImageSize, format model.ImageFormat) (*string, error) {
+	return storage.ResolveURL(ctx, r.Storage, media.VariantKey(obj.AvatarURL, size, format))
+}
+
+// MemberSince is the resolver for the memberSince field.
+func (r *publicProfileResolver) MemberSince(ctx context.Context, obj *model.User) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// URL is the resolver for the url field.
+func (r *publicProfileResolver) URL(ctx context.Context, obj *model.User) (string, error) {
+	return profiles.Path(*obj.ProfileSlug), nil
+}
+
+// Impact is the resolver for the impact field.
+func (r *publicProfileResolver) Impact(ctx context.Context, obj *model.User) (*model.ImpactSummary, error) {
+	return analytics.VolunteerImpact(r.DB, obj.ID)
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	user, err := auth.GetUserFromContext(ctx)
@@ -1075,6 +1379,15 @@ func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error
 	return &user, nil
 }
 
+// PublicProfile is the resolver for the publicProfile field.
+func (r *queryResolver) PublicProfile(ctx context.Context, slug string) (*model.User, error) {
+	user, err := profiles.Find(r.DB, slug)
+	if errors.Is(err, profiles.ErrNotFound) {
+		return nil, nil
+	}
+	return user, err
+}
+
 // Users is the resolver for the users field.
 func (r *queryResolver) Users(ctx context.Context, skills []string, availability *model.AvailabilityFilter, role *model.UserRole) ([]*model.User, error) {
 	query := r.DB.Model(&model.User{}).Preload("Skills").Preload("Causes")
@@ -1176,14 +1489,11 @@ func (r *queryResolver) Project(ctx context.Context, id string) (*model.Project,
 	if err != nil {
 		return nil, err
 	}
-	var project model.Project
-	if err := r.DB.Preload("SkillsNeeded").Preload("Applications").Preload("Engagements").First(&project, projectID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil // Not found
-		}
-		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		currentUser = nil // anyone may look at a project, but only its nonprofit sees who applied
 	}
-	return &project, nil
+	return r.ProjectService.Get(currentUser, projectID)
 }
 
 // Projects is the resolver for the projects field.
@@ -1287,6 +1597,372 @@ func (r *queryResolver) RecommendedVolunteers(ctx context.Context, projectID str
 	return users, nil
 }
 
+// NonprofitStats is the resolver for the nonprofitStats field.
+func (r *queryResolver) NonprofitStats(ctx context.Context, id string, from string, to string, granularity model.Granularity) (*model.NonprofitStats, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	nonprofitID, err := utils.IdToUint(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.NonprofitService.Stats(currentUser, nonprofitID, from, to, granularity)
+}
+
+// AdminDashboard is the resolver for the adminDashboard field.
+func (r *queryResolver) AdminDashboard(ctx context.Context, from string, to string) (*model.AdminDashboard, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	if err := services.RequirePlatformAdmin(currentUser, "view the admin dashboard"); err != nil {
+		return nil, err
+	}
+
+	v := validation.New()
+	fromTime := v.DateTime(from, "from")
+	toTime := v.DateTime(to, "to")
+	if fromTime != nil && toTime != nil {
+		v.Check(!toTime.Before(*fromTime), "to", "must not be before from")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return &model.AdminDashboard{From: *fromTime, To: *toTime}, nil
+}
+
+// SearchUsers is the resolver for the searchUsers field.
+func (r *queryResolver) SearchUsers(ctx context.Context, query string, role *model.UserRole, limit *int32, offset *int32) ([]*model.User, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	skip := 0
+	if offset != nil {
+		skip = int(*offset)
+	}
+	return r.UserService.Search(currentUser, query, role, clampLimit(limit, 25), skip)
+}
+
+// PlatformHealth is the resolver for the platformHealth field.
+func (r *queryResolver) PlatformHealth(ctx context.Context) (*model.PlatformHealth, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	if err := services.RequirePlatformAdmin(currentUser, "view platform health"); err != nil {
+		return nil, err
+	}
+
+	health := &model.PlatformHealth{
+		UptimeSeconds:    time.Since(r.StartedAt).Seconds(),
+		PersistedQueries: int32(r.PersistedQueries.Len()),
+	}
+
+	// A failing database is reported rather than returned as an error, that is the point of the query
+	sqlDB, err := r.DB.DB()
+	if err != nil {
+		return health, nil
+	}
+	start := time.Now()
+	pingErr := sqlDB.PingContext(ctx)
+	health.DatabaseLatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	health.OpenConnections = int32(sqlDB.Stats().OpenConnections)
+	if pingErr != nil {
+		return health, nil
+	}
+	health.DatabaseOk = true
+
+	if err := analytics.PlatformCounts(r.DB, health); err != nil {
+		return nil, fmt.Errorf("failed to count platform records: %w", err)
+	}
+
+	var pending []uint
+	if err := r.DB.Model(&model.Project{}).Where("status = ?", model.PendingReview).Pluck("id", &pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation queue: %w", err)
+	}
+	submitted, err := lifecycle.SubmittedAt(r.DB, pending)
+	if err != nil {
+		return nil, err
+	}
+	for _, at := range submitted {
+		if time.Since(at) > r.Moderation.SLA {
+			health.OverdueModeration++
+		}
+	}
+	return health, nil
+}
+
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, filter *model.ModerationQueueFilter) ([]*model.ModerationQueueItem, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	if err := services.RequirePlatformAdmin(currentUser, "view the moderation queue"); err != nil {
+		return nil, err
+	}
+
+	query := r.DB.Model(&model.Project{}).
+		Where("projects.status = ?", model.PendingReview).
+		Preload("SkillsNeeded")
+
+	if filter != nil {
+		if filter.NonprofitID != nil && *filter.NonprofitID != "" {
+			nonprofitID, err := utils.IdToUint(*filter.NonprofitID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid nonprofit ID for filtering: %w", err)
+			}
+			query = query.Where("projects.nonprofit_id = ?", nonprofitID)
+		}
+		if filter.VerifiedNonprofitOnly != nil && *filter.VerifiedNonprofitOnly {
+			query = query.Joins("JOIN nonprofits ON nonprofits.id = projects.nonprofit_id").
+				Where("nonprofits.verified = ?", true)
+		}
+		if filter.Urgency != nil {
+			query = query.Where("projects.urgency = ?", *filter.Urgency)
+		}
+	}
+
+	var projects []*model.Project
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation queue: %w", err)
+	}
+
+	projectIDs := make([]uint, 0, len(projects))
+	for _, p := range projects {
+		projectIDs = append(projectIDs, p.ID)
+	}
+	submitted, err := lifecycle.SubmittedAt(r.DB, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]*model.ModerationQueueItem, 0, len(projects))
+	for _, p := range projects {
+		// Projects submitted before status history existed fall back to their last update
+		submittedAt, ok := submitted[p.ID]
+		if !ok {
+			submittedAt = p.UpdatedAt
+		}
+		age := now.Sub(submittedAt)
+		overdue := age > r.Moderation.SLA
+		if filter != nil && filter.OverdueOnly != nil && *filter.OverdueOnly && !overdue {
+			continue
+		}
+		items = append(items, &model.ModerationQueueItem{
+			Project:     p,
+			SubmittedAt: utils.FormatTime(submittedAt),
+			AgeHours:    age.Hours(),
+			Overdue:     overdue,
+		})
+	}
+
+	// Oldest submissions first, so the queue is worked in SLA order
+	sort.Slice(items, func(i, j int) bool { return items[i].AgeHours > items[j].AgeHours })
+	return items, nil
+}
+
+// FlaggedReviews is the resolver for the flaggedReviews field.
+func (r *queryResolver) FlaggedReviews(ctx context.Context) ([]*model.Review, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	if err := services.RequirePlatformAdmin(currentUser, "view flagged reviews"); err != nil {
+		return nil, err
+	}
+	return reviews.FlaggedReviews(r.DB)
+}
+
+// Conversations is the resolver for the conversations field.
+func (r *queryResolver) Conversations(ctx context.Context) ([]*model.Conversation, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return messaging.Conversations(r.DB, currentUser)
+}
+
+// Messages is the resolver for the messages field.
+func (r *queryResolver) Messages(ctx context.Context, conversationID string, before *string, limit *int32) ([]*model.Message, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	conversation, err := r.findConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := messaging.Authorize(r.DB, currentUser, conversation); err != nil {
+		return nil, err
+	}
+
+	var beforeID uint
+	if before != nil {
+		if beforeID, err = utils.IdToUint(*before); err != nil {
+			return nil, err
+		}
+	}
+	return messaging.Messages(r.DB, conversation.ID, beforeID, clampLimit(limit, 50))
+}
+
+// UnreadMessageCount is the resolver for the unreadMessageCount field.
+func (r *queryResolver) UnreadMessageCount(ctx context.Context) (int32, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return 0, errors.New("unauthenticated: " + err.Error())
+	}
+	count, err := messaging.TotalUnread(r.DB, currentUser.ID)
+	return int32(count), err
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, unreadOnly *bool, limit *int32) ([]*model.Notification, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return notifications.List(r.DB, currentUser.ID, unreadOnly != nil && *unreadOnly, clampLimit(limit, 50))
+}
+
+// UnreadNotificationCount is the resolver for the unreadNotificationCount field.
+func (r *queryResolver) UnreadNotificationCount(ctx context.Context) (int32, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return 0, errors.New("unauthenticated: " + err.Error())
+	}
+	count, err := notifications.UnreadCount(r.DB, currentUser.ID)
+	return int32(count), err
+}
+
+// NotificationPreferences is the resolver for the notificationPreferences field.
+func (r *queryResolver) NotificationPreferences(ctx context.Context) ([]*model.NotificationPreference, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return notifications.Preferences(r.DB, currentUser.ID)
+}
+
+// WebhookSubscriptions is the resolver for the webhookSubscriptions field.
+func (r *queryResolver) WebhookSubscriptions(ctx context.Context, nonprofitID string) ([]*model.WebhookSubscription, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	nonprofitIDUsable, err := utils.IdToUint(nonprofitID)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.RequireNonprofitMember(r.Repos.Nonprofits, currentUser, nonprofitIDUsable, "view webhooks"); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []*model.WebhookSubscription
+	if err := r.DB.Where("nonprofit_id = ?", nonprofitIDUsable).Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, subscriptionID string, status *model.WebhookDeliveryStatus, limit *int32) ([]*model.WebhookDelivery, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	subscription, err := r.findWebhookSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.RequireNonprofitMember(r.Repos.Nonprofits, currentUser, subscription.NonprofitID, "view webhooks"); err != nil {
+		return nil, err
+	}
+
+	query := r.DB.Where("subscription_id = ?", subscription.ID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	var deliveries []*model.WebhookDelivery
+	if err := query.Order("id DESC").Limit(clampLimit(limit, 50)).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, filter *model.AuditLogFilter, before *string, limit *int32) ([]*model.AuditEvent, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	if err := services.RequirePlatformAdmin(currentUser, "view the audit log"); err != nil {
+		return nil, err
+	}
+
+	query, beforeID, err := auditQuery(filter, before)
+	if err != nil {
+		return nil, err
+	}
+	return audit.List(r.DB, query, beforeID, clampLimit(limit, 50))
+}
+
+// NonprofitAuditLog is the resolver for the nonprofitAuditLog field.
+func (r *queryResolver) NonprofitAuditLog(ctx context.Context, nonprofitID string, filter *model.AuditLogFilter, before *string, limit *int32) ([]*model.AuditEvent, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	nonprofitIDUsable, err := utils.IdToUint(nonprofitID)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.RequireNonprofitMember(r.Repos.Nonprofits, currentUser, nonprofitIDUsable, "view the audit log"); err != nil {
+		return nil, err
+	}
+
+	query, beforeID, err := auditQuery(filter, before)
+	if err != nil {
+		return nil, err
+	}
+	query.NonprofitID = &nonprofitIDUsable
+	return audit.List(r.DB, query, beforeID, clampLimit(limit, 50))
+}
+
+// MyCertificates is the resolver for the myCertificates field.
+func (r *queryResolver) MyCertificates(ctx context.Context) ([]*model.Certificate, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+
+	var certs []*model.Certificate
+	if err := r.DB.Where("volunteer_id = ?", currentUser.ID).
+		Preload("Volunteer").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Order("created_at DESC").
+		Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// VerifyCertificate is the resolver for the verifyCertificate field.
+func (r *queryResolver) VerifyCertificate(ctx context.Context, code string) (*model.Certificate, error) {
+	cert, err := certificates.Verify(r.DB, code)
+	if errors.Is(err, certificates.ErrNotFound) {
+		return nil, nil
+	}
+	return cert, err
+}
+
 // Skills is the resolver for the skills field.
 func (r *queryResolver) Skills(ctx context.Context) ([]*model.Skill, error) {
 	var skills []*model.Skill // Use slice of pointers as gqlgen expects
@@ -1310,6 +1986,142 @@ func (r *queryResolver) Causes(ctx context.Context) ([]*model.Cause, error) {
 	return causes, nil
 }
 
+// ID is the resolver for the id field.
+func (r *reviewResolver) ID(ctx context.Context, obj *model.Review) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Rating is the resolver for the rating field.
+func (r *reviewResolver) Rating(ctx context.Context, obj *model.Review) (int32, error) {
+	return int32(obj.Rating), nil
+}
+
+// ProjectRating is the resolver for the projectRating field.
+func (r *reviewResolver) ProjectRating(ctx context.Context, obj *model.Review) (*int32, error) {
+	if obj.ProjectRating == nil {
+		return nil, nil
+	}
+	rating := int32(*obj.ProjectRating)
+	return &rating, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *reviewResolver) CreatedAt(ctx context.Context, obj *model.Review) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// Reviewer is the resolver for the reviewer field.
+func (r *reviewResolver) Reviewer(ctx context.Context, obj *model.Review) (*model.User, error) {
+	var reviewer model.User
+	if err := r.DB.First(&reviewer, obj.ReviewerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reviewer: %w", err)
+	}
+	return &reviewer, nil
+}
+
+// Engagement is the resolver for the engagement field.
+func (r *reviewResolver) Engagement(ctx context.Context, obj *model.Review) (*model.Engagement, error) {
+	var engagement model.Engagement
+	if err := r.DB.First(&engagement, obj.EngagementID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch engagement: %w", err)
+	}
+	return &engagement, nil
+}
+
+// EndorsedSkills is the resolver for the endorsedSkills field.
+func (r *reviewResolver) EndorsedSkills(ctx context.Context, obj *model.Review) ([]*model.Skill, error) {
+	if obj.Direction != model.ReviewOfVolunteer {
+		return []*model.Skill{}, nil
+	}
+	var skills []*model.Skill
+	if err := r.DB.Where("id IN (SELECT skill_id FROM skill_endorsements WHERE engagement_id = ? AND deleted_at IS NULL)", obj.EngagementID).
+		Order("name").
+		Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch endorsed skills: %w", err)
+	}
+	return skills, nil
+}
+
+// ID is the resolver for the id field.
+func (r *shiftResolver) ID(ctx context.Context, obj *model.Shift) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// StartsAt is the resolver for the startsAt field.
+func (r *shiftResolver) StartsAt(ctx context.Context, obj *model.Shift) (string, error) {
+	return utils.FormatTime(obj.StartsAt), nil
+}
+
+// EndsAt is the resolver for the endsAt field.
+func (r *shiftResolver) EndsAt(ctx context.Context, obj *model.Shift) (string, error) {
+	return utils.FormatTime(obj.EndsAt), nil
+}
+
+// Timezone is the resolver for the timezone field.
+func (r *shiftResolver) Timezone(ctx context.Context, obj *model.Shift) (string, error) {
+	var project model.Project
+	if err := r.DB.Select("id", "timezone").First(&project, obj.ProjectID).Error; err != nil {
+		return "", fmt.Errorf("failed to fetch project: %w", err)
+	}
+	return services.ProjectLocation(&project).String(), nil
+}
+
+// Capacity is the resolver for the capacity field.
+func (r *shiftResolver) Capacity(ctx context.Context, obj *model.Shift) (int32, error) {
+	return int32(obj.Capacity), nil
+}
+
+// SignedUp is the resolver for the signedUp field.
+func (r *shiftResolver) SignedUp(ctx context.Context, obj *model.Shift) (int32, error) {
+	count, err := lifecycle.ShiftSignups(r.DB, obj.ID)
+	if err != nil {
+		return 0, err
+	}
+	return int32(count), nil
+}
+
+// FitsMyAvailability is the resolver for the fitsMyAvailability field.
+func (r *shiftResolver) FitsMyAvailability(ctx context.Context, obj *model.Shift) (*bool, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	fits := lifecycle.ShiftFitsAvailability(obj, currentUser.Availability)
+	return &fits, nil
+}
+
+// Project is the resolver for the project field.
+func (r *shiftResolver) Project(ctx context.Context, obj *model.Shift) (*model.Project, error) {
+	var project model.Project
+	if err := r.DB.First(&project, obj.ProjectID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project for shift: %w", err)
+	}
+	return &project, nil
+}
+
+// Volunteers is the resolver for the volunteers field.
+func (r *shiftResolver) Volunteers(ctx context.Context, obj *model.Shift) ([]*model.User, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return []*model.User{}, nil
+	}
+	var project model.Project
+	if err := r.DB.First(&project, obj.ProjectID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project for shift: %w", err)
+	}
+	if _, ok, err := services.ProjectActor(r.Repos.Nonprofits, currentUser, project.NonprofitID); err != nil || !ok {
+		return []*model.User{}, err
+	}
+
+	var volunteers []*model.User
+	if err := r.DB.Joins("JOIN shift_signups ON shift_signups.volunteer_id = users.id").
+		Where("shift_signups.shift_id = ? AND shift_signups.cancelled_at IS NULL AND shift_signups.deleted_at IS NULL", obj.ID).
+		Find(&volunteers).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch shift volunteers: %w", err)
+	}
+	return volunteers, nil
+}
+
 // ID is the resolver for the id field.
 func (r *skillResolver) ID(ctx context.Context, obj *model.Skill) (string, error) {
 	// obj is the *model.Skill fetched by the parent resolver
@@ -1318,38 +2130,43 @@ func (r *skillResolver) ID(ctx context.Context, obj *model.Skill) (string, error
 
 // ProjectUpdated is the resolver for the projectUpdated field.
 func (r *subscriptionResolver) ProjectUpdated(ctx context.Context, projectID string) (<-chan *model.Project, error) {
-	// 1. Validate projectIDStr
-	// 2. Create a channel for this specific subscription.
-	// 3. In your mutation resolvers (e.g., UpdateProject, ChangeProjectStatus),
-	//    after a project is updated, you would publish an event to the PubSub system.
-	// 4. This subscription resolver (or a service it calls) would listen to those PubSub events
-	//    for the given projectID and send the updated Project data to the channel.
-	// 5. Handle channel closure and context cancellation.
-	// Example (very simplified in-memory, not for production):
-	// ch := make(chan *model.Project)
-	// go func() {
-	//  defer close(ch)
-	//  // Periodically check or listen to some in-memory event bus
-	//  // For example, every 5 seconds, fetch the project and send if changed (bad idea)
-	//  // A real implementation uses a proper event-driven PubSub
-	// }()
-	// return ch, nil
-	panic(fmt.Errorf("not implemented: ProjectUpdated - requires PubSub system"))
+	id, err := utils.IdToUint(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Repos.Projects.Find(id); errors.Is(err, services.ErrNotFound) {
+		return nil, fmt.Errorf("project with ID %d not found", id)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	return r.Activity.ProjectUpdated(ctx, id), nil
 }
 
 // ApplicationReceived is the resolver for the applicationReceived field.
 func (r *subscriptionResolver) ApplicationReceived(ctx context.Context) (<-chan *model.Application, error) {
-	// Similar to ProjectUpdated:
-	// - Mutations like ApplyToProject would publish an "application received" event.
-	// - This resolver would subscribe to such events (perhaps filtered by nonprofit admin, etc.).
-	panic(fmt.Errorf("not implemented: ApplicationReceived - requires PubSub system"))
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return r.Activity.ApplicationReceived(ctx, currentUser.ID), nil
 }
 
 // EngagementStarted is the resolver for the engagementStarted field.
 func (r *subscriptionResolver) EngagementStarted(ctx context.Context) (<-chan *model.Engagement, error) {
-	// Similar to ProjectUpdated:
-	// - Mutation StartVolunteering would publish an "engagement started" event.
-	panic(fmt.Errorf("not implemented: EngagementStarted - requires PubSub system"))
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return r.Activity.EngagementStarted(ctx, currentUser.ID), nil
+}
+
+// MessageReceived is the resolver for the messageReceived field.
+func (r *subscriptionResolver) MessageReceived(ctx context.Context) (<-chan *model.Message, error) {
+	currentUser, err := auth.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.New("unauthenticated: " + err.Error())
+	}
+	return r.Messages.Subscribe(ctx, currentUser.ID), nil
 }
 
 // ID is the resolver for the id field.
@@ -1359,12 +2176,9 @@ func (r *userResolver) ID(ctx context.Context, obj *model.User) (string, error)
 }
 
 // Avatar is the resolver for the avatar field.
-func (r *userResolver) Avatar(ctx context.Context, obj *model.User) (*string, error) {
-	if obj.AvatarURL == "" {
-		return nil, nil
-	}
-	// Similar to Nonprofit.Logo, adjust if it's a relative path vs. full URL
-	return &obj.AvatarURL, nil
+func (r *userResolver) Avatar(ctx context.Context, obj *model.User, size model.ImageSize, format model.ImageFormat) (*string, error) {
+	// Same as Nonprofit.Logo: AvatarURL holds the base storage key of the avatar's variants
+	return storage.ResolveURL(ctx, r.Storage, media.VariantKey(obj.AvatarURL, size, format))
 }
 
 // LinkedIn is the resolver for the linkedIn field.
@@ -1393,6 +2207,53 @@ func (r *userResolver) UpdatedAt(ctx context.Context, obj *model.User) (string,
 	return utils.FormatTime(obj.UpdatedAt), nil
 }
 
+// Impact is the resolver for the impact field.
+func (r *userResolver) Impact(ctx context.Context, obj *model.User) (*model.ImpactSummary, error) {
+	if !profileVisible(ctx, obj) {
+		return nil, nil
+	}
+	return analytics.VolunteerImpact(r.DB, obj.ID)
+}
+
+// Rating is the resolver for the rating field.
+func (r *userResolver) Rating(ctx context.Context, obj *model.User) (*model.RatingSummary, error) {
+	if !profileVisible(ctx, obj) {
+		return &model.RatingSummary{}, nil
+	}
+	return reviews.VolunteerRating(r.DB, obj.ID)
+}
+
+// Reviews is the resolver for the reviews field.
+func (r *userResolver) Reviews(ctx context.Context, obj *model.User) ([]*model.Review, error) {
+	if !profileVisible(ctx, obj) {
+		return []*model.Review{}, nil
+	}
+	var reviewList []*model.Review
+	if err := r.DB.Where("volunteer_id = ? AND direction = ? AND hidden = ?", obj.ID, model.ReviewOfVolunteer, false).
+		Order("created_at DESC").
+		Find(&reviewList).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return reviewList, nil
+}
+
+// SkillBadges is the resolver for the skillBadges field.
+func (r *userResolver) SkillBadges(ctx context.Context, obj *model.User) ([]*model.SkillBadge, error) {
+	if !profileVisible(ctx, obj) {
+		return []*model.SkillBadge{}, nil
+	}
+	return reviews.Badges(r.DB, obj.ID)
+}
+
+// PublicProfileURL is the resolver for the publicProfileUrl field.
+func (r *userResolver) PublicProfileURL(ctx context.Context, obj *model.User) (*string, error) {
+	if obj.ProfileVisibility != model.ProfilePublic || obj.ProfileSlug == nil {
+		return nil, nil
+	}
+	url := profiles.Path(*obj.ProfileSlug)
+	return &url, nil
+}
+
 // HoursLogged fetches all hours logged by the user across engagements.
 func (r *userResolver) HoursLogged(ctx context.Context, obj *model.User) ([]*model.HoursLogged, error) {
 	// Authorization: User can see their own hours. Admins might see others.
@@ -1417,33 +2278,170 @@ func (r *userResolver) HoursLogged(ctx context.Context, obj *model.User) ([]*mod
 	return hours, nil
 }
 
+// Pending is the resolver for the pending field.
+func (r *verificationBacklogResolver) Pending(ctx context.Context, obj *model.VerificationBacklog) (int32, error) {
+	return int32(obj.Pending), nil
+}
+
+// OldestCreatedAt is the resolver for the oldestCreatedAt field.
+func (r *verificationBacklogResolver) OldestCreatedAt(ctx context.Context, obj *model.VerificationBacklog) (*string, error) {
+	return utils.FormatNullableTime(obj.OldestCreatedAt), nil
+}
+
+// Nonprofits is the resolver for the nonprofits field.
+func (r *verificationBacklogResolver) Nonprofits(ctx context.Context, obj *model.VerificationBacklog, limit *int32) ([]*model.Nonprofit, error) {
+	var nonprofits []*model.Nonprofit
+	if err := r.DB.Where("verified = ?", false).
+		Order("created_at ASC").
+		Limit(clampLimit(limit, 20)).
+		Find(&nonprofits).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch unverified nonprofits: %w", err)
+	}
+	return nonprofits, nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookDeliveryResolver) ID(ctx context.Context, obj *model.WebhookDelivery) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Subscription is the resolver for the subscription field.
+func (r *webhookDeliveryResolver) Subscription(ctx context.Context, obj *model.WebhookDelivery) (*model.WebhookSubscription, error) {
+	// Unscoped: deliveries of a deleted subscription still show where they went
+	var subscription model.WebhookSubscription
+	if err := r.DB.Unscoped().First(&subscription, obj.SubscriptionID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *webhookDeliveryResolver) CreatedAt(ctx context.Context, obj *model.WebhookDelivery) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// DeliveredAt is the resolver for the deliveredAt field.
+func (r *webhookDeliveryResolver) DeliveredAt(ctx context.Context, obj *model.WebhookDelivery) (*string, error) {
+	return utils.FormatNullableTime(obj.DeliveredAt), nil
+}
+
+// RedeliveryOf is the resolver for the redeliveryOf field.
+func (r *webhookDeliveryResolver) RedeliveryOf(ctx context.Context, obj *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	if obj.RedeliveryOfID == nil {
+		return nil, nil
+	}
+	var delivery model.WebhookDelivery
+	if err := r.DB.First(&delivery, *obj.RedeliveryOfID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// ID is the resolver for the id field.
+func (r *webhookSubscriptionResolver) ID(ctx context.Context, obj *model.WebhookSubscription) (string, error) {
+	return fmt.Sprintf("%d", obj.ID), nil
+}
+
+// Nonprofit is the resolver for the nonprofit field.
+func (r *webhookSubscriptionResolver) Nonprofit(ctx context.Context, obj *model.WebhookSubscription) (*model.Nonprofit, error) {
+	var nonprofit model.Nonprofit
+	if err := r.DB.First(&nonprofit, obj.NonprofitID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
+	}
+	return &nonprofit, nil
+}
+
+// Events is the resolver for the events field.
+func (r *webhookSubscriptionResolver) Events(ctx context.Context, obj *model.WebhookSubscription) ([]model.WebhookEvent, error) {
+	events := make([]model.WebhookEvent, len(obj.Events))
+	for i, event := range obj.Events {
+		events[i] = model.WebhookEvent(event)
+	}
+	return events, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *webhookSubscriptionResolver) CreatedAt(ctx context.Context, obj *model.WebhookSubscription) (string, error) {
+	return utils.FormatTime(obj.CreatedAt), nil
+}
+
+// AdminDashboard returns AdminDashboardResolver implementation.
+func (r *Resolver) AdminDashboard() AdminDashboardResolver { return &adminDashboardResolver{r} }
+
 // Application returns ApplicationResolver implementation.
 func (r *Resolver) Application() ApplicationResolver { return &applicationResolver{r} }
 
+// ApplicationAnswer returns ApplicationAnswerResolver implementation.
+func (r *Resolver) ApplicationAnswer() ApplicationAnswerResolver {
+	return &applicationAnswerResolver{r}
+}
+
+// ApplicationQuestion returns ApplicationQuestionResolver implementation.
+func (r *Resolver) ApplicationQuestion() ApplicationQuestionResolver {
+	return &applicationQuestionResolver{r}
+}
+
+// AuditEvent returns AuditEventResolver implementation.
+func (r *Resolver) AuditEvent() AuditEventResolver { return &auditEventResolver{r} }
+
 // Availability returns AvailabilityResolver implementation.
 func (r *Resolver) Availability() AvailabilityResolver { return &availabilityResolver{r} }
 
 // Cause returns CauseResolver implementation.
 func (r *Resolver) Cause() CauseResolver { return &causeResolver{r} }
 
+// Certificate returns CertificateResolver implementation.
+func (r *Resolver) Certificate() CertificateResolver { return &certificateResolver{r} }
+
+// Conversation returns ConversationResolver implementation.
+func (r *Resolver) Conversation() ConversationResolver { return &conversationResolver{r} }
+
 // Engagement returns EngagementResolver implementation.
 func (r *Resolver) Engagement() EngagementResolver { return &engagementResolver{r} }
 
 // HoursLogged returns HoursLoggedResolver implementation.
 func (r *Resolver) HoursLogged() HoursLoggedResolver { return &hoursLoggedResolver{r} }
 
+// Message returns MessageResolver implementation.
+func (r *Resolver) Message() MessageResolver { return &messageResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Nonprofit returns NonprofitResolver implementation.
 func (r *Resolver) Nonprofit() NonprofitResolver { return &nonprofitResolver{r} }
 
+// Notification returns NotificationResolver implementation.
+func (r *Resolver) Notification() NotificationResolver { return &notificationResolver{r} }
+
+// NotificationPreference returns NotificationPreferenceResolver implementation.
+func (r *Resolver) NotificationPreference() NotificationPreferenceResolver {
+	return &notificationPreferenceResolver{r}
+}
+
 // Project returns ProjectResolver implementation.
 func (r *Resolver) Project() ProjectResolver { return &projectResolver{r} }
 
+// ProjectRoleSlot returns ProjectRoleSlotResolver implementation.
+func (r *Resolver) ProjectRoleSlot() ProjectRoleSlotResolver { return &projectRoleSlotResolver{r} }
+
+// ProjectStatusChange returns ProjectStatusChangeResolver implementation.
+func (r *Resolver) ProjectStatusChange() ProjectStatusChangeResolver {
+	return &projectStatusChangeResolver{r}
+}
+
+// PublicProfile returns PublicProfileResolver implementation.
+func (r *Resolver) PublicProfile() PublicProfileResolver { return &publicProfileResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Review returns ReviewResolver implementation.
+func (r *Resolver) Review() ReviewResolver { return &reviewResolver{r} }
+
+// Shift returns ShiftResolver implementation.
+func (r *Resolver) Shift() ShiftResolver { return &shiftResolver{r} }
+
 // Skill returns SkillResolver implementation.
 func (r *Resolver) Skill() SkillResolver { return &skillResolver{r} }
 
@@ -1453,15 +2451,45 @@ func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionRes
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
+// VerificationBacklog returns VerificationBacklogResolver implementation.
+func (r *Resolver) VerificationBacklog() VerificationBacklogResolver {
+	return &verificationBacklogResolver{r}
+}
+
+// WebhookDelivery returns WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() WebhookDeliveryResolver { return &webhookDeliveryResolver{r} }
+
+// WebhookSubscription returns WebhookSubscriptionResolver implementation.
+func (r *Resolver) WebhookSubscription() WebhookSubscriptionResolver {
+	return &webhookSubscriptionResolver{r}
+}
+
+type adminDashboardResolver struct{ *Resolver }
 type applicationResolver struct{ *Resolver }
+type applicationAnswerResolver struct{ *Resolver }
+type applicationQuestionResolver struct{ *Resolver }
+type auditEventResolver struct{ *Resolver }
 type availabilityResolver struct{ *Resolver }
 type causeResolver struct{ *Resolver }
+type certificateResolver struct{ *Resolver }
+type conversationResolver struct{ *Resolver }
 type engagementResolver struct{ *Resolver }
 type hoursLoggedResolver struct{ *Resolver }
+type messageResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type nonprofitResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
+type notificationPreferenceResolver struct{ *Resolver }
 type projectResolver struct{ *Resolver }
+type projectRoleSlotResolver struct{ *Resolver }
+type projectStatusChangeResolver struct{ *Resolver }
+type publicProfileResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type reviewResolver struct{ *Resolver }
+type shiftResolver struct{ *Resolver }
 type skillResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
+type verificationBacklogResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
+type webhookSubscriptionResolver struct{ *Resolver }
@@ -0,0 +1,159 @@
+// Package jobs is a background job queue stored in Postgres. Jobs are claimed with
+// SELECT ... FOR UPDATE SKIP LOCKED, so any number of workers on any number of machines can share
+// the queue, and enqueueing inside a transaction only makes the job visible once it commits.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Status string
+
+const (
+	StatusQueued  Status = "QUEUED"
+	StatusRunning Status = "RUNNING"
+	StatusDone    Status = "DONE"
+	// StatusDead is the dead letter state: the job failed MaxAttempts times, or permanently, and
+	// is kept with its LastError for someone to look at.
+	StatusDead Status = "DEAD"
+)
+
+// DefaultMaxAttempts is how often a job is tried unless enqueued with MaxAttempts.
+const DefaultMaxAttempts = 5
+
+// Job is one unit of background work. Payload is the JSON-encoded argument for the handler
+// registered for Kind. A UniqueKey is only unique among jobs that are still queued or running.
+type Job struct {
+	gorm.Model
+	Kind        string    `gorm:"type:varchar(100);index"`
+	Payload     string    `gorm:"type:jsonb"`
+	Status      Status    `gorm:"type:varchar(10);index:idx_jobs_claim,priority:1"`
+	RunAt       time.Time `gorm:"index:idx_jobs_claim,priority:2"`
+	Attempts    int
+	MaxAttempts int
+	UniqueKey   *string `gorm:"type:varchar(200);uniqueIndex:idx_jobs_unique_key,where:status <> 'DONE' AND status <> 'DEAD'"`
+	LockedAt    *time.Time
+	LockedBy    string
+	LastError   string
+	FinishedAt  *time.Time
+}
+
+// Decode unmarshals the job's payload into v.
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal([]byte(j.Payload), v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s payload: %w", j.Kind, err))
+	}
+	return nil
+}
+
+// Option adjusts a job being enqueued.
+type Option func(*Job)
+
+// RunAt schedules the job for t instead of straight away.
+func RunAt(t time.Time) Option {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// Delay schedules the job d from now.
+func Delay(d time.Duration) Option {
+	return func(j *Job) { j.RunAt = time.Now().Add(d) }
+}
+
+// Unique makes Enqueue a no-op while another job with the same key is queued or running.
+func Unique(key string) Option {
+	return func(j *Job) { j.UniqueKey = &key }
+}
+
+// MaxAttempts overrides DefaultMaxAttempts.
+func MaxAttempts(n int) Option {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// Enqueue adds a job of kind with payload encoded as JSON. Pass a transaction as db to enqueue
+// atomically with other writes. For a Unique job that is already pending, the returned job has
+// no ID.
+func Enqueue(db *gorm.DB, kind string, payload any, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", kind, err)
+	}
+
+	job := Job{
+		Kind:        kind,
+		Payload:     string(data),
+		Status:      StatusQueued,
+		RunAt:       time.Now(),
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	query := db
+	if job.UniqueKey != nil {
+		query = query.Clauses(clause.OnConflict{DoNothing: true})
+	}
+	if err := query.Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue %s job: %w", kind, err)
+	}
+	return &job, nil
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered straight away instead of retried.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Retention is how long finished jobs are kept, for inspection, after which the KindPrune job
+// deletes them.
+const Retention = 30 * 24 * time.Hour
+
+// KindPrune is the job that runs Prune with Retention.
+const KindPrune = "jobs.prune"
+
+// Prune deletes the jobs that finished, done or dead, more than olderThan ago.
+func Prune(db *gorm.DB, olderThan time.Duration) (int64, error) {
+	res := db.Unscoped().
+		Where("status IN ? AND finished_at < ?", []Status{StatusDone, StatusDead}, time.Now().Add(-olderThan)).
+		Delete(&Job{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to prune jobs: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// PruneHandler returns the KindPrune job handler.
+func PruneHandler(db *gorm.DB) Handler {
+	return func(ctx context.Context, job *Job) error {
+		_, err := Prune(db.WithContext(ctx), Retention)
+		return err
+	}
+}
+
+// Backoff is how long to wait before retrying a job that has failed attempts times: 30 seconds
+// doubling with each attempt, capped at an hour.
+func Backoff(attempts int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScheduledRun remembers the last time slot a schedule was enqueued for, so that only one of the
+// schedulers running on every replica enqueues each run.
+type ScheduledRun struct {
+	Name      string `gorm:"primaryKey;type:varchar(100)"`
+	LastRunAt time.Time
+}
+
+type schedule struct {
+	name    string
+	cron    *Cron
+	kind    string
+	payload any
+}
+
+// Scheduler enqueues jobs on cron schedules. Times are matched in UTC.
+type Scheduler struct {
+	DB     *gorm.DB
+	Logger *zerolog.Logger
+
+	schedules []schedule
+}
+
+func NewScheduler(db *gorm.DB, logger *zerolog.Logger) *Scheduler {
+	return &Scheduler{DB: db, Logger: logger}
+}
+
+// Add enqueues a job of kind with payload whenever spec matches. name identifies the schedule
+// across replicas and restarts; it is usually the job kind.
+func (s *Scheduler) Add(name, spec, kind string, payload any) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	s.schedules = append(s.schedules, schedule{name: name, cron: cron, kind: kind, payload: payload})
+	return nil
+}
+
+// Run checks the schedules at the start of every minute until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+		s.tick(next)
+	}
+}
+
+func (s *Scheduler) tick(slot time.Time) {
+	for _, sched := range s.schedules {
+		if !sched.cron.Matches(slot) {
+			continue
+		}
+		enqueued, err := s.enqueue(sched, slot)
+		if err != nil {
+			s.Logger.Error().Err(err).Str("schedule", sched.name).Msg("Enqueueing scheduled job failed")
+		} else if enqueued {
+			s.Logger.Debug().Str("schedule", sched.name).Time("slot", slot).Msg("Scheduled job enqueued")
+		}
+	}
+}
+
+// enqueue claims slot for sched and enqueues its job in the same transaction. It does nothing
+// when another replica already claimed the slot.
+func (s *Scheduler) enqueue(sched schedule, slot time.Time) (enqueued bool, err error) {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_run_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "scheduled_runs.last_run_at < excluded.last_run_at"},
+			}},
+		}).Create(&ScheduledRun{Name: sched.name, LastRunAt: slot})
+		if res.Error != nil {
+			return fmt.Errorf("failed to claim schedule slot: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if _, err := Enqueue(tx, sched.kind, sched.payload, Unique(sched.name)); err != nil {
+			return err
+		}
+		enqueued = true
+		return nil
+	})
+	return enqueued, err
+}
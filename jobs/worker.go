@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler runs one job. Returning an error retries the job with Backoff, unless it is wrapped
+// with Permanent or the job is out of attempts, in which case it is dead-lettered.
+type Handler func(ctx context.Context, job *Job) error
+
+// Worker claims and runs jobs for the kinds registered with it.
+type Worker struct {
+	DB     *gorm.DB
+	Logger *zerolog.Logger
+
+	// Concurrency is how many jobs run at once.
+	Concurrency int
+	// PollInterval is how long an idle worker waits before looking for jobs again.
+	PollInterval time.Duration
+	// Timeout bounds a single run of a job. A job still marked running LockTimeout after it was
+	// claimed is assumed to have lost its worker and is handed out again.
+	Timeout     time.Duration
+	LockTimeout time.Duration
+
+	id       string
+	handlers map[string]Handler
+}
+
+// NewWorker returns a worker configured from JOBS_CONCURRENCY (default 4) and
+// JOBS_POLL_INTERVAL (default 1s).
+func NewWorker(db *gorm.DB, logger *zerolog.Logger) (*Worker, error) {
+	w := &Worker{
+		DB:           db,
+		Logger:       logger,
+		Concurrency:  4,
+		PollInterval: time.Second,
+		Timeout:      5 * time.Minute,
+		LockTimeout:  15 * time.Minute,
+		handlers:     make(map[string]Handler),
+	}
+	if v := os.Getenv("JOBS_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid JOBS_CONCURRENCY %q", v)
+		}
+		w.Concurrency = n
+	}
+	if v := os.Getenv("JOBS_POLL_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JOBS_POLL_INTERVAL: %w", err)
+		}
+		w.PollInterval = d
+	}
+
+	host, _ := os.Hostname()
+	w.id = fmt.Sprintf("%s-%d", host, os.Getpid())
+	return w, nil
+}
+
+// Register sets the handler for jobs of kind. Call it before Run.
+func (w *Worker) Register(kind string, handler Handler) {
+	w.handlers[kind] = handler
+}
+
+// Run works through jobs until ctx is cancelled, then waits for the jobs in progress to finish.
+func (w *Worker) Run(ctx context.Context) {
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+	w.Logger.Info().Str("worker", w.id).Int("concurrency", w.Concurrency).Strs("kinds", kinds).Msg("Job worker started")
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				ran, err := w.runNext(ctx, kinds)
+				if err != nil {
+					w.Logger.Error().Err(err).Msg("Claiming job failed")
+				}
+				if ran {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(w.PollInterval):
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	w.Logger.Info().Str("worker", w.id).Msg("Job worker stopped")
+}
+
+// runNext claims and runs one job. ran is false when there was nothing to do.
+func (w *Worker) runNext(ctx context.Context, kinds []string) (ran bool, err error) {
+	job, err := w.claim(kinds, time.Now())
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// Jobs already claimed are finished even while shutting down
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.Timeout)
+	defer cancel()
+	start := time.Now()
+	runErr := w.run(runCtx, job)
+
+	log := w.Logger.With().Uint("job_id", job.ID).Str("kind", job.Kind).Int("attempt", job.Attempts).Dur("duration", time.Since(start)).Logger()
+	if err := w.finish(job, runErr, time.Now()); err != nil {
+		return true, err
+	}
+	switch {
+	case runErr == nil:
+		log.Debug().Msg("Job done")
+	case job.Status == StatusDead:
+		log.Error().Err(runErr).Msg("Job failed permanently and was dead-lettered")
+	default:
+		log.Warn().Err(runErr).Time("retry_at", job.RunAt).Msg("Job failed, will retry")
+	}
+	return true, nil
+}
+
+// claim locks the next due job, including ones whose worker went away, and marks it running.
+func (w *Worker) claim(kinds []string, now time.Time) (*Job, error) {
+	var job Job
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("kind IN ?", kinds).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				StatusQueued, now, StatusRunning, now.Add(-w.LockTimeout)).
+			Order("run_at").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+		job.Status = StatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		job.LockedBy = w.id
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":    job.Status,
+			"attempts":  job.Attempts,
+			"locked_at": now,
+			"locked_by": w.id,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+// run calls the job's handler, turning a panic into an error.
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for %s", job.Kind))
+	}
+	return handler(ctx, job)
+}
+
+// finish records the outcome of a run: done, queued again after a backoff, or dead.
+func (w *Worker) finish(job *Job, runErr error, now time.Time) error {
+	updates := map[string]interface{}{"locked_at": nil, "locked_by": ""}
+	switch {
+	case runErr == nil:
+		job.Status = StatusDone
+		updates["finished_at"] = now
+		updates["last_error"] = ""
+	case isPermanent(runErr) || job.Attempts >= job.MaxAttempts:
+		job.Status = StatusDead
+		updates["finished_at"] = now
+		updates["last_error"] = runErr.Error()
+	default:
+		job.Status = StatusQueued
+		job.RunAt = now.Add(Backoff(job.Attempts))
+		updates["run_at"] = job.RunAt
+		updates["last_error"] = runErr.Error()
+	}
+	updates["status"] = job.Status
+
+	// Another worker may have reclaimed a job that overran LockTimeout; its outcome wins
+	if err := w.DB.Model(&Job{}).
+		Where("id = ? AND locked_by = ? AND attempts = ?", job.ID, w.id, job.Attempts).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record job %d outcome: %w", job.ID, err)
+	}
+	return nil
+}
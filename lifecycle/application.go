@@ -0,0 +1,138 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+type applicationTransition struct {
+	from, to model.ApplicationStatus
+}
+
+// applicationTransitions lists the allowed application status changes. Who may make them is
+// checked by services.ApplicationService: volunteers withdraw, nonprofit members and platform
+// admins decide. A withdrawal is final; nobody else may put a volunteer's application back.
+var applicationTransitions = map[applicationTransition]bool{
+	{model.Pending, model.Accepted}:   true,
+	{model.Pending, model.Rejected}:   true,
+	{model.Pending, model.Withdrawn}:  true,
+	{model.Pending, model.Waitlisted}: true, // accepted past capacity, see AcceptOrWaitlist
+	{model.Accepted, model.Withdrawn}: true,
+	{model.Rejected, model.Pending}:   true, // reopened for reconsideration
+	{model.Pending, model.Expired}:    true, // left undecided too long
+	{model.Expired, model.Pending}:    true,
+
+	{model.Waitlisted, model.Accepted}:  true, // promoted, see PromoteFromWaitlist
+	{model.Waitlisted, model.Rejected}:  true,
+	{model.Waitlisted, model.Withdrawn}: true,
+}
+
+// TransitionApplication validates and applies an application status change and records an
+// ApplicationStatusChanged event. DecidedAt is set for decisions and cleared when an application
+// is reopened. Run it inside a transaction.
+func TransitionApplication(tx *gorm.DB, application *model.Application, to model.ApplicationStatus) error {
+	return transitionApplication(tx, application, to, nil)
+}
+
+// transitionApplication is TransitionApplication, noting in the event when the project closing
+// is what changed the application.
+func transitionApplication(tx *gorm.DB, application *model.Application, to model.ApplicationStatus, projectClosed *model.ProjectStatus) error {
+	from := application.Status
+	if !applicationTransitions[applicationTransition{from, to}] {
+		return fmt.Errorf("invalid application status transition from %s to %s", from, to)
+	}
+
+	var decidedAt *time.Time
+	if to != model.Pending {
+		now := time.Now()
+		decidedAt = &now
+	}
+
+	res := tx.Model(&model.Application{}).
+		Where("id = ? AND status = ?", application.ID, from).
+		Updates(map[string]interface{}{"status": to, "decided_at": decidedAt})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update application: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("application status was changed concurrently, reload and try again")
+	}
+
+	application.Status = to
+	application.DecidedAt = decidedAt
+	return outbox.Record(tx, outbox.ApplicationStatusChanged, outbox.ApplicationPayload{
+		ApplicationID: application.ID,
+		ProjectID:     application.ProjectID,
+		VolunteerID:   application.VolunteerID,
+		From:          &from,
+		To:            to,
+		ProjectClosed: projectClosed,
+	})
+}
+
+// CloseOpenApplications rejects every application still PENDING or WAITLISTED on a project, used
+// once the project reaches COMPLETED or CANCELLED and can no longer take volunteers. The events
+// carry the project's status so the volunteers are told why.
+func CloseOpenApplications(tx *gorm.DB, project *model.Project) error {
+	var open []*model.Application
+	if err := tx.Where("project_id = ? AND status IN ?", project.ID, []model.ApplicationStatus{model.Pending, model.Waitlisted}).
+		Find(&open).Error; err != nil {
+		return fmt.Errorf("failed to fetch open applications: %w", err)
+	}
+	for _, application := range open {
+		if err := transitionApplication(tx, application, model.Rejected, &project.Status); err != nil {
+			return fmt.Errorf("failed to close open applications: %w", err)
+		}
+	}
+	return nil
+}
+
+// StartEngagement creates the active engagement for a volunteer on a project and moves an ACTIVE
+// project to IN_PROGRESS. The caller is responsible for checking the application was accepted.
+func StartEngagement(tx *gorm.DB, volunteerID, projectID uint) (*model.Engagement, error) {
+	// Check if an engagement already exists for this application/project/volunteer
+	var existing model.Engagement
+	err := tx.Where("volunteer_id = ? AND project_id = ?", volunteerID, projectID).First(&existing).Error
+	if err == nil {
+		return nil, errors.New("an engagement for this project and volunteer already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error checking existing engagement: %w", err)
+	}
+
+	engagement := model.Engagement{
+		VolunteerID: volunteerID,
+		ProjectID:   projectID,
+		StartDate:   time.Now(),
+		Status:      model.EngagementActive,
+	}
+	if err := tx.Create(&engagement).Error; err != nil {
+		return nil, fmt.Errorf("failed to start engagement: %w", err)
+	}
+	if err := outbox.Record(tx, outbox.EngagementStarted, outbox.EngagementPayload{
+		EngagementID: engagement.ID,
+		ProjectID:    projectID,
+		VolunteerID:  volunteerID,
+	}); err != nil {
+		return nil, err
+	}
+
+	// The first engagement moves an ACTIVE project to IN_PROGRESS. Projects in any other
+	// status are left alone rather than forced, and the change is recorded in the history.
+	var project model.Project
+	if err := tx.First(&project, projectID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if project.Status == model.Active {
+		reason := "first volunteer engagement started"
+		if err := TransitionProject(tx, &project, model.InProgress, ActorSystem, nil, &reason); err != nil {
+			return nil, err
+		}
+	}
+	return &engagement, nil
+}
@@ -0,0 +1,155 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProjectFull = errors.New("project is at capacity")
+
+// lockProject takes a row lock on the project so concurrent accepts and promotions on the same
+// project are serialised and cannot overfill it.
+func lockProject(tx *gorm.DB, projectID uint) (*model.Project, error) {
+	var project model.Project
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, projectID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	return &project, nil
+}
+
+// AcceptedCount returns how many applications are accepted on a project, or in one of its role
+// slots when slotID is set.
+func AcceptedCount(db *gorm.DB, projectID uint, slotID *uint) (int64, error) {
+	query := db.Model(&model.Application{}).Where("project_id = ? AND status = ?", projectID, model.Accepted)
+	if slotID != nil {
+		query = query.Where("slot_id = ?", *slotID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count accepted applications: %w", err)
+	}
+	return count, nil
+}
+
+// OpenSlots returns how many more volunteers a project can accept, or nil if it has no limit.
+func OpenSlots(db *gorm.DB, project *model.Project) (*int, error) {
+	if project.VolunteersNeeded <= 0 {
+		return nil, nil
+	}
+	accepted, err := AcceptedCount(db, project.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	open := project.VolunteersNeeded - int(accepted)
+	if open < 0 {
+		open = 0
+	}
+	return &open, nil
+}
+
+// hasCapacity reports whether one more volunteer fits in the project and, if given, the slot.
+// A slot that has since been removed from the project no longer limits anyone.
+func hasCapacity(tx *gorm.DB, project *model.Project, slotID *uint) (bool, error) {
+	if project.VolunteersNeeded > 0 {
+		accepted, err := AcceptedCount(tx, project.ID, nil)
+		if err != nil {
+			return false, err
+		}
+		if int(accepted) >= project.VolunteersNeeded {
+			return false, nil
+		}
+	}
+
+	if slotID != nil {
+		var slot model.ProjectRoleSlot
+		err := tx.Where("id = ? AND project_id = ?", *slotID, project.ID).First(&slot).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch role slot: %w", err)
+		}
+		accepted, err := AcceptedCount(tx, project.ID, slotID)
+		if err != nil {
+			return false, err
+		}
+		if int(accepted) >= slot.Capacity {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// AcceptOrWaitlist accepts a PENDING application if the project (and its role slot) has room,
+// and otherwise puts it on the project's waitlist. A WAITLISTED application is accepted only if
+// room has opened up, else ErrProjectFull. Run it inside a transaction.
+func AcceptOrWaitlist(tx *gorm.DB, application *model.Application) error {
+	project, err := lockProject(tx, application.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	fits, err := hasCapacity(tx, project, application.SlotID)
+	if err != nil {
+		return err
+	}
+	if fits {
+		return TransitionApplication(tx, application, model.Accepted)
+	}
+	if application.Status == model.Waitlisted {
+		return ErrProjectFull
+	}
+
+	if err := TransitionApplication(tx, application, model.Waitlisted); err != nil {
+		return err
+	}
+	now := time.Now()
+	application.WaitlistedAt = &now
+	return tx.Model(application).Update("waitlisted_at", now).Error
+}
+
+// PromoteFromWaitlist accepts waitlisted applications in waitlist order for as long as the
+// project has room, skipping applicants whose role slot is still full. It returns the promoted
+// applications. Call it whenever capacity frees up: withdrawals, cancelled engagements and
+// capacity increases.
+func PromoteFromWaitlist(tx *gorm.DB, projectID uint) ([]*model.Application, error) {
+	project, err := lockProject(tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	// Closed projects do not take volunteers, waitlisted or not
+	if project.Status != model.Active && project.Status != model.InProgress {
+		return nil, nil
+	}
+
+	var waitlist []*model.Application
+	if err := tx.Where("project_id = ? AND status = ?", projectID, model.Waitlisted).
+		Order("waitlisted_at ASC, id ASC").
+		Find(&waitlist).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+
+	var promoted []*model.Application
+	for _, application := range waitlist {
+		fits, err := hasCapacity(tx, project, application.SlotID)
+		if err != nil {
+			return nil, err
+		}
+		if !fits {
+			if application.SlotID == nil {
+				break // the project itself is full, nobody further down can fit either
+			}
+			continue
+		}
+		if err := TransitionApplication(tx, application, model.Accepted); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, application)
+	}
+	return promoted, nil
+}
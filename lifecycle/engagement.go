@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+var ErrEngagementNotActive = errors.New("engagement is no longer active")
+
+// endEngagement moves an active engagement to status, guarded on it still being ACTIVE so
+// concurrent callers get ErrEngagementNotActive rather than ending it twice.
+func endEngagement(tx *gorm.DB, engagement *model.Engagement, status model.EngagementStatus, extra map[string]interface{}) error {
+	now := time.Now()
+	updates := map[string]interface{}{"status": status, "end_date": now}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	res := tx.Model(&model.Engagement{}).
+		Where("id = ? AND status = ?", engagement.ID, model.EngagementActive).
+		Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("failed to end engagement: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrEngagementNotActive
+	}
+	engagement.Status = status
+	engagement.EndDate = &now
+	return nil
+}
+
+func engagementPayload(engagement *model.Engagement, by *model.User, reason *string) outbox.EngagementPayload {
+	payload := outbox.EngagementPayload{
+		EngagementID: engagement.ID,
+		ProjectID:    engagement.ProjectID,
+		VolunteerID:  engagement.VolunteerID,
+		Reason:       reason,
+	}
+	if by != nil {
+		payload.ByID = &by.ID
+	}
+	return payload
+}
+
+// CompleteEngagement completes an active engagement, storing the volunteer's feedback if given,
+// and records an EngagementCompleted event. completedBy is nil when the system completes it.
+func CompleteEngagement(tx *gorm.DB, engagement *model.Engagement, completedBy *model.User, feedback *string) error {
+	extra := map[string]interface{}{}
+	if feedback != nil {
+		now := time.Now()
+		extra["feedback"] = *feedback
+		extra["feedback_submitted_at"] = now
+		engagement.Feedback = feedback
+		engagement.FeedbackSubmittedAt = &now
+	}
+	if err := endEngagement(tx, engagement, model.EngagementCompleted, extra); err != nil {
+		return err
+	}
+	return outbox.Record(tx, outbox.EngagementCompleted, engagementPayload(engagement, completedBy, nil))
+}
+
+// CancelEngagement cancels an active engagement, withdraws the accepted application behind it and
+// promotes the next volunteer from the waitlist into the freed place. It records an
+// EngagementCancelled event; cancelledBy is nil when the system cancels it, and reason is passed
+// on to the volunteer.
+func CancelEngagement(tx *gorm.DB, engagement *model.Engagement, cancelledBy *model.User, reason *string) error {
+	if err := endEngagement(tx, engagement, model.EngagementCancelled, nil); err != nil {
+		return err
+	}
+	if err := outbox.Record(tx, outbox.EngagementCancelled, engagementPayload(engagement, cancelledBy, reason)); err != nil {
+		return err
+	}
+
+	var application model.Application
+	err := tx.Where("volunteer_id = ? AND project_id = ? AND status = ?",
+		engagement.VolunteerID, engagement.ProjectID, model.Accepted).
+		First(&application).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch application: %w", err)
+	}
+	if err := TransitionApplication(tx, &application, model.Withdrawn); err != nil {
+		return fmt.Errorf("failed to release application: %w", err)
+	}
+	_, err = PromoteFromWaitlist(tx, engagement.ProjectID)
+	return err
+}
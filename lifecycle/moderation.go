@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// ModerationPolicy controls the platform review of projects submitted by nonprofits.
+type ModerationPolicy struct {
+	// AutoApproveVerified skips the queue for projects from verified nonprofits.
+	AutoApproveVerified bool
+	// SLA is how long a project may wait in the queue before it is reported as overdue.
+	SLA time.Duration
+}
+
+// ModerationPolicyFromEnv reads MODERATION_AUTO_APPROVE ("verified", the default, or "off")
+// and MODERATION_SLA_HOURS (default 48).
+func ModerationPolicyFromEnv() ModerationPolicy {
+	policy := ModerationPolicy{
+		AutoApproveVerified: os.Getenv("MODERATION_AUTO_APPROVE") != "off",
+		SLA:                 48 * time.Hour,
+	}
+	if hours, err := strconv.Atoi(os.Getenv("MODERATION_SLA_HOURS")); err == nil && hours > 0 {
+		policy.SLA = time.Duration(hours) * time.Hour
+	}
+	return policy
+}
+
+// ShouldAutoApprove reports whether a project from nonprofit bypasses manual review.
+func (p ModerationPolicy) ShouldAutoApprove(nonprofit *model.Nonprofit) bool {
+	return p.AutoApproveVerified && nonprofit.Verified
+}
+
+// SubmitForReview moves a DRAFT project to PENDING_REVIEW and, when the policy allows it,
+// straight on to ACTIVE. Both steps are recorded in the status history. It reports whether the
+// project was auto-approved.
+func SubmitForReview(tx *gorm.DB, project *model.Project, actor Actor, submittedBy *model.User, policy ModerationPolicy) (bool, error) {
+	if err := TransitionProject(tx, project, model.PendingReview, actor, submittedBy, nil); err != nil {
+		return false, err
+	}
+
+	var nonprofit model.Nonprofit
+	if err := tx.First(&nonprofit, project.NonprofitID).Error; err != nil {
+		return false, fmt.Errorf("failed to fetch nonprofit: %w", err)
+	}
+	if !policy.ShouldAutoApprove(&nonprofit) {
+		return false, nil
+	}
+
+	reason := "auto-approved: submitted by a verified nonprofit"
+	if err := TransitionProject(tx, project, model.Active, ActorSystem, nil, &reason); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SubmittedAt returns when each of the given projects last entered PENDING_REVIEW. Projects
+// with no recorded submission are missing from the map.
+func SubmittedAt(db *gorm.DB, projectIDs []uint) (map[uint]time.Time, error) {
+	var rows []struct {
+		ProjectID   uint
+		SubmittedAt time.Time
+	}
+	if len(projectIDs) > 0 {
+		if err := db.Model(&model.ProjectStatusChange{}).
+			Select("project_id, MAX(created_at) AS submitted_at").
+			Where("project_id IN ? AND to_status = ?", projectIDs, model.PendingReview).
+			Group("project_id").
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch submission times: %w", err)
+		}
+	}
+
+	submitted := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		submitted[row.ProjectID] = row.SubmittedAt
+	}
+	return submitted, nil
+}
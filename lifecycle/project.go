@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+// Actor is the capacity in which someone changes a project's status.
+type Actor string
+
+const (
+	// ActorNonprofitMember is a member of the nonprofit that owns the project.
+	ActorNonprofitMember Actor = "NONPROFIT_MEMBER"
+	// ActorPlatformAdmin is a user with the PLATFORM_ADMIN role.
+	ActorPlatformAdmin Actor = "PLATFORM_ADMIN"
+	// ActorSystem is the API itself, e.g. moving a project to IN_PROGRESS when work starts.
+	ActorSystem Actor = "SYSTEM"
+)
+
+var ErrTransitionConflict = errors.New("project status was changed concurrently, reload and try again")
+
+type projectTransition struct {
+	from, to model.ProjectStatus
+}
+
+// projectTransitions lists every allowed status change and who may make it. Anything not listed
+// is rejected; COMPLETED and CANCELLED are terminal.
+var projectTransitions = map[projectTransition][]Actor{
+	{model.Draft, model.PendingReview}:     {ActorNonprofitMember, ActorPlatformAdmin},
+	{model.Draft, model.Cancelled}:         {ActorNonprofitMember, ActorPlatformAdmin},
+	{model.PendingReview, model.Active}:    {ActorPlatformAdmin, ActorSystem}, // system: auto-approval, see SubmitForReview
+	{model.PendingReview, model.Draft}:     {ActorNonprofitMember, ActorPlatformAdmin},
+	{model.PendingReview, model.Cancelled}: {ActorNonprofitMember, ActorPlatformAdmin},
+	{model.Active, model.InProgress}:       {ActorNonprofitMember, ActorPlatformAdmin, ActorSystem},
+	{model.Active, model.Completed}:        {ActorNonprofitMember, ActorPlatformAdmin, ActorSystem},
+	{model.Active, model.Cancelled}:        {ActorNonprofitMember, ActorPlatformAdmin},
+	{model.InProgress, model.Completed}:    {ActorNonprofitMember, ActorPlatformAdmin, ActorSystem},
+	{model.InProgress, model.Cancelled}:    {ActorNonprofitMember, ActorPlatformAdmin},
+}
+
+// CanTransitionProject reports whether actor may move a project from one status to another.
+func CanTransitionProject(from, to model.ProjectStatus, actor Actor) error {
+	actors, ok := projectTransitions[projectTransition{from, to}]
+	if !ok {
+		return fmt.Errorf("invalid status transition from %s to %s", from, to)
+	}
+	for _, a := range actors {
+		if a == actor {
+			return nil
+		}
+	}
+	return fmt.Errorf("unauthorized: %s cannot move a project from %s to %s", actor, from, to)
+}
+
+// TransitionProject validates and applies a status change, records it in the project's status
+// history and records a ProjectStatusChanged event. Run it inside a transaction together with any
+// related writes. changedBy is nil for ActorSystem changes.
+func TransitionProject(tx *gorm.DB, project *model.Project, to model.ProjectStatus, actor Actor, changedBy *model.User, reason *string) error {
+	from := project.Status
+	if err := CanTransitionProject(from, to, actor); err != nil {
+		return err
+	}
+
+	// Guard on the status we validated against so two concurrent changes cannot both succeed
+	res := tx.Model(&model.Project{}).
+		Where("id = ? AND status = ?", project.ID, from).
+		Update("status", to)
+	if res.Error != nil {
+		return fmt.Errorf("failed to change project status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrTransitionConflict
+	}
+	project.Status = to
+
+	if err := RecordProjectStatus(tx, project.ID, &from, to, changedBy, reason); err != nil {
+		return err
+	}
+	payload := outbox.ProjectPayload{ProjectID: project.ID, From: from, To: to, Reason: reason}
+	if changedBy != nil {
+		payload.ChangedByID = &changedBy.ID
+	}
+	if err := outbox.Record(tx, outbox.ProjectStatusChanged, payload); err != nil {
+		return err
+	}
+
+	// A finished project can no longer take volunteers
+	if to == model.Completed || to == model.Cancelled {
+		return CloseOpenApplications(tx, project)
+	}
+	return nil
+}
+
+// RecordProjectStatus appends an entry to a project's status history. from is nil for the
+// initial status set when the project is created.
+func RecordProjectStatus(tx *gorm.DB, projectID uint, from *model.ProjectStatus, to model.ProjectStatus, changedBy *model.User, reason *string) error {
+	change := model.ProjectStatusChange{
+		ProjectID:  projectID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+	}
+	if changedBy != nil {
+		change.ChangedByID = &changedBy.ID
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return fmt.Errorf("failed to record project status change: %w", err)
+	}
+	return nil
+}
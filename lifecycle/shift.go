@@ -0,0 +1,236 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrShiftFull    = errors.New("shift is full")
+	ErrShiftStarted = errors.New("shift has already started")
+)
+
+// ShiftFitsAvailability reports whether every day a shift touches, seen in the volunteer's own
+// timezone, is one of their available days. Volunteers who have not set their availability are
+// treated as available any day.
+func ShiftFitsAvailability(shift *model.Shift, availability model.Availability) bool {
+	if len(availability.DaysAvailable) == 0 {
+		return true
+	}
+	loc := time.UTC
+	if availability.Timezone != "" {
+		if l, err := time.LoadLocation(availability.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	available := make(map[model.Weekday]bool, len(availability.DaysAvailable))
+	for _, day := range availability.DaysAvailable {
+		available[day] = true
+	}
+	// A shift ending exactly at midnight does not touch the following day
+	last := shift.EndsAt.Add(-time.Nanosecond).In(loc)
+	for day := shift.StartsAt.In(loc); ; day = day.AddDate(0, 0, 1) {
+		if !available[weekday(day.Weekday())] {
+			return false
+		}
+		if sameDay(day, last) || day.After(last) {
+			return true
+		}
+	}
+}
+
+func weekday(d time.Weekday) model.Weekday {
+	return [...]model.Weekday{model.Sunday, model.Monday, model.Tuesday, model.Wednesday,
+		model.Thursday, model.Friday, model.Saturday}[d]
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// ShiftSignups returns how many volunteers are signed up for a shift.
+func ShiftSignups(db *gorm.DB, shiftID uint) (int64, error) {
+	var count int64
+	if err := db.Model(&model.ShiftSignup{}).
+		Where("shift_id = ? AND cancelled_at IS NULL", shiftID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count shift signups: %w", err)
+	}
+	return count, nil
+}
+
+// SignUpForShift puts a volunteer on a shift. The volunteer must be accepted on the project; their
+// engagement is started if this is their first shift. Run it inside a transaction.
+func SignUpForShift(tx *gorm.DB, shiftID uint, volunteer *model.User) (*model.ShiftSignup, error) {
+	// Lock the shift so concurrent signups cannot overfill it
+	var shift model.Shift
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shift, shiftID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch shift: %w", err)
+	}
+	if !shift.StartsAt.After(time.Now()) {
+		return nil, ErrShiftStarted
+	}
+
+	var application model.Application
+	if err := tx.Where("volunteer_id = ? AND project_id = ? AND status = ?", volunteer.ID, shift.ProjectID, model.Accepted).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("you must be accepted on the project to sign up for its shifts")
+		}
+		return nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+
+	if !ShiftFitsAvailability(&shift, volunteer.Availability) {
+		return nil, errors.New("shift falls outside your available days")
+	}
+
+	var existing int64
+	if err := tx.Model(&model.ShiftSignup{}).
+		Joins("JOIN shifts ON shifts.id = shift_signups.shift_id").
+		Where("shift_signups.volunteer_id = ? AND shift_signups.cancelled_at IS NULL AND shifts.deleted_at IS NULL", volunteer.ID).
+		Where("shifts.id = ? OR (shifts.starts_at < ? AND shifts.ends_at > ?)", shift.ID, shift.EndsAt, shift.StartsAt).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing signups: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("you are already signed up for this or an overlapping shift")
+	}
+
+	signups, err := ShiftSignups(tx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	if int(signups) >= shift.Capacity {
+		return nil, ErrShiftFull
+	}
+
+	engagement, err := activeEngagement(tx, volunteer.ID, shift.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	signup := model.ShiftSignup{ShiftID: shift.ID, VolunteerID: volunteer.ID, EngagementID: engagement.ID}
+	if err := tx.Create(&signup).Error; err != nil {
+		return nil, fmt.Errorf("failed to sign up for shift: %w", err)
+	}
+	return &signup, nil
+}
+
+// activeEngagement returns the volunteer's active engagement on a project, starting one if they
+// have never had one.
+func activeEngagement(tx *gorm.DB, volunteerID, projectID uint) (*model.Engagement, error) {
+	var engagement model.Engagement
+	err := tx.Where("volunteer_id = ? AND project_id = ?", volunteerID, projectID).First(&engagement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return StartEngagement(tx, volunteerID, projectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error checking existing engagement: %w", err)
+	}
+	if engagement.Status != model.EngagementActive {
+		return nil, fmt.Errorf("your engagement on this project is %s", engagement.Status)
+	}
+	return &engagement, nil
+}
+
+// CancelShiftSignup takes a volunteer off a shift that has not started yet.
+func CancelShiftSignup(db *gorm.DB, shiftID, volunteerID uint) error {
+	var shift model.Shift
+	if err := db.First(&shift, shiftID).Error; err != nil {
+		return fmt.Errorf("failed to fetch shift: %w", err)
+	}
+	if !shift.StartsAt.After(time.Now()) {
+		return ErrShiftStarted
+	}
+
+	res := db.Model(&model.ShiftSignup{}).
+		Where("shift_id = ? AND volunteer_id = ? AND cancelled_at IS NULL", shiftID, volunteerID).
+		Update("cancelled_at", time.Now())
+	if res.Error != nil {
+		return fmt.Errorf("failed to cancel shift signup: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("you are not signed up for this shift")
+	}
+	return nil
+}
+
+// KindPrefillShiftHours is the job that runs PrefillShiftHours.
+const KindPrefillShiftHours = "lifecycle.prefill_shift_hours"
+
+// PrefillShiftHours creates an unapproved HoursLogged entry for every volunteer signed up to a
+// shift that has ended, so they only have to confirm it. Each shift is handled once; rows are
+// claimed with SKIP LOCKED so several replicas can run this concurrently. A shift that fails is
+// logged and left for the next run rather than holding up the others. It returns the number of
+// shifts processed.
+func PrefillShiftHours(db *gorm.DB, logger *zerolog.Logger, now time.Time) (int, error) {
+	processed := 0
+	var failed []uint
+	for {
+		var shift model.Shift
+		claimed := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("ends_at <= ? AND hours_prefilled_at IS NULL", now)
+			if len(failed) > 0 {
+				query = query.Where("id NOT IN ?", failed)
+			}
+			err := query.Order("ends_at ASC").First(&shift).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to fetch ended shift: %w", err)
+			}
+			claimed = true
+			return prefillShift(tx, &shift, now)
+		})
+		if !claimed {
+			return processed, err
+		}
+		if err != nil {
+			logger.Error().Err(err).Uint("shift_id", shift.ID).Msg("Failed to pre-fill hours for shift")
+			failed = append(failed, shift.ID)
+			continue
+		}
+		processed++
+	}
+}
+
+// prefillShift logs the hours of everyone still signed up to shift and marks it done.
+func prefillShift(tx *gorm.DB, shift *model.Shift, now time.Time) error {
+	var signups []model.ShiftSignup
+	if err := tx.Where("shift_id = ? AND cancelled_at IS NULL", shift.ID).Find(&signups).Error; err != nil {
+		return fmt.Errorf("failed to fetch shift signups: %w", err)
+	}
+	hours := shift.EndsAt.Sub(shift.StartsAt).Hours()
+	description := "Shift"
+	if shift.Location != "" {
+		description += " at " + shift.Location
+	}
+	for _, signup := range signups {
+		entry := model.HoursLogged{
+			EngagementID: signup.EngagementID,
+			Date:         shift.StartsAt,
+			Hours:        hours,
+			Description:  &description,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to prefill hours: %w", err)
+		}
+		if err := outbox.Record(tx, outbox.HoursLogged, outbox.HoursPayload{HoursID: entry.ID, EngagementID: entry.EngagementID}); err != nil {
+			return err
+		}
+	}
+	return tx.Model(shift).Update("hours_prefilled_at", now).Error
+}
@@ -0,0 +1,57 @@
+// Package mailer sends transactional email.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// Message is a single email. HTML is optional; Text is always sent as the plain-text part.
+// Headers adds extra headers such as List-Unsubscribe.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer delivers email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv builds the Mailer selected by MAILER_DRIVER ("log", the default, or "smtp").
+func NewFromEnv(logger *zerolog.Logger) (Mailer, error) {
+	switch driver := os.Getenv("MAILER_DRIVER"); driver {
+	case "", "log":
+		return &LogMailer{Logger: logger}, nil
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown MAILER_DRIVER %q", driver)
+	}
+}
+
+// LogMailer writes email to the log instead of sending it. Meant for development.
+type LogMailer struct {
+	Logger *zerolog.Logger
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.Logger.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Text).
+		Msg("Email not sent (MAILER_DRIVER=log)")
+	return nil
+}
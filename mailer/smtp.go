@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"sort"
+	"time"
+)
+
+// SMTPConfig configures an SMTPMailer. Port defaults to 587; the connection is upgraded with
+// STARTTLS when the server offers it.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends email through an SMTP relay.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, errors.New("SMTP_HOST and MAIL_FROM are required for the smtp mailer")
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &SMTPMailer{cfg: cfg}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support, so the deadline only bounds how long we wait for it
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{msg.To}, body)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// build renders msg as a MIME message, multipart/alternative when it has an HTML part.
+func (m *SMTPMailer) build(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	names := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, msg.Headers[name])
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate MIME boundary: %w", err)
+	}
+	boundary := hex.EncodeToString(b[:])
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, part.contentType)
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, s string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return w.Close()
+}
@@ -0,0 +1,25 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"gorm.io/gorm"
+)
+
+// ExpireStaleApplications expires applications left PENDING for longer than the policy allows.
+func (m *Maintenance) ExpireStaleApplications(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-m.Policy.ApplicationExpiry)
+	var application model.Application
+	return m.each(ctx, "application",
+		func(tx *gorm.DB) (uint, error) {
+			application = model.Application{}
+			err := first(tx.Where("status = ? AND applied_at < ?", model.Pending, cutoff), &application)
+			return application.ID, err
+		},
+		func(tx *gorm.DB) error {
+			return lifecycle.TransitionApplication(tx, &application, model.Expired)
+		})
+}
@@ -0,0 +1,103 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+// lastActivity is when hours were last logged on an engagement, or when it started if never.
+// GREATEST ignores the NULL of an engagement without hours.
+const lastActivity = `GREATEST(engagements.start_date,
+	(SELECT MAX(h.created_at) FROM hours_loggeds h WHERE h.engagement_id = engagements.id AND h.deleted_at IS NULL))`
+
+// HandleIdleEngagements nudges volunteers on engagements with no hours logged for a while, once
+// per idle stretch, and cancels engagements that stayed idle for long after the nudge. It returns
+// the number of engagements nudged or cancelled.
+func (m *Maintenance) HandleIdleEngagements(ctx context.Context, now time.Time) (int, error) {
+	cancelled, err := m.cancelIdleEngagements(ctx, now)
+	if err != nil {
+		return cancelled, err
+	}
+	nudged, err := m.nudgeIdleEngagements(ctx, now)
+	return cancelled + nudged, err
+}
+
+func (m *Maintenance) nudgeIdleEngagements(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-m.Policy.IdleNudgeAfter)
+	var engagement model.Engagement
+	return m.each(ctx, "engagement",
+		func(tx *gorm.DB) (uint, error) {
+			engagement = model.Engagement{}
+			err := first(tx.Preload("Project").
+				Where("status = ?", model.EngagementActive).
+				Where(lastActivity+" < ?", cutoff).
+				Where("idle_nudged_at IS NULL OR idle_nudged_at < "+lastActivity), &engagement)
+			return engagement.ID, err
+		},
+		func(tx *gorm.DB) error {
+			if err := tx.Model(&engagement).Update("idle_nudged_at", now).Error; err != nil {
+				return fmt.Errorf("failed to nudge engagement: %w", err)
+			}
+			return outbox.Record(tx, outbox.EngagementIdle, systemPayload(&engagement))
+		})
+}
+
+func (m *Maintenance) cancelIdleEngagements(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-m.Policy.IdleCancelAfter)
+	noticeCutoff := now.Add(-m.Policy.CancelNotice)
+	var engagement model.Engagement
+	return m.each(ctx, "engagement",
+		func(tx *gorm.DB) (uint, error) {
+			engagement = model.Engagement{}
+			// Only engagements nudged since they went idle, and long enough ago to have reacted
+			err := first(tx.Preload("Project").
+				Where("status = ?", model.EngagementActive).
+				Where(lastActivity+" < ?", cutoff).
+				Where("idle_nudged_at > "+lastActivity).
+				Where("idle_nudged_at < ?", noticeCutoff), &engagement)
+			return engagement.ID, err
+		},
+		func(tx *gorm.DB) error {
+			reason := "No hours were logged for a long time, so the engagement was closed."
+			return lifecycle.CancelEngagement(tx, &engagement, nil, &reason)
+		})
+}
+
+// SendHoursReminders reminds volunteers on engagements with no hours logged lately to log them,
+// at most once per reminder interval. Engagements idle long enough to be nudged are left to
+// HandleIdleEngagements.
+func (m *Maintenance) SendHoursReminders(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-m.Policy.HoursReminderInterval)
+	idleCutoff := now.Add(-m.Policy.IdleNudgeAfter)
+	var engagement model.Engagement
+	return m.each(ctx, "engagement",
+		func(tx *gorm.DB) (uint, error) {
+			engagement = model.Engagement{}
+			err := first(tx.Preload("Project").
+				Where("status = ?", model.EngagementActive).
+				Where(lastActivity+" BETWEEN ? AND ?", idleCutoff, cutoff).
+				Where("hours_reminded_at IS NULL OR hours_reminded_at < ?", cutoff), &engagement)
+			return engagement.ID, err
+		},
+		func(tx *gorm.DB) error {
+			if err := tx.Model(&engagement).Update("hours_reminded_at", now).Error; err != nil {
+				return fmt.Errorf("failed to remind engagement: %w", err)
+			}
+			return outbox.Record(tx, outbox.HoursReminderDue, systemPayload(&engagement))
+		})
+}
+
+// systemPayload describes an event the system raised about engagement.
+func systemPayload(engagement *model.Engagement) outbox.EngagementPayload {
+	return outbox.EngagementPayload{
+		EngagementID: engagement.ID,
+		ProjectID:    engagement.ProjectID,
+		VolunteerID:  engagement.VolunteerID,
+	}
+}
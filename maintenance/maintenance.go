@@ -0,0 +1,149 @@
+// Package maintenance holds the scheduled jobs that clean up after users: completing projects
+// past their end date, nudging and then cancelling idle engagements, expiring applications nobody
+// decided on and reminding volunteers to log their hours.
+//
+// Every job claims one row at a time with SKIP LOCKED and changes it so that it no longer
+// matches, so a job can be run again, or on several replicas at once, without doing anything
+// twice. Every change records its domain events in the outbox, which notifies the volunteers and
+// webhooks once it is committed.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Job kinds, see Register.
+const (
+	KindCompleteEndedProjects   = "maintenance.complete_ended_projects"
+	KindIdleEngagements         = "maintenance.idle_engagements"
+	KindExpireStaleApplications = "maintenance.expire_stale_applications"
+	KindHoursReminders          = "maintenance.hours_reminders"
+)
+
+const day = 24 * time.Hour
+
+// Policy holds the thresholds the maintenance jobs work with.
+type Policy struct {
+	// ProjectGracePeriod is how long after its end date a project is completed.
+	ProjectGracePeriod time.Duration
+	// IdleNudgeAfter is how long an engagement can go without hours before the volunteer is nudged.
+	IdleNudgeAfter time.Duration
+	// IdleCancelAfter is how long an engagement can go without hours before it is cancelled. The
+	// volunteer must have been nudged at least CancelNotice earlier.
+	IdleCancelAfter time.Duration
+	CancelNotice    time.Duration
+	// ApplicationExpiry is how long an application may stay PENDING.
+	ApplicationExpiry time.Duration
+	// HoursReminderInterval is how often volunteers who haven't logged hours are reminded.
+	HoursReminderInterval time.Duration
+}
+
+// PolicyFromEnv reads MAINTENANCE_PROJECT_GRACE_DAYS (default 7), MAINTENANCE_IDLE_NUDGE_DAYS
+// (30), MAINTENANCE_IDLE_CANCEL_DAYS (90), MAINTENANCE_CANCEL_NOTICE_DAYS (7),
+// MAINTENANCE_APPLICATION_EXPIRY_DAYS (30) and MAINTENANCE_HOURS_REMINDER_DAYS (7).
+func PolicyFromEnv() Policy {
+	return Policy{
+		ProjectGracePeriod:    envDays("MAINTENANCE_PROJECT_GRACE_DAYS", 7),
+		IdleNudgeAfter:        envDays("MAINTENANCE_IDLE_NUDGE_DAYS", 30),
+		IdleCancelAfter:       envDays("MAINTENANCE_IDLE_CANCEL_DAYS", 90),
+		CancelNotice:          envDays("MAINTENANCE_CANCEL_NOTICE_DAYS", 7),
+		ApplicationExpiry:     envDays("MAINTENANCE_APPLICATION_EXPIRY_DAYS", 30),
+		HoursReminderInterval: envDays("MAINTENANCE_HOURS_REMINDER_DAYS", 7),
+	}
+}
+
+func envDays(key string, fallback int) time.Duration {
+	if days, err := strconv.Atoi(os.Getenv(key)); err == nil && days > 0 {
+		return time.Duration(days) * day
+	}
+	return time.Duration(fallback) * day
+}
+
+// Maintenance runs the maintenance jobs.
+type Maintenance struct {
+	DB     *gorm.DB
+	Logger *zerolog.Logger
+	Policy Policy
+}
+
+func New(db *gorm.DB, logger *zerolog.Logger) *Maintenance {
+	return &Maintenance{DB: db, Logger: logger, Policy: PolicyFromEnv()}
+}
+
+// Register makes w run the maintenance jobs.
+func (m *Maintenance) Register(w *jobs.Worker) {
+	w.Register(KindCompleteEndedProjects, m.handler(m.CompleteEndedProjects))
+	w.Register(KindIdleEngagements, m.handler(m.HandleIdleEngagements))
+	w.Register(KindExpireStaleApplications, m.handler(m.ExpireStaleApplications))
+	w.Register(KindHoursReminders, m.handler(m.SendHoursReminders))
+}
+
+func (m *Maintenance) handler(run func(ctx context.Context, now time.Time) (int, error)) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) error {
+		n, err := run(ctx, time.Now())
+		if n > 0 {
+			m.Logger.Info().Str("kind", job.Kind).Int("processed", n).Msg("Maintenance job finished")
+		}
+		return err
+	}
+}
+
+var skipLocked = clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}
+
+// each claims the rows claim matches one at a time and runs process on each in its own
+// transaction. process must change the row so that claim no longer matches it. claim returns the
+// ID of the row it locked, or 0 once there are none left; its tx already leaves out rows that
+// failed earlier in the run. A row that fails is logged and skipped, so one bad row cannot hold
+// up the rest; it is tried again on the next run. It returns the number of rows processed.
+func (m *Maintenance) each(ctx context.Context, what string, claim func(tx *gorm.DB) (uint, error), process func(tx *gorm.DB) error) (int, error) {
+	processed := 0
+	var failed []uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+		var id uint
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if len(failed) > 0 {
+				tx = tx.Where("id NOT IN ?", failed)
+			}
+			var err error
+			if id, err = claim(tx); err != nil || id == 0 {
+				return err
+			}
+			return process(tx.Session(&gorm.Session{NewDB: true}))
+		})
+		if id == 0 {
+			return processed, err
+		}
+		if err != nil {
+			m.Logger.Error().Err(err).Uint(what+"_id", id).Msg("Maintenance failed on a row, skipping it")
+			failed = append(failed, id)
+			continue
+		}
+		processed++
+	}
+}
+
+// first locks the first row matching query into dest, skipping rows locked by another replica.
+// Finding none is not an error.
+func first(query *gorm.DB, dest interface{}) error {
+	err := query.Clauses(skipLocked).First(dest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim row: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"gorm.io/gorm"
+)
+
+// CompleteEndedProjects moves ACTIVE and IN_PROGRESS projects to COMPLETED once their end date is
+// more than the grace period ago, completing their active engagements too. Open applications are
+// closed by the transition.
+func (m *Maintenance) CompleteEndedProjects(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-m.Policy.ProjectGracePeriod)
+	var project model.Project
+	return m.each(ctx, "project",
+		func(tx *gorm.DB) (uint, error) {
+			project = model.Project{}
+			err := first(tx.Where("status IN ? AND end_date < ?", []model.ProjectStatus{model.Active, model.InProgress}, cutoff), &project)
+			return project.ID, err
+		},
+		func(tx *gorm.DB) error {
+			reason := "The project's end date has passed."
+			if err := lifecycle.TransitionProject(tx, &project, model.Completed, lifecycle.ActorSystem, nil, &reason); err != nil {
+				return err
+			}
+			var engagements []*model.Engagement
+			if err := tx.Where("project_id = ? AND status = ?", project.ID, model.EngagementActive).Find(&engagements).Error; err != nil {
+				return fmt.Errorf("failed to fetch engagements: %w", err)
+			}
+			for _, engagement := range engagements {
+				if err := lifecycle.CompleteEngagement(tx, engagement, nil, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
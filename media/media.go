@@ -0,0 +1,151 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/google/uuid"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/storage"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrInvalidImage wraps every rejection caused by the uploaded bytes themselves, as opposed to
+// storage failures, so callers can report it as a validation error on the upload field.
+var ErrInvalidImage = errors.New("invalid image")
+
+// Purpose holds the limits and output shape for one kind of upload.
+type Purpose struct {
+	Dir       string
+	MaxBytes  int64
+	MaxPixels int // width and height limit, checked before decoding to avoid decompression bombs
+	MinPixels int
+	// Square crops variants to the centre square (avatars); otherwise they keep their aspect ratio.
+	Square bool
+}
+
+var (
+	Avatar = Purpose{Dir: "avatars", MaxBytes: 5 << 20, MaxPixels: 6000, MinPixels: 32, Square: true}
+	Logo   = Purpose{Dir: "nonprofit_logos", MaxBytes: 5 << 20, MaxPixels: 6000, MinPixels: 32}
+)
+
+// Pixel sizes generated for every upload. The longest side of each variant is at most this.
+var sizes = map[model.ImageSize]int{
+	model.ImageSmall:  64,
+	model.ImageMedium: 256,
+	model.ImageLarge:  1024,
+}
+
+var formats = []model.ImageFormat{model.ImagePng, model.ImageWebp}
+
+var allowedContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ProcessUpload validates an uploaded image, normalises it and stores every size/format variant.
+// It returns the base key to persist (e.g. "avatars/<uuid>"); use VariantKey to address a variant.
+// Re-encoding from decoded pixels drops EXIF and any other metadata the client sent.
+func ProcessUpload(ctx context.Context, store storage.Store, upload *model.Upload, purpose Purpose) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(upload.File, purpose.MaxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > purpose.MaxBytes {
+		return "", fmt.Errorf("%w: file must be at most %d MB", ErrInvalidImage, purpose.MaxBytes>>20)
+	}
+
+	// Trust the bytes, not the client's filename or Content-Type
+	if ct := http.DetectContentType(data); !allowedContentTypes[ct] {
+		return "", fmt.Errorf("%w: unsupported file type %s, expected PNG, JPEG, GIF or WebP", ErrInvalidImage, ct)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width > purpose.MaxPixels || cfg.Height > purpose.MaxPixels {
+		return "", fmt.Errorf("%w: image must be at most %dx%d pixels", ErrInvalidImage, purpose.MaxPixels, purpose.MaxPixels)
+	}
+	if cfg.Width < purpose.MinPixels || cfg.Height < purpose.MinPixels {
+		return "", fmt.Errorf("%w: image must be at least %dx%d pixels", ErrInvalidImage, purpose.MinPixels, purpose.MinPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	// Phones store rotation as EXIF metadata; bake it into the pixels before we discard it.
+	img = applyOrientation(img, jpegOrientation(data))
+
+	baseKey := path.Join(purpose.Dir, uuid.New().String())
+	for size, px := range sizes {
+		variant := resize(img, px, purpose.Square)
+		for _, format := range formats {
+			var buf bytes.Buffer
+			if err := encode(&buf, variant, format); err != nil {
+				return "", fmt.Errorf("failed to encode %s variant: %w", strings.ToLower(string(format)), err)
+			}
+			key := VariantKey(baseKey, size, format)
+			if err := store.Put(ctx, key, &buf, int64(buf.Len()), contentType(format)); err != nil {
+				return "", fmt.Errorf("failed to store image: %w", err)
+			}
+		}
+	}
+	return baseKey, nil
+}
+
+// VariantKey returns the storage key of one variant. Keys written before variants existed
+// point at a single file (they have an extension) and are returned unchanged.
+func VariantKey(baseKey string, size model.ImageSize, format model.ImageFormat) string {
+	if baseKey == "" || path.Ext(baseKey) != "" || strings.Contains(baseKey, "://") {
+		return baseKey
+	}
+	px, ok := sizes[size]
+	if !ok {
+		px = sizes[model.ImageMedium]
+	}
+	return fmt.Sprintf("%s/%d.%s", baseKey, px, strings.ToLower(string(format)))
+}
+
+// DeleteVariants removes every variant stored under baseKey. Missing objects are not an error.
+func DeleteVariants(ctx context.Context, store storage.Store, baseKey string) error {
+	if baseKey == "" || path.Ext(baseKey) != "" || strings.Contains(baseKey, "://") {
+		return nil
+	}
+	for size := range sizes {
+		for _, format := range formats {
+			if err := store.Delete(ctx, VariantKey(baseKey, size, format)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func encode(w io.Writer, img image.Image, format model.ImageFormat) error {
+	if format == model.ImageWebp {
+		return nativewebp.Encode(w, img, nil)
+	}
+	return png.Encode(w, img)
+}
+
+func contentType(format model.ImageFormat) string {
+	if format == model.ImageWebp {
+		return "image/webp"
+	}
+	return "image/png"
+}
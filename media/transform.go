@@ -0,0 +1,144 @@
+package media
+
+import (
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// resize scales img so that its longest side is at most max pixels, never upscaling. With square
+// set it first crops the centre square.
+func resize(img image.Image, max int, square bool) image.Image {
+	b := img.Bounds()
+	if square {
+		side := b.Dx()
+		if b.Dy() < side {
+			side = b.Dy()
+		}
+		x0 := b.Min.X + (b.Dx()-side)/2
+		y0 := b.Min.Y + (b.Dy()-side)/2
+		b = image.Rect(x0, y0, x0+side, y0+side)
+	}
+
+	w, h := b.Dx(), b.Dy()
+	if w > max || h > max {
+		if w >= h {
+			w, h = max, h*max/w
+		} else {
+			w, h = w*max/h, max
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// applyOrientation rotates/flips img according to an EXIF orientation value (1-8).
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // orientations 5-8 swap width and height
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirror vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag from a JPEG's APP1 segment, returning 1
+// (no transform) for non-JPEGs or when the tag is missing or malformed.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan / end of image: no more metadata
+			return 1
+		}
+		segLen := int(binary.BigEndian.Uint16(data[i+2:]))
+		if segLen < 2 || i+2+segLen > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+segLen]
+		if marker == 0xE1 && len(seg) > 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + segLen
+	}
+	return 1
+}
+
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	entries := int(order.Uint16(t[ifd:]))
+	for k := 0; k < entries; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > len(t) {
+			return 1
+		}
+		if order.Uint16(t[e:]) == 0x0112 {
+			if v := int(order.Uint16(t[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
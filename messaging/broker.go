@@ -0,0 +1,92 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// subscriberBuffer is how many messages a slow subscriber may fall behind before new ones are
+// dropped for it. Dropped messages are still in the database and show up as unread.
+const subscriberBuffer = 16
+
+// Broker fans new messages out to the subscriptions of their recipients connected to this
+// replica. Messages sent through other replicas arrive through the outbox, see Relay.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan *model.Message]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[uint]map[chan *model.Message]struct{})}
+}
+
+// Subscribe returns a channel receiving the messages published to userID until ctx is done.
+func (b *Broker) Subscribe(ctx context.Context, userID uint) <-chan *model.Message {
+	ch := make(chan *model.Message, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan *model.Message]struct{})
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs[userID], ch)
+		if len(b.subs[userID]) == 0 {
+			delete(b.subs, userID)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+// Publish delivers message to every subscription of the given users without blocking.
+func (b *Broker) Publish(userIDs []uint, message *model.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range userIDs {
+		for ch := range b.subs[id] {
+			select {
+			case ch <- message:
+			default:
+			}
+		}
+	}
+}
+
+// Relay returns the outbox listener handler publishing each MessageSent event to the recipients'
+// subscriptions on this replica.
+func (b *Broker) Relay(db *gorm.DB, logger *zerolog.Logger) outbox.ListenHandler {
+	return func(ctx context.Context, event *outbox.Event) {
+		var payload outbox.MessagePayload
+		if err := event.Decode(&payload); err != nil {
+			logger.Error().Err(err).Msg("Relaying message failed")
+			return
+		}
+		var message model.Message
+		if err := db.WithContext(ctx).First(&message, payload.MessageID).Error; err != nil {
+			logger.Error().Err(err).Uint("message_id", payload.MessageID).Msg("Relaying message failed")
+			return
+		}
+		var conversation model.Conversation
+		if err := db.WithContext(ctx).First(&conversation, payload.ConversationID).Error; err != nil {
+			logger.Error().Err(err).Uint("message_id", payload.MessageID).Msg("Relaying message failed")
+			return
+		}
+		recipients, err := Recipients(db.WithContext(ctx), &conversation, payload.SenderID)
+		if err != nil {
+			logger.Error().Err(err).Uint("message_id", payload.MessageID).Msg("Relaying message failed")
+			return
+		}
+		b.Publish(recipients, &message)
+	}
+}
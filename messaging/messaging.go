@@ -0,0 +1,224 @@
+// Package messaging handles the conversation threads between volunteers and nonprofit members,
+// and delivers new messages to subscribers as they are sent.
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotParticipant = errors.New("unauthorized: only the volunteer and members of the nonprofit can access this conversation")
+
+// IsParticipant reports whether user can read and post to a thread between the given volunteer
+// and nonprofit: the volunteer themselves or any member of the nonprofit. Platform admins are
+// deliberately not included.
+func IsParticipant(db *gorm.DB, user *model.User, nonprofitID, volunteerID uint) (bool, error) {
+	if user.ID == volunteerID {
+		return true, nil
+	}
+	var count int64
+	if err := db.Table("nonprofit_members").
+		Where("nonprofit_id = ? AND user_id = ?", nonprofitID, user.ID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check nonprofit membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Authorize returns ErrNotParticipant unless user takes part in conversation.
+func Authorize(db *gorm.DB, user *model.User, conversation *model.Conversation) error {
+	ok, err := IsParticipant(db, user, conversation.NonprofitID, conversation.VolunteerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+// ForApplication returns the conversation about application, starting it if there is none yet.
+// application must have its Project loaded.
+func ForApplication(tx *gorm.DB, application *model.Application) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := tx.Where(model.Conversation{ApplicationID: &application.ID}).
+		Attrs(model.Conversation{
+			ProjectID:   application.ProjectID,
+			NonprofitID: application.Project.NonprofitID,
+			VolunteerID: application.VolunteerID,
+		}).
+		FirstOrCreate(&conversation).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to open conversation: %w", err)
+	}
+	return &conversation, nil
+}
+
+// ForEngagement returns the conversation about engagement. The thread from the volunteer's
+// application to the same project is carried over when there is one, so the history stays in one
+// place; otherwise a new conversation is started. engagement must have its Project loaded.
+func ForEngagement(tx *gorm.DB, engagement *model.Engagement) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := tx.Where("engagement_id = ?", engagement.ID).First(&conversation).Error
+	if err == nil {
+		return &conversation, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to fetch conversation: %w", err)
+	}
+
+	err = tx.Where("project_id = ? AND volunteer_id = ? AND engagement_id IS NULL", engagement.ProjectID, engagement.VolunteerID).
+		Order("created_at DESC").
+		First(&conversation).Error
+	switch {
+	case err == nil:
+		conversation.EngagementID = &engagement.ID
+		if err := tx.Model(&conversation).Update("engagement_id", engagement.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to attach conversation to engagement: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		conversation = model.Conversation{
+			EngagementID: &engagement.ID,
+			ProjectID:    engagement.ProjectID,
+			NonprofitID:  engagement.Project.NonprofitID,
+			VolunteerID:  engagement.VolunteerID,
+		}
+		if err := tx.Create(&conversation).Error; err != nil {
+			return nil, fmt.Errorf("failed to open conversation: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to fetch conversation: %w", err)
+	}
+	return &conversation, nil
+}
+
+// Send posts a message to conversation and records a MessageSent event. Sending counts as reading
+// everything before it. Run it inside a transaction.
+func Send(tx *gorm.DB, conversation *model.Conversation, sender *model.User, body string) (*model.Message, error) {
+	message := model.Message{
+		ConversationID: conversation.ID,
+		SenderID:       sender.ID,
+		Body:           body,
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to save message: %w", err)
+	}
+
+	conversation.LastMessageAt = &message.CreatedAt
+	if err := tx.Model(conversation).Update("last_message_at", message.CreatedAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %w", err)
+	}
+	if err := MarkRead(tx, conversation.ID, sender.ID, message.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := outbox.Record(tx, outbox.MessageSent, outbox.MessagePayload{
+		MessageID:      message.ID,
+		ConversationID: conversation.ID,
+		SenderID:       sender.ID,
+	}); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// MarkRead records that userID has read conversationID up to at. It never moves the read marker
+// backwards.
+func MarkRead(db *gorm.DB, conversationID, userID uint, at time.Time) error {
+	read := model.ConversationRead{ConversationID: conversationID, UserID: userID, LastReadAt: at}
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "conversation_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "last_read_at"},
+			Value:  gorm.Expr("GREATEST(conversation_reads.last_read_at, excluded.last_read_at)"),
+		}},
+	}).Create(&read).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return nil
+}
+
+// unreadSQL selects the messages from others that userID has not read yet, in conversations they
+// take part in. Its four parameters are all the user's ID.
+const unreadSQL = `
+	FROM messages m
+	JOIN conversations c ON c.id = m.conversation_id AND c.deleted_at IS NULL
+	LEFT JOIN conversation_reads r ON r.conversation_id = c.id AND r.user_id = ?
+	WHERE m.deleted_at IS NULL
+		AND m.sender_id <> ?
+		AND (r.last_read_at IS NULL OR m.created_at > r.last_read_at)
+		AND (c.volunteer_id = ? OR c.nonprofit_id IN (SELECT nonprofit_id FROM nonprofit_members WHERE user_id = ?))`
+
+// UnreadCount is the number of messages in conversationID that userID has not read.
+func UnreadCount(db *gorm.DB, conversationID, userID uint) (int, error) {
+	var count int
+	if err := db.Raw("SELECT COUNT(*)"+unreadSQL+" AND c.id = ?", userID, userID, userID, userID, conversationID).
+		Scan(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// TotalUnread is the number of unread messages across all of userID's conversations.
+func TotalUnread(db *gorm.DB, userID uint) (int, error) {
+	var count int
+	if err := db.Raw("SELECT COUNT(*)"+unreadSQL, userID, userID, userID, userID).
+		Scan(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// Conversations lists the conversations user takes part in, most recently active first.
+func Conversations(db *gorm.DB, user *model.User) ([]*model.Conversation, error) {
+	var conversations []*model.Conversation
+	if err := db.Where("volunteer_id = ? OR nonprofit_id IN (SELECT nonprofit_id FROM nonprofit_members WHERE user_id = ?)", user.ID, user.ID).
+		Order("COALESCE(last_message_at, created_at) DESC").
+		Find(&conversations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// Messages returns up to limit messages of conversationID in the order they were sent. When
+// beforeID is non-zero only messages older than it are returned, for paging back through history.
+func Messages(db *gorm.DB, conversationID, beforeID uint, limit int) ([]*model.Message, error) {
+	query := db.Where("conversation_id = ?", conversationID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var messages []*model.Message
+	if err := query.Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// Recipients lists the users a message in conversation is delivered to: the volunteer and every
+// member of the nonprofit, except the sender.
+func Recipients(db *gorm.DB, conversation *model.Conversation, senderID uint) ([]uint, error) {
+	var memberIDs []uint
+	if err := db.Table("nonprofit_members").
+		Where("nonprofit_id = ?", conversation.NonprofitID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit members: %w", err)
+	}
+
+	recipients := make([]uint, 0, len(memberIDs)+1)
+	for _, id := range append(memberIDs, conversation.VolunteerID) {
+		if id != senderID {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// Content for each event. Titles double as email subjects, so they name the project.
+
+// ApplicationReceived tells the nonprofit's members that volunteer applied to project.
+func ApplicationReceived(project *model.Project, volunteer *model.User) Content {
+	return Content{
+		Title: fmt.Sprintf("New application for %s", project.Title),
+		Body:  fmt.Sprintf("%s applied to volunteer on %s.", fullName(volunteer), project.Title),
+		Link:  fmt.Sprintf("/projects/%d/applications", project.ID),
+	}
+}
+
+// ApplicationDecided tells a volunteer the outcome of their application, picking the event from
+// its status. ok is false for statuses volunteers aren't notified about.
+func ApplicationDecided(application *model.Application, project *model.Project) (event model.NotificationEvent, content Content, ok bool) {
+	link := fmt.Sprintf("/projects/%d", project.ID)
+	switch application.Status {
+	case model.Accepted:
+		return model.NotifyApplicationAccepted, Content{
+			Title: fmt.Sprintf("You're in: %s", project.Title),
+			Body:  fmt.Sprintf("Your application to %s was accepted.", project.Title),
+			Link:  link,
+		}, true
+	case model.Waitlisted:
+		return model.NotifyApplicationWaitlisted, Content{
+			Title: fmt.Sprintf("You're on the waitlist for %s", project.Title),
+			Body:  fmt.Sprintf("%s is full right now. You'll be accepted automatically if a place opens up.", project.Title),
+			Link:  link,
+		}, true
+	case model.Rejected:
+		return model.NotifyApplicationRejected, Content{
+			Title: fmt.Sprintf("Update on your application to %s", project.Title),
+			Body:  fmt.Sprintf("Your application to %s was not accepted this time.", project.Title),
+			Link:  link,
+		}, true
+	case model.Expired:
+		return model.NotifyApplicationExpired, Content{
+			Title: fmt.Sprintf("Your application to %s expired", project.Title),
+			Body:  fmt.Sprintf("%s didn't get to your application in time, so it has expired. You can look for other projects in the meantime.", project.Title),
+			Link:  link,
+		}, true
+	}
+	return "", Content{}, false
+}
+
+// ApplicationClosed tells a volunteer their open application was closed because project was
+// completed or cancelled before anyone decided on it.
+func ApplicationClosed(project *model.Project, status model.ProjectStatus) Content {
+	reason := "has been completed"
+	if status == model.Cancelled {
+		reason = "was cancelled"
+	}
+	return Content{
+		Title: fmt.Sprintf("Applications to %s have closed", project.Title),
+		Body:  fmt.Sprintf("%s %s, so your application was closed. You can look for other projects in the meantime.", project.Title, reason),
+		Link:  fmt.Sprintf("/projects/%d", project.ID),
+	}
+}
+
+// HoursReviewed tells a volunteer whether the nonprofit signed off hours they logged.
+func HoursReviewed(entry *model.HoursLogged, project *model.Project, approved bool) (model.NotificationEvent, Content) {
+	link := fmt.Sprintf("/engagements/%d", entry.EngagementID)
+	if approved {
+		return model.NotifyHoursApproved, Content{
+			Title: fmt.Sprintf("Hours approved on %s", project.Title),
+			Body:  fmt.Sprintf("%.1f hours you logged on %s were approved.", entry.Hours, project.Title),
+			Link:  link,
+		}
+	}
+	return model.NotifyHoursRejected, Content{
+		Title: fmt.Sprintf("Hours not approved on %s", project.Title),
+		Body:  fmt.Sprintf("%.1f hours you logged on %s were not approved.", entry.Hours, project.Title),
+		Link:  link,
+	}
+}
+
+// EngagementIdle nudges a volunteer who has not logged hours on engagement for a while.
+func EngagementIdle(engagement *model.Engagement, project *model.Project) Content {
+	return Content{
+		Title: fmt.Sprintf("Still volunteering on %s?", project.Title),
+		Body:  fmt.Sprintf("You haven't logged any hours on %s for a while. Log your hours to keep the engagement active, or complete it if you're done.", project.Title),
+		Link:  fmt.Sprintf("/engagements/%d", engagement.ID),
+	}
+}
+
+// EngagementCancelled tells a volunteer someone else cancelled their engagement.
+func EngagementCancelled(engagement *model.Engagement, project *model.Project, reason *string) Content {
+	body := fmt.Sprintf("Your engagement on %s was cancelled.", project.Title)
+	if reason != nil && *reason != "" {
+		body += "\n\n" + *reason
+	}
+	return Content{
+		Title: fmt.Sprintf("Your engagement on %s was closed", project.Title),
+		Body:  body,
+		Link:  fmt.Sprintf("/engagements/%d", engagement.ID),
+	}
+}
+
+// HoursReminder reminds a volunteer to log the hours they worked on engagement.
+func HoursReminder(engagement *model.Engagement, project *model.Project) Content {
+	return Content{
+		Title: fmt.Sprintf("Log your hours on %s", project.Title),
+		Body:  fmt.Sprintf("Don't forget to log the time you spent on %s this week.", project.Title),
+		Link:  fmt.Sprintf("/engagements/%d", engagement.ID),
+	}
+}
+
+// ProjectStatusChanged tells everyone involved in project about its new status.
+func ProjectStatusChanged(project *model.Project, reason *string) Content {
+	status := strings.ToLower(strings.ReplaceAll(string(project.Status), "_", " "))
+	body := fmt.Sprintf("%s is now %s.", project.Title, status)
+	if reason != nil && *reason != "" {
+		body += "\n\n" + *reason
+	}
+	return Content{
+		Title: fmt.Sprintf("%s is now %s", project.Title, status),
+		Body:  body,
+		Link:  fmt.Sprintf("/projects/%d", project.ID),
+	}
+}
+
+// MessageReceived tells a participant of a conversation about a new message.
+func MessageReceived(conversation *model.Conversation, sender *model.User, body string) Content {
+	preview := body
+	if r := []rune(preview); len(r) > 140 {
+		preview = string(r[:140]) + "…"
+	}
+	return Content{
+		Title: fmt.Sprintf("New message from %s", fullName(sender)),
+		Body:  preview,
+		Link:  fmt.Sprintf("/messages/%d", conversation.ID),
+	}
+}
+
+// NonprofitMembers lists the IDs of nonprofitID's members.
+func NonprofitMembers(db *gorm.DB, nonprofitID uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Table("nonprofit_members").
+		Where("nonprofit_id = ?", nonprofitID).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit members: %w", err)
+	}
+	return ids, nil
+}
+
+// ProjectAudience lists everyone with a stake in project: the nonprofit's members and the
+// volunteers whose applications are still open or accepted.
+func ProjectAudience(db *gorm.DB, project *model.Project) ([]uint, error) {
+	ids, err := NonprofitMembers(db, project.NonprofitID)
+	if err != nil {
+		return nil, err
+	}
+	var volunteerIDs []uint
+	if err := db.Model(&model.Application{}).
+		Where("project_id = ? AND status IN ?", project.ID, []model.ApplicationStatus{model.Pending, model.Waitlisted, model.Accepted}).
+		Pluck("volunteer_id", &volunteerIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch project volunteers: %w", err)
+	}
+	return append(ids, volunteerIDs...), nil
+}
+
+// Except returns ids without id, typically to leave out whoever caused the event.
+func Except(ids []uint, id uint) []uint {
+	out := make([]uint, 0, len(ids))
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func fullName(user *model.User) string {
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
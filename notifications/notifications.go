@@ -0,0 +1,308 @@
+// Package notifications records what happened for each user and fans it out over the channels
+// they chose: the in-app notification center, an immediate email, or the periodic digest.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KindDeliver is the job that hands a notification to one external sender.
+const KindDeliver = "notifications.deliver"
+
+// Content is what a notification says. Link is a frontend path such as /projects/12.
+type Content struct {
+	Title string
+	Body  string
+	Link  string
+}
+
+// Sender delivers a notification outside the app.
+type Sender interface {
+	Send(ctx context.Context, user *model.User, notification *model.Notification) error
+}
+
+// Notifier creates notifications and hands them to the senders of the channels each recipient
+// chose. Senders registered for ChannelInApp are used alongside the notification center, e.g. to
+// forward to a push gateway. Sending happens in KindDeliver jobs, see Register.
+type Notifier struct {
+	DB      *gorm.DB
+	Senders map[model.NotificationChannel][]Sender
+	Logger  *zerolog.Logger
+}
+
+// NewFromEnv builds a Notifier that emails through m and, when NOTIFICATION_WEBHOOK_URL is set,
+// also posts in-app notifications to that webhook.
+func NewFromEnv(db *gorm.DB, m mailer.Mailer, logger *zerolog.Logger) *Notifier {
+	senders := map[model.NotificationChannel][]Sender{
+		model.ChannelEmail: {&EmailSender{Mailer: m}},
+	}
+	if url := os.Getenv("NOTIFICATION_WEBHOOK_URL"); url != "" {
+		senders[model.ChannelInApp] = append(senders[model.ChannelInApp], &WebhookSender{
+			URL:    url,
+			Secret: os.Getenv("NOTIFICATION_WEBHOOK_SECRET"),
+		})
+	}
+	return &Notifier{DB: db, Senders: senders, Logger: logger}
+}
+
+// Notify sends event to each of userIDs, creating the notifications and their delivery jobs with
+// tx. Notifications are sent for domain events, see HandleEvent.
+func (n *Notifier) Notify(tx *gorm.DB, event model.NotificationEvent, userIDs []uint, content Content) error {
+	seen := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if err := n.notify(tx, event, userID, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) notify(db *gorm.DB, event model.NotificationEvent, userID uint, content Content) error {
+	channels, err := Channels(db, userID, event)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	notification := model.Notification{
+		UserID: userID,
+		Event:  event,
+		Title:  content.Title,
+		Body:   content.Body,
+		Link:   content.Link,
+	}
+	for _, channel := range channels {
+		switch channel {
+		case model.ChannelInApp:
+			notification.InApp = true
+		case model.ChannelDigest:
+			notification.Digest = true
+		}
+	}
+
+	// One job per sender, so a retry never repeats a delivery that already succeeded
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&notification).Error; err != nil {
+			return fmt.Errorf("failed to save notification: %w", err)
+		}
+		for _, channel := range channels {
+			for i := range n.Senders[channel] {
+				if _, err := jobs.Enqueue(tx, KindDeliver, deliverPayload{
+					NotificationID: notification.ID,
+					Channel:        channel,
+					Sender:         i,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// deliverPayload identifies one sender of a channel by its index in Notifier.Senders.
+type deliverPayload struct {
+	NotificationID uint                      `json:"notificationId"`
+	Channel        model.NotificationChannel `json:"channel"`
+	Sender         int                       `json:"sender"`
+}
+
+// Register makes w deliver notifications.
+func (n *Notifier) Register(w *jobs.Worker) {
+	w.Register(KindDeliver, n.deliver)
+}
+
+func (n *Notifier) deliver(ctx context.Context, job *jobs.Job) error {
+	var payload deliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	senders := n.Senders[payload.Channel]
+	if payload.Sender < 0 || payload.Sender >= len(senders) {
+		// The sender was configured when the job was queued but not any more
+		return nil
+	}
+
+	var notification model.Notification
+	if err := n.DB.First(&notification, payload.NotificationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch notification: %w", err)
+	}
+	var user model.User
+	if err := n.DB.First(&user, notification.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return senders[payload.Sender].Send(ctx, &user, &notification)
+}
+
+// DefaultChannels are the channels used for event until the user picks their own. Outcomes of a
+// volunteer's own applications and project status changes are emailed straight away; routine
+// activity goes to the digest.
+func DefaultChannels(event model.NotificationEvent) []model.NotificationChannel {
+	switch event {
+	case model.NotifyApplicationAccepted, model.NotifyApplicationWaitlisted, model.NotifyApplicationRejected, model.NotifyApplicationExpired,
+		model.NotifyProjectStatusChanged, model.NotifyEngagementIdle, model.NotifyEngagementCancelled, model.NotifyHoursReminder:
+		return []model.NotificationChannel{model.ChannelInApp, model.ChannelEmail}
+	case model.NotifyMessageReceived:
+		// Unread messages already show up in the inbox
+		return []model.NotificationChannel{model.ChannelDigest}
+	default:
+		return []model.NotificationChannel{model.ChannelInApp, model.ChannelDigest}
+	}
+}
+
+// Events lists every notification event, in the order preferences are shown.
+var Events = []model.NotificationEvent{
+	model.NotifyApplicationReceived,
+	model.NotifyApplicationAccepted,
+	model.NotifyApplicationWaitlisted,
+	model.NotifyApplicationRejected,
+	model.NotifyHoursApproved,
+	model.NotifyHoursRejected,
+	model.NotifyProjectStatusChanged,
+	model.NotifyMessageReceived,
+	model.NotifyApplicationExpired,
+	model.NotifyEngagementIdle,
+	model.NotifyEngagementCancelled,
+	model.NotifyHoursReminder,
+}
+
+// Channels returns the channels userID receives event on.
+func Channels(db *gorm.DB, userID uint, event model.NotificationEvent) ([]model.NotificationChannel, error) {
+	var preference model.NotificationPreference
+	result := db.Where("user_id = ? AND event = ?", userID, event).Limit(1).Find(&preference)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch notification preference: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return DefaultChannels(event), nil
+	}
+	return PreferenceChannels(&preference), nil
+}
+
+// PreferenceChannels lists the channels a stored preference turns on.
+func PreferenceChannels(preference *model.NotificationPreference) []model.NotificationChannel {
+	channels := []model.NotificationChannel{}
+	if preference.InApp {
+		channels = append(channels, model.ChannelInApp)
+	}
+	if preference.Email {
+		channels = append(channels, model.ChannelEmail)
+	}
+	if preference.Digest {
+		channels = append(channels, model.ChannelDigest)
+	}
+	return channels
+}
+
+// Preferences returns userID's preference for every event, filling in the defaults for events
+// they have not set.
+func Preferences(db *gorm.DB, userID uint) ([]*model.NotificationPreference, error) {
+	var stored []model.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	byEvent := make(map[model.NotificationEvent]model.NotificationPreference, len(stored))
+	for _, preference := range stored {
+		byEvent[preference.Event] = preference
+	}
+
+	preferences := make([]*model.NotificationPreference, 0, len(Events))
+	for _, event := range Events {
+		preference, ok := byEvent[event]
+		if !ok {
+			preference = newPreference(userID, event, DefaultChannels(event))
+		}
+		preferences = append(preferences, &preference)
+	}
+	return preferences, nil
+}
+
+// SetPreference stores the channels userID wants event on. An empty list turns the event off.
+func SetPreference(db *gorm.DB, userID uint, event model.NotificationEvent, channels []model.NotificationChannel) (*model.NotificationPreference, error) {
+	preference := newPreference(userID, event, channels)
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "digest", "updated_at"}),
+	}).Create(&preference).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return &preference, nil
+}
+
+func newPreference(userID uint, event model.NotificationEvent, channels []model.NotificationChannel) model.NotificationPreference {
+	preference := model.NotificationPreference{UserID: userID, Event: event}
+	for _, channel := range channels {
+		switch channel {
+		case model.ChannelInApp:
+			preference.InApp = true
+		case model.ChannelEmail:
+			preference.Email = true
+		case model.ChannelDigest:
+			preference.Digest = true
+		}
+	}
+	return preference
+}
+
+// List returns userID's notification center, newest first.
+func List(db *gorm.DB, userID uint, unreadOnly bool, limit int) ([]*model.Notification, error) {
+	query := db.Where("user_id = ? AND in_app = ?", userID, true)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []*model.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// UnreadCount is the number of unread notifications in userID's notification center.
+func UnreadCount(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	if err := db.Model(&model.Notification{}).
+		Where("user_id = ? AND in_app = ? AND read_at IS NULL", userID, true).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks userID's notifications with the given IDs as read, or all of them when ids is
+// nil, and returns how many changed.
+func MarkRead(db *gorm.DB, userID uint, ids []uint) (int64, error) {
+	query := db.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if ids != nil {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
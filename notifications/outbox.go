@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/messaging"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+// ConsumerName is the name HandleEvent consumes the outbox under.
+const ConsumerName = "notifications"
+
+// HandleEvent is the outbox handler that notifies the users affected by a domain event. Events
+// nobody is notified about are ignored. Anything an event refers to that has since been deleted
+// is skipped rather than retried.
+func (n *Notifier) HandleEvent(tx *gorm.DB, event *outbox.Event) error {
+	err := n.handleEvent(tx, event)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (n *Notifier) handleEvent(tx *gorm.DB, event *outbox.Event) error {
+	switch event.Type {
+	case outbox.ApplicationSubmitted, outbox.ApplicationStatusChanged:
+		var payload outbox.ApplicationPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var application model.Application
+		if err := tx.Preload("Volunteer").Preload("Project").First(&application, payload.ApplicationID).Error; err != nil {
+			return fmt.Errorf("failed to fetch application: %w", err)
+		}
+		if event.Type == outbox.ApplicationSubmitted {
+			members, err := NonprofitMembers(tx, application.Project.NonprofitID)
+			if err != nil {
+				return err
+			}
+			return n.Notify(tx, model.NotifyApplicationReceived, members, ApplicationReceived(&application.Project, &application.Volunteer))
+		}
+		if payload.ProjectClosed != nil {
+			return n.Notify(tx, model.NotifyApplicationRejected, []uint{application.VolunteerID}, ApplicationClosed(&application.Project, *payload.ProjectClosed))
+		}
+		// Describe the status the event is about, not whatever the application moved on to since
+		application.Status = payload.To
+		if kind, content, ok := ApplicationDecided(&application, &application.Project); ok {
+			return n.Notify(tx, kind, []uint{application.VolunteerID}, content)
+		}
+		return nil
+
+	case outbox.ProjectStatusChanged:
+		var payload outbox.ProjectPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		// Submissions only concern the moderators, and a project filling up isn't news
+		if payload.To == model.PendingReview || (payload.To == model.InProgress && payload.ChangedByID == nil) {
+			return nil
+		}
+		var project model.Project
+		if err := tx.First(&project, payload.ProjectID).Error; err != nil {
+			return fmt.Errorf("failed to fetch project: %w", err)
+		}
+		project.Status = payload.To
+		audience, err := ProjectAudience(tx, &project)
+		if err != nil {
+			return err
+		}
+		if payload.ChangedByID != nil {
+			audience = Except(audience, *payload.ChangedByID)
+		}
+		return n.Notify(tx, model.NotifyProjectStatusChanged, audience, ProjectStatusChanged(&project, payload.Reason))
+
+	case outbox.HoursReviewed:
+		var payload outbox.HoursPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Approved == nil {
+			return nil
+		}
+		var entry model.HoursLogged
+		if err := tx.Preload("Engagement.Project").First(&entry, payload.HoursID).Error; err != nil {
+			return fmt.Errorf("failed to fetch hours: %w", err)
+		}
+		kind, content := HoursReviewed(&entry, &entry.Engagement.Project, *payload.Approved)
+		return n.Notify(tx, kind, []uint{entry.Engagement.VolunteerID}, content)
+
+	case outbox.EngagementCancelled, outbox.EngagementIdle, outbox.HoursReminderDue:
+		var payload outbox.EngagementPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		// Volunteers know when they cancelled themselves
+		if event.Type == outbox.EngagementCancelled && payload.ByID != nil && *payload.ByID == payload.VolunteerID {
+			return nil
+		}
+		var engagement model.Engagement
+		if err := tx.Preload("Project").First(&engagement, payload.EngagementID).Error; err != nil {
+			return fmt.Errorf("failed to fetch engagement: %w", err)
+		}
+		kind, content := model.NotifyEngagementCancelled, EngagementCancelled(&engagement, &engagement.Project, payload.Reason)
+		switch event.Type {
+		case outbox.EngagementIdle:
+			kind, content = model.NotifyEngagementIdle, EngagementIdle(&engagement, &engagement.Project)
+		case outbox.HoursReminderDue:
+			kind, content = model.NotifyHoursReminder, HoursReminder(&engagement, &engagement.Project)
+		}
+		return n.Notify(tx, kind, []uint{payload.VolunteerID}, content)
+
+	case outbox.MessageSent:
+		var payload outbox.MessagePayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var message model.Message
+		if err := tx.First(&message, payload.MessageID).Error; err != nil {
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+		var conversation model.Conversation
+		if err := tx.First(&conversation, payload.ConversationID).Error; err != nil {
+			return fmt.Errorf("failed to fetch conversation: %w", err)
+		}
+		var sender model.User
+		if err := tx.First(&sender, payload.SenderID).Error; err != nil {
+			return fmt.Errorf("failed to fetch sender: %w", err)
+		}
+		recipients, err := messaging.Recipients(tx, &conversation, sender.ID)
+		if err != nil {
+			return err
+		}
+		return n.Notify(tx, model.NotifyMessageReceived, recipients, MessageReceived(&conversation, &sender, message.Body))
+	}
+	return nil
+}
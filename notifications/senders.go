@@ -0,0 +1,93 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+)
+
+// EmailSender emails a notification to the user straight away.
+type EmailSender struct {
+	Mailer mailer.Mailer
+}
+
+func (s *EmailSender) Send(ctx context.Context, user *model.User, notification *model.Notification) error {
+	text := notification.Body
+	if notification.Link != "" {
+		text += "\n\n" + strings.TrimRight(os.Getenv("FRONTEND_URL"), "/") + notification.Link
+	}
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: notification.Title,
+		Text:    text,
+	})
+}
+
+// WebhookSender posts notifications as JSON to a fixed URL, such as a push notification gateway.
+// When Secret is set the body is signed with HMAC-SHA256 in the X-Signature-256 header.
+type WebhookSender struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	ID        uint                    `json:"id"`
+	Event     model.NotificationEvent `json:"event"`
+	UserID    uint                    `json:"userId"`
+	Title     string                  `json:"title"`
+	Body      string                  `json:"body"`
+	Link      string                  `json:"link,omitempty"`
+	CreatedAt time.Time               `json:"createdAt"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, user *model.User, notification *model.Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		UserID:    user.ID,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Link:      notification.Link,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package outbox
+
+import "github.com/prkagrawal/cosmos-bk2/graph/model"
+
+// Event types. The payload of each is noted next to it.
+const (
+	ApplicationSubmitted     = "application.submitted"      // ApplicationPayload
+	ApplicationStatusChanged = "application.status_changed" // ApplicationPayload
+	EngagementStarted        = "engagement.started"         // EngagementPayload
+	EngagementCompleted      = "engagement.completed"       // EngagementPayload
+	EngagementCancelled      = "engagement.cancelled"       // EngagementPayload
+	EngagementIdle           = "engagement.idle"            // EngagementPayload
+	HoursLogged              = "hours.logged"               // HoursPayload
+	HoursReviewed            = "hours.reviewed"             // HoursPayload
+	HoursReminderDue         = "hours.reminder_due"         // EngagementPayload
+	ProjectStatusChanged     = "project.status_changed"     // ProjectPayload
+	MessageSent              = "message.sent"               // MessagePayload
+	PersistedQueriesChanged  = "persisted_queries.changed"  // PersistedQueriesPayload
+)
+
+// ApplicationPayload describes a new application or a change of its status. From is nil for
+// ApplicationSubmitted. ProjectClosed is set when the application was closed because its project
+// reached that status, rather than decided on.
+type ApplicationPayload struct {
+	ApplicationID uint                     `json:"applicationId"`
+	ProjectID     uint                     `json:"projectId"`
+	VolunteerID   uint                     `json:"volunteerId"`
+	From          *model.ApplicationStatus `json:"from,omitempty"`
+	To            model.ApplicationStatus  `json:"to"`
+	ProjectClosed *model.ProjectStatus     `json:"projectClosed,omitempty"`
+}
+
+// EngagementPayload describes a change to an engagement. ByID is the user who made it, nil for
+// the system; Reason is shown to the volunteer when they didn't make it themselves.
+type EngagementPayload struct {
+	EngagementID uint    `json:"engagementId"`
+	ProjectID    uint    `json:"projectId"`
+	VolunteerID  uint    `json:"volunteerId"`
+	ByID         *uint   `json:"byId,omitempty"`
+	Reason       *string `json:"reason,omitempty"`
+}
+
+// HoursPayload describes logged hours or their review.
+type HoursPayload struct {
+	HoursID      uint  `json:"hoursId"`
+	EngagementID uint  `json:"engagementId"`
+	Approved     *bool `json:"approved,omitempty"`
+}
+
+// ProjectPayload describes a change of a project's status. ChangedByID is nil for the system.
+type ProjectPayload struct {
+	ProjectID   uint                `json:"projectId"`
+	From        model.ProjectStatus `json:"from"`
+	To          model.ProjectStatus `json:"to"`
+	ChangedByID *uint               `json:"changedById,omitempty"`
+	Reason      *string             `json:"reason,omitempty"`
+}
+
+// MessagePayload describes a message sent in a conversation.
+type MessagePayload struct {
+	MessageID      uint `json:"messageId"`
+	ConversationID uint `json:"conversationId"`
+	SenderID       uint `json:"senderId"`
+}
+
+// PersistedQueriesPayload describes a persisted query manifest registered by an admin.
+type PersistedQueriesPayload struct {
+	Version string `json:"version"`
+}
@@ -0,0 +1,116 @@
+// Package outbox records domain events in the same transaction as the change they describe and
+// relays them to their consumers afterwards. A consumer sees an event only once the change has
+// been committed, and exactly once: it handles the event in the transaction that marks it as
+// consumed, so whatever it writes, such as notifications, jobs or webhook deliveries, commits
+// or rolls back together with that mark.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"gorm.io/gorm"
+)
+
+// Event is a domain event waiting in the outbox. Payload holds one of the payload types in
+// events.go, as JSON.
+type Event struct {
+	ID        uint      `gorm:"primaryKey"`
+	Type      string    `gorm:"type:varchar(60)"`
+	Payload   string    `gorm:"type:jsonb"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (Event) TableName() string { return "outbox_events" }
+
+// Consumption records that a consumer has handled an event.
+type Consumption struct {
+	EventID    uint   `gorm:"primaryKey"`
+	Consumer   string `gorm:"primaryKey;type:varchar(60)"`
+	ConsumedAt time.Time
+}
+
+func (Consumption) TableName() string { return "outbox_consumptions" }
+
+// ConsumerStart is where a consumer started reading the outbox: the last event recorded before it
+// first ran, so that a newly added consumer doesn't replay the whole outbox.
+type ConsumerStart struct {
+	Consumer  string `gorm:"primaryKey;type:varchar(60)"`
+	AfterID   uint
+	CreatedAt time.Time
+}
+
+func (ConsumerStart) TableName() string { return "outbox_consumer_starts" }
+
+// Failure tracks a consumer failing to handle an event. The relay leaves the event alone until
+// RetryAt, and for good once DeadAt is set after MaxAttempts: the row then stays as the
+// dead-letter record of the event, until it is pruned with the event.
+type Failure struct {
+	EventID   uint   `gorm:"primaryKey"`
+	Consumer  string `gorm:"primaryKey;type:varchar(60)"`
+	Attempts  int
+	RetryAt   time.Time
+	LastError string
+	DeadAt    *time.Time `gorm:"index"`
+}
+
+func (Failure) TableName() string { return "outbox_failures" }
+
+// Decode unmarshals the event's payload into v.
+func (e *Event) Decode(v any) error {
+	if err := json.Unmarshal([]byte(e.Payload), v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Record adds an event to the outbox. Call it with the transaction making the change the event
+// describes.
+func Record(tx *gorm.DB, eventType string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	if err := tx.Create(&Event{Type: eventType, Payload: string(body)}).Error; err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// Retention is how long events are kept, for inspection, after which Prune deletes them.
+const Retention = 7 * 24 * time.Hour
+
+// KindPrune is the job that runs Prune.
+const KindPrune = "outbox.prune"
+
+// Prune deletes the events older than Retention with their consumptions and failures.
+func Prune(db *gorm.DB, now time.Time) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		old := tx.Model(&Event{}).Select("id").Where("created_at < ?", now.Add(-Retention))
+		if err := tx.Where("event_id IN (?)", old).Delete(&Consumption{}).Error; err != nil {
+			return fmt.Errorf("failed to prune outbox consumptions: %w", err)
+		}
+		if err := tx.Where("event_id IN (?)", old).Delete(&Failure{}).Error; err != nil {
+			return fmt.Errorf("failed to prune outbox failures: %w", err)
+		}
+		res := tx.Where("created_at < ?", now.Add(-Retention)).Delete(&Event{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to prune outbox events: %w", res.Error)
+		}
+		deleted = res.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
+
+// PruneHandler returns the KindPrune job handler.
+func PruneHandler(db *gorm.DB) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) error {
+		_, err := Prune(db, time.Now())
+		return err
+	}
+}
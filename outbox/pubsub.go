@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// Channel is the Postgres NOTIFY channel events are broadcast on.
+const Channel = "outbox_events"
+
+// ConsumerPubSub is the consumer name of Broadcast.
+const ConsumerPubSub = "pubsub"
+
+// Broadcast is the consumer that publishes events to the Listener of every replica. NOTIFY is
+// transactional, so listeners hear of each event exactly once, when it is marked consumed.
+func Broadcast(tx *gorm.DB, event *Event) error {
+	if err := tx.Exec("SELECT pg_notify(?, ?)", Channel, strconv.FormatUint(uint64(event.ID), 10)).Error; err != nil {
+		return fmt.Errorf("failed to broadcast event: %w", err)
+	}
+	return nil
+}
+
+// ListenHandler handles a broadcast event in the replica that received it, e.g. to push it to
+// the GraphQL subscriptions connected there.
+type ListenHandler func(ctx context.Context, event *Event)
+
+// Listener receives the events broadcast on Channel and hands them to the handlers registered
+// for their type. Run one in every replica serving subscriptions.
+type Listener struct {
+	DB     *gorm.DB
+	Logger *zerolog.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]ListenHandler
+}
+
+func NewListener(db *gorm.DB, logger *zerolog.Logger) *Listener {
+	return &Listener{DB: db, Logger: logger, handlers: make(map[string][]ListenHandler)}
+}
+
+// Handle registers handler for events of eventType.
+func (l *Listener) Handle(eventType string, handler ListenHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[eventType] = append(l.handlers[eventType], handler)
+}
+
+// Run listens until ctx is cancelled, reconnecting after errors. Events broadcast while it is
+// reconnecting are missed; subscriptions are best effort, the data is in the database.
+func (l *Listener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		l.Logger.Error().Err(err).Msg("Listening for outbox events failed, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	sqlDB, err := l.DB.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("listening needs the pgx driver")
+		}
+		pgxConn := c.Conn()
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+Channel); err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				// The connection is still listening, or broken; either way it can't go back to
+				// the pool
+				return errors.Join(driver.ErrBadConn, err)
+			}
+			l.dispatch(ctx, notification.Payload)
+		}
+	})
+	return err
+}
+
+func (l *Listener) dispatch(ctx context.Context, payload string) {
+	id, err := strconv.ParseUint(payload, 10, 64)
+	if err != nil {
+		l.Logger.Warn().Str("payload", payload).Msg("Ignoring malformed outbox notification")
+		return
+	}
+	var event Event
+	if err := l.DB.WithContext(ctx).First(&event, id).Error; err != nil {
+		l.Logger.Error().Err(err).Uint64("event_id", id).Msg("Loading broadcast outbox event failed")
+		return
+	}
+
+	l.mu.RLock()
+	handlers := l.handlers[event.Type]
+	l.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(ctx, &event)
+	}
+}
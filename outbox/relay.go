@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler consumes an event. tx is the transaction marking the event consumed: write with it so
+// the consumer's changes commit exactly when the event is marked. An error rolls both back and
+// the event is retried with backoff.
+type Handler func(tx *gorm.DB, event *Event) error
+
+type consumer struct {
+	name    string
+	handler Handler
+	// afterID is the consumer's ConsumerStart, once loaded
+	afterID *uint
+}
+
+// MaxAttempts is how often a consumer tries an event before giving up on it, about six hours with
+// the job queue's backoff. The event's Failure is then kept as its dead-letter record.
+const MaxAttempts = 12
+
+// Relay hands the events in the outbox to every consumer. Any number of relays can run, one per
+// replica say: each event is still handled once per consumer.
+type Relay struct {
+	DB           *gorm.DB
+	Logger       *zerolog.Logger
+	PollInterval time.Duration
+	BatchSize    int
+
+	consumers []*consumer
+}
+
+func NewRelay(db *gorm.DB, logger *zerolog.Logger) *Relay {
+	return &Relay{
+		DB:           db,
+		Logger:       logger,
+		PollInterval: time.Second,
+		BatchSize:    100,
+	}
+}
+
+// Consume adds a consumer. name identifies it in the consumption records, so it must stay the
+// same across releases. A new consumer gets the events recorded after it first runs.
+func (r *Relay) Consume(name string, handler Handler) {
+	r.consumers = append(r.consumers, &consumer{name: name, handler: handler})
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	for {
+		for _, c := range r.consumers {
+			if err := r.drain(ctx, c); err != nil && ctx.Err() == nil {
+				r.Logger.Error().Err(err).Str("consumer", c.name).Msg("Relaying outbox events failed")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain hands c the events it hasn't consumed yet, oldest first. Events waiting to be retried
+// after a failure, or given up on, are left out so they cannot crowd out the rest of the batch.
+func (r *Relay) drain(ctx context.Context, c *consumer) error {
+	afterID, err := r.start(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var events []*Event
+	if err := r.DB.WithContext(ctx).
+		Where("id > ?", afterID).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_consumptions c WHERE c.event_id = outbox_events.id AND c.consumer = ?)", c.name).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox_failures f WHERE f.event_id = outbox_events.id AND f.consumer = ?
+			AND (f.dead_at IS NOT NULL OR f.retry_at > ?))`, c.name, time.Now()).
+		Order("id ASC").
+		Limit(r.BatchSize).
+		Find(&events).Error; err != nil {
+		return fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := r.consume(ctx, c, event); err != nil {
+			if err := r.fail(ctx, c, event, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// start returns the ID of the last event c is not interested in, recording it the first time c
+// runs anywhere. A consumer that already has consumptions from before starts were recorded picks
+// up after the last of them.
+func (r *Relay) start(ctx context.Context, c *consumer) (uint, error) {
+	if c.afterID != nil {
+		return *c.afterID, nil
+	}
+	db := r.DB.WithContext(ctx)
+	if err := db.Exec(`INSERT INTO outbox_consumer_starts (consumer, after_id, created_at)
+		SELECT ?, COALESCE((SELECT MAX(event_id) FROM outbox_consumptions WHERE consumer = ?), MAX(id), 0), ?
+		FROM outbox_events
+		ON CONFLICT (consumer) DO NOTHING`, c.name, c.name, time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to record consumer start: %w", err)
+	}
+	var start ConsumerStart
+	if err := db.First(&start, "consumer = ?", c.name).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch consumer start: %w", err)
+	}
+	c.afterID = &start.AfterID
+	return start.AfterID, nil
+}
+
+// fail records that c failed to handle event, scheduling the next attempt with backoff or, after
+// MaxAttempts, giving up on it.
+func (r *Relay) fail(ctx context.Context, c *consumer, event *Event, cause error) error {
+	db := r.DB.WithContext(ctx)
+	failure := Failure{EventID: event.ID, Consumer: c.name}
+	if err := db.Limit(1).Find(&failure).Error; err != nil {
+		return fmt.Errorf("failed to fetch outbox failure: %w", err)
+	}
+	now := time.Now()
+	failure.Attempts++
+	failure.RetryAt = now.Add(jobs.Backoff(failure.Attempts))
+	failure.LastError = cause.Error()
+
+	log := r.Logger.Error()
+	if failure.Attempts >= MaxAttempts {
+		failure.DeadAt = &now
+		log = log.Bool("dead", true)
+	}
+	log.Err(cause).Str("consumer", c.name).Uint("event_id", event.ID).Str("type", event.Type).
+		Int("attempt", failure.Attempts).Msg("Consuming outbox event failed")
+
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&failure).Error; err != nil {
+		return fmt.Errorf("failed to record outbox failure: %w", err)
+	}
+	return nil
+}
+
+// consume marks event consumed by c and handles it in one transaction. A relay on another replica
+// inserting the same mark waits for this transaction and then skips the event, or takes over if
+// it rolls back.
+func (r *Relay) consume(ctx context.Context, c *consumer, event *Event) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&Consumption{EventID: event.ID, Consumer: c.name, ConsumedAt: time.Now()})
+		if res.Error != nil {
+			return fmt.Errorf("failed to mark event consumed: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := c.handler(tx, event); err != nil {
+			return err
+		}
+		// Handled after all, on a retry
+		return tx.Where("event_id = ? AND consumer = ?", event.ID, c.name).Delete(&Failure{}).Error
+	})
+}
@@ -0,0 +1,73 @@
+package persisted
+
+import (
+	"context"
+	"errors"
+	"expvar"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Mode controls how operations outside the manifest are treated.
+type Mode string
+
+const (
+	// ModeOff disables persisted operations entirely.
+	ModeOff Mode = ""
+	// ModePermissive serves hashes from the manifest but still executes ad-hoc documents,
+	// counting them so we can see what would break before switching to strict.
+	ModePermissive Mode = "permissive"
+	// ModeStrict only executes operations whose hash is in the manifest.
+	ModeStrict Mode = "strict"
+)
+
+const errPersistedQueryNotAllowed = "PERSISTED_QUERY_NOT_ALLOWED"
+
+// Exposed on /debug/vars.
+var (
+	adhocQueries    = expvar.NewInt("graphql_adhoc_queries_total")
+	rejectedQueries = expvar.NewInt("graphql_adhoc_queries_rejected_total")
+)
+
+// Allowlist is a gqlgen extension enforcing Mode. Register it after AutomaticPersistedQuery so
+// that hash-only requests have already been expanded into their document.
+type Allowlist struct {
+	Store *Store
+	Mode  Mode
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = Allowlist{}
+
+func (a Allowlist) ExtensionName() string {
+	return "PersistedQueryAllowlist"
+}
+
+func (a Allowlist) Validate(schema graphql.ExecutableSchema) error {
+	if a.Store == nil {
+		return errors.New("PersistedQueryAllowlist.Store can not be nil")
+	}
+	return nil
+}
+
+func (a Allowlist) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if a.Mode == ModeOff {
+		return nil
+	}
+	if _, ok := a.Store.Get(ctx, Hash(rawParams.Query)); ok {
+		return nil
+	}
+
+	adhocQueries.Add(1)
+	if a.Mode != ModeStrict {
+		return nil
+	}
+
+	rejectedQueries.Add(1)
+	err := gqlerror.Errorf("operation is not in the persisted query allowlist")
+	err.Extensions = map[string]interface{}{"code": errPersistedQueryNotAllowed}
+	return err
+}
@@ -0,0 +1,193 @@
+package persisted
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PersistedOperation is an operation document the frontend is known to send, keyed by the
+// SHA-256 of its body (the same hash Apollo's persisted query link sends).
+type PersistedOperation struct {
+	Hash            string `gorm:"primaryKey;type:char(64)"`
+	Name            string
+	Body            string `gorm:"type:text;not null"`
+	ManifestVersion string `gorm:"index"`
+	CreatedAt       time.Time
+}
+
+// Manifest is a set of operations generated from the frontend at build time.
+type Manifest struct {
+	Version    string
+	Operations []PersistedOperation
+}
+
+// Store holds the allowlisted operations. Operations from a manifest file live only in memory;
+// operations registered at runtime are written to the persisted_operations table and announced
+// through the outbox, so every replica reloads them (see Reload). Lookups are only ever served
+// from memory, so unknown hashes cost nothing. Store implements graphql.Cache[string] so it can
+// back the APQ extension.
+type Store struct {
+	DB *gorm.DB
+
+	mu  sync.RWMutex
+	ops map[string]string
+}
+
+var _ graphql.Cache[string] = &Store{}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{
+		DB:  db,
+		ops: make(map[string]string),
+	}
+}
+
+// Hash returns the hex SHA-256 of a query document.
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseManifest accepts either Apollo's persisted query manifest
+// ({"format": "apollo-persisted-query-manifest", "operations": [{"id", "name", "body"}]})
+// or a flat {"<sha256>": "<document>"} map as produced by graphql-codegen.
+func ParseManifest(data []byte, version string) (*Manifest, error) {
+	var apollo struct {
+		Format     string `json:"format"`
+		Operations []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format != "" {
+		m := &Manifest{Version: version}
+		for _, op := range apollo.Operations {
+			m.Operations = append(m.Operations, PersistedOperation{Hash: op.ID, Name: op.Name, Body: op.Body, ManifestVersion: version})
+		}
+		return m, m.verify()
+	}
+
+	var flat map[string]string
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return nil, fmt.Errorf("unrecognised manifest format: %w", err)
+	}
+	m := &Manifest{Version: version}
+	for hash, body := range flat {
+		m.Operations = append(m.Operations, PersistedOperation{Hash: hash, Body: body, ManifestVersion: version})
+	}
+	return m, m.verify()
+}
+
+// verify rejects manifests whose hashes do not match their bodies, which would otherwise
+// allowlist a hash for a different document than the one reviewed.
+func (m *Manifest) verify() error {
+	if len(m.Operations) == 0 {
+		return errors.New("manifest contains no operations")
+	}
+	for _, op := range m.Operations {
+		if Hash(op.Body) != op.Hash {
+			return fmt.Errorf("manifest hash %s does not match its operation body", op.Hash)
+		}
+	}
+	return nil
+}
+
+// LoadFile reads a manifest from disk into memory and returns the number of operations loaded.
+func (s *Store) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read persisted query manifest: %w", err)
+	}
+	m, err := ParseManifest(data, path)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range m.Operations {
+		s.ops[op.Hash] = op.Body
+	}
+	return len(m.Operations), nil
+}
+
+// LoadFromDB warms the in-memory set with every registered operation.
+func (s *Store) LoadFromDB(ctx context.Context) error {
+	var ops []PersistedOperation
+	if err := s.DB.WithContext(ctx).Find(&ops).Error; err != nil {
+		return fmt.Errorf("failed to load persisted operations: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range ops {
+		s.ops[op.Hash] = op.Body
+	}
+	return nil
+}
+
+// Register stores a manifest in the database and returns how many of its operations were new.
+// The other replicas reload their operations once the PersistedQueriesChanged event it records
+// reaches them.
+func (s *Store) Register(ctx context.Context, m *Manifest) (int, error) {
+	var added int64
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range m.Operations {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&m.Operations[i])
+			if res.Error != nil {
+				return res.Error
+			}
+			added += res.RowsAffected
+		}
+		return outbox.Record(tx, outbox.PersistedQueriesChanged, outbox.PersistedQueriesPayload{Version: m.Version})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to register persisted operations: %w", err)
+	}
+
+	if err := s.LoadFromDB(ctx); err != nil {
+		return 0, err
+	}
+	return int(added), nil
+}
+
+// Reload is the outbox listen handler that picks up operations registered on another replica.
+func (s *Store) Reload(logger *zerolog.Logger) outbox.ListenHandler {
+	return func(ctx context.Context, event *outbox.Event) {
+		if err := s.LoadFromDB(ctx); err != nil {
+			logger.Error().Err(err).Msg("Reloading persisted queries failed")
+		}
+	}
+}
+
+// Len returns the number of operations currently held in memory.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ops)
+}
+
+// Get looks up an operation by hash.
+func (s *Store) Get(ctx context.Context, hash string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	body, ok := s.ops[hash]
+	return body, ok
+}
+
+// Add is a no-op: clients cannot teach the server new operations, only manifests can.
+func (s *Store) Add(ctx context.Context, hash string, query string) {}
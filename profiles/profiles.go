@@ -0,0 +1,99 @@
+// Package profiles serves volunteers' public impact profiles as plain web pages, so they can be
+// linked from LinkedIn or a CV and unfurl with a proper preview.
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/prkagrawal/cosmos-bk2/analytics"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+var ErrNotFound = errors.New("profile not found")
+
+// Path returns the public page for a profile slug.
+func Path(slug string) string {
+	return "/profiles/" + slug
+}
+
+// NewSlug returns an unguessable slug, so making a profile public does not make every profile
+// enumerable.
+func NewSlug() string {
+	return uuid.New().String()
+}
+
+// Find returns the volunteer behind a public profile slug. Private profiles are reported as not
+// found, exactly like unknown slugs.
+func Find(db *gorm.DB, slug string) (*model.User, error) {
+	var user model.User
+	err := db.Where("profile_slug = ? AND profile_visibility = ?", slug, model.ProfilePublic).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile: %w", err)
+	}
+	return &user, nil
+}
+
+var page = template.Must(template.New("profile").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} - Volunteer impact</title>
+<meta property="og:type" content="profile">
+<meta property="og:title" content="{{.Name}} - {{printf "%.0f" .Impact.TotalApprovedHours}} verified volunteer hours">
+<meta property="og:description" content="Helped {{.Impact.NonprofitsHelped}} nonprofits across {{len .Impact.CausesServed}} causes.">
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{with .User.Bio}}<p>{{.}}</p>{{end}}
+<ul>
+<li><strong>{{printf "%.1f" .Impact.TotalApprovedHours}}</strong> approved volunteer hours</li>
+<li><strong>{{.Impact.NonprofitsHelped}}</strong> nonprofits helped</li>
+<li><strong>{{.Impact.ProjectsCompleted}}</strong> projects completed</li>
+</ul>
+{{with .Impact.CausesServed}}<h2>Causes served</h2>
+<ul>{{range .}}<li>{{.Name}}</li>{{end}}</ul>{{end}}
+{{with .Impact.SkillsUsed}}<h2>Skills used</h2>
+<ul>{{range .}}<li>{{.Name}}</li>{{end}}</ul>{{end}}
+{{with .Impact.Endorsements}}<h2>Endorsed skills</h2>
+<ul>{{range .}}<li>{{.Skill.Name}} ({{.Endorsements}}{{if .Verified}}, verified{{end}})</li>{{end}}</ul>{{end}}
+<p><small>Volunteering since {{.User.CreatedAt.Format "January 2006"}}. Hours are approved by the nonprofits.</small></p>
+</body>
+</html>
+`))
+
+// Handler serves GET /profiles/{slug}.
+func Handler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := Find(db, chi.URLParam(r, "slug"))
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
+			return
+		}
+		impact, err := analytics.VolunteerImpact(db, user.ID)
+		if err != nil {
+			http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.Execute(w, struct {
+			Name   string
+			User   *model.User
+			Impact *model.ImpactSummary
+		}{user.FirstName + " " + user.LastName, user, impact})
+	}
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps buckets in process memory. It is the default for single-replica deployments
+// and development; use PostgresStore when several replicas must share limits.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, cost float64, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.Burst, updated: now}
+		s.buckets[key] = b
+	}
+
+	tokens, res := refill(b.tokens, now.Sub(b.updated), cost, limit)
+	b.tokens = tokens
+	b.updated = now
+	return res, nil
+}
+
+// Cleanup drops buckets that have been idle for longer than maxIdle. A full bucket and a missing
+// bucket behave the same, so this only bounds memory; run it periodically from a goroutine.
+func (s *MemoryStore) Cleanup(maxIdle time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-maxIdle)
+	for key, b := range s.buckets {
+		if b.updated.Before(cutoff) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type contextKey string
+
+const clientIPKey = contextKey("clientIP")
+
+// ClientIPFromContext returns the client IP stored by Middleware, or "" if none.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// Middleware limits requests per client IP. It must run after RealIP so that RemoteAddr reflects
+// the real client. The IP is also stored in the request context for the
+// GraphQL extension to fall back on for unauthenticated operations.
+func Middleware(store Store, limit Limit, logger *zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+			ctx := context.WithValue(r.Context(), clientIPKey, ip)
+
+			res, err := store.Take(ctx, "ip:"+ip, 1, limit)
+			if err != nil {
+				// Fail open: an unavailable limiter store should not take the API down with it.
+				logger.Error().Err(err).Str("ip", ip).Msg("Rate limiter unavailable")
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			if !res.Allowed {
+				w.Header().Set("Retry-After", retryAfterSeconds(res.RetryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// RealIP rewrites RemoteAddr without a port.
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
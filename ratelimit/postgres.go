@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitBucket is the persisted state of one token bucket, shared by all replicas.
+type RateLimitBucket struct {
+	Key       string `gorm:"primaryKey;type:varchar(255)"`
+	Tokens    float64
+	UpdatedAt time.Time `gorm:"index"`
+}
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so limits hold across replicas.
+// Each Take locks the bucket row for the duration of a short transaction.
+type PostgresStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, cost float64, limit Limit) (Result, error) {
+	var res Result
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Use the database clock so replicas with skewed clocks agree on refill time.
+		var now time.Time
+		if err := tx.Raw("SELECT now()").Scan(&now).Error; err != nil {
+			return err
+		}
+
+		// Make sure the row exists, then lock it.
+		seed := RateLimitBucket{Key: key, Tokens: limit.Burst, UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+			return err
+		}
+
+		var b RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&b, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		var tokens float64
+		tokens, res = refill(b.Tokens, now.Sub(b.UpdatedAt), cost, limit)
+		return tx.Model(&b).Updates(map[string]interface{}{"tokens": tokens, "updated_at": now}).Error
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit store: %w", err)
+	}
+	return res, nil
+}
+
+// Cleanup deletes buckets idle for longer than maxIdle.
+func (s *PostgresStore) Cleanup(ctx context.Context, maxIdle time.Duration) error {
+	return s.DB.WithContext(ctx).
+		Where("updated_at < ?", time.Now().Add(-maxIdle)).
+		Delete(&RateLimitBucket{}).Error
+}
+
+// KindCleanup is the job that runs PostgresStore.Cleanup.
+const KindCleanup = "ratelimit.cleanup"
+
+// CleanupHandler returns the KindCleanup job handler, deleting buckets idle for longer than
+// MaxIdle.
+func (s *PostgresStore) CleanupHandler() jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) error {
+		return s.Cleanup(ctx, MaxIdle)
+	}
+}
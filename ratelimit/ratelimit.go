@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket: it holds at most Burst tokens and refills at Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst float64
+}
+
+// PerMinute is a convenience for "n requests per minute, allowing bursts of n".
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: float64(n)}
+}
+
+// PerHour is a convenience for "n requests per hour, allowing bursts of n".
+func PerHour(n int) Limit {
+	return Limit{Rate: float64(n) / 3600, Burst: float64(n)}
+}
+
+// MaxIdle is how long a bucket may go untouched before the stores' Cleanup drops it.
+const MaxIdle = time.Hour
+
+// Result is the outcome of taking tokens from a bucket.
+type Result struct {
+	Allowed    bool
+	Remaining  float64
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets keyed by an arbitrary string (e.g. "ip:1.2.3.4" or "op:login:user:42").
+// Implementations must make Take atomic per key.
+type Store interface {
+	Take(ctx context.Context, key string, cost float64, limit Limit) (Result, error)
+}
+
+// refill computes the state of a bucket after elapsed time and an attempt to take cost tokens.
+// It returns the new token count and the result; tokens are only consumed when the take is allowed.
+func refill(tokens float64, elapsed time.Duration, cost float64, limit Limit) (float64, Result) {
+	if elapsed > 0 {
+		tokens = math.Min(limit.Burst, tokens+elapsed.Seconds()*limit.Rate)
+	}
+
+	if tokens >= cost {
+		tokens -= cost
+		return tokens, Result{Allowed: true, Remaining: tokens}
+	}
+
+	retryAfter := time.Duration(math.MaxInt64)
+	if limit.Rate > 0 {
+		retryAfter = time.Duration((cost - tokens) / limit.Rate * float64(time.Second))
+	}
+	return tokens, Result{Allowed: false, Remaining: tokens, RetryAfter: retryAfter}
+}
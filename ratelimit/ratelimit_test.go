@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRefill(t *testing.T) {
+	limit := PerMinute(60) // one token a second, up to 60
+	tests := []struct {
+		name       string
+		tokens     float64
+		elapsed    time.Duration
+		cost       float64
+		wantTokens float64
+		wantResult Result
+	}{
+		{"full bucket", 60, 0, 1, 59, Result{Allowed: true, Remaining: 59}},
+		{"refills with time", 0, 5 * time.Second, 1, 4, Result{Allowed: true, Remaining: 4}},
+		{"refills up to the burst", 10, time.Hour, 1, 59, Result{Allowed: true, Remaining: 59}},
+		{"takes the whole cost", 3, 0, 3, 0, Result{Allowed: true, Remaining: 0}},
+		{"empty bucket", 0, 0, 1, 0, Result{Allowed: false, Remaining: 0, RetryAfter: time.Second}},
+		{"denied takes nothing", 1.5, 0, 4, 1.5, Result{Allowed: false, Remaining: 1.5, RetryAfter: 2500 * time.Millisecond}},
+		{"clock going backwards does not drain", 5, -time.Minute, 1, 4, Result{Allowed: true, Remaining: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, res := refill(tt.tokens, tt.elapsed, tt.cost, limit)
+			if tokens != tt.wantTokens || res != tt.wantResult {
+				t.Fatalf("refill = %v, %+v, want %v, %+v", tokens, res, tt.wantTokens, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestRefillWithoutRate(t *testing.T) {
+	_, res := refill(0, time.Hour, 1, Limit{Burst: 1})
+	if res.Allowed || res.RetryAfter != time.Duration(math.MaxInt64) {
+		t.Fatalf("refill = %+v, want denied with no retry", res)
+	}
+}
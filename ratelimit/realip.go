@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TrustedProxies are the networks of the reverse proxies in front of the API. Only requests
+// coming from them may say who the client is through X-Forwarded-For or X-Real-IP; anyone else
+// could make up a new address for every request and never be limited.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or single IPs.
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", part)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			part = fmt.Sprintf("%s/%d", part, bits)
+		}
+		_, network, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", part, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// TrustedProxiesFromEnv reads TRUSTED_PROXIES, e.g. "10.0.0.0/8,127.0.0.1". Unset trusts nobody.
+func TrustedProxiesFromEnv() (TrustedProxies, error) {
+	return ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+}
+
+func (t TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP sets RemoteAddr to the client's IP. Forwarding headers are only believed when the
+// request comes from a trusted proxy; X-Forwarded-For is read from the right, skipping the
+// trusted proxies that appended to it, since anything left of them may be client-supplied.
+func RealIP(trusted TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, trusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns the client IP the trusted proxies forwarded, or "" to keep RemoteAddr.
+func forwardedIP(r *http.Request, trusted TrustedProxies) string {
+	peer := net.ParseIP(clientIP(r))
+	if peer == nil || !trusted.contains(peer) {
+		return ""
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// Nothing left of a malformed hop can be trusted
+				return ""
+			}
+			if !trusted.contains(ip) || i == 0 {
+				return ip.String()
+			}
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"10.0.0.0/8", []string{"10.0.0.0/8"}, false},
+		{" 10.0.0.0/8 , 127.0.0.1,", []string{"10.0.0.0/8", "127.0.0.1/32"}, false},
+		{"::1,fd00::/8", []string{"::1/128", "fd00::/8"}, false},
+		{"10.0.0.1/8", []string{"10.0.0.0/8"}, false},
+		{"localhost", nil, true},
+		{"10.0.0.0/33", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(proxies) != len(tt.want) {
+				t.Fatalf("got %v, want %v", proxies, tt.want)
+			}
+			for i, network := range proxies {
+				if network.String() != tt.want[i] {
+					t.Errorf("proxy %d = %s, want %s", i, network, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForwardedIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{"untrusted peer is not believed", "203.0.113.9:4000", []string{"198.51.100.1"}, "198.51.100.2", ""},
+		{"single hop", "10.0.0.1:4000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"rightmost untrusted hop", "10.0.0.1:4000", []string{"192.0.2.66, 198.51.100.1"}, "", "198.51.100.1"},
+		{"skips trusted proxies", "10.0.0.1:4000", []string{"198.51.100.1, 10.0.0.7, 10.0.0.8"}, "", "198.51.100.1"},
+		{"joins repeated headers", "10.0.0.1:4000", []string{"192.0.2.66", "198.51.100.1, 10.0.0.7"}, "", "198.51.100.1"},
+		{"only trusted hops", "10.0.0.1:4000", []string{"10.0.0.7, 10.0.0.8"}, "", "10.0.0.7"},
+		{"malformed hop", "10.0.0.1:4000", []string{"198.51.100.1, garbage, 10.0.0.7"}, "", ""},
+		{"X-Real-IP without X-Forwarded-For", "10.0.0.1:4000", nil, "198.51.100.1", "198.51.100.1"},
+		{"nothing forwarded", "10.0.0.1:4000", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, xff := range tt.xff {
+				r.Header.Add("X-Forwarded-For", xff)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := forwardedIP(r, trusted); got != tt.want {
+				t.Fatalf("forwardedIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
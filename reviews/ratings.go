@@ -0,0 +1,85 @@
+package reviews
+
+import (
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// NonprofitRating aggregates volunteers' ratings of a nonprofit.
+func NonprofitRating(db *gorm.DB, nonprofitID uint) (*model.RatingSummary, error) {
+	return summary(db, "rating", "nonprofit_id = ? AND direction = ?", nonprofitID, model.ReviewOfNonprofit)
+}
+
+// ProjectRating aggregates volunteers' ratings of a project.
+func ProjectRating(db *gorm.DB, projectID uint) (*model.RatingSummary, error) {
+	return summary(db, "project_rating", "project_id = ? AND direction = ?", projectID, model.ReviewOfNonprofit)
+}
+
+// VolunteerRating aggregates coordinators' ratings of a volunteer.
+func VolunteerRating(db *gorm.DB, volunteerID uint) (*model.RatingSummary, error) {
+	return summary(db, "rating", "volunteer_id = ? AND direction = ?", volunteerID, model.ReviewOfVolunteer)
+}
+
+// summary averages column over the visible reviews matching the condition. column is always a
+// constant from this file, never user input.
+func summary(db *gorm.DB, column, condition string, args ...interface{}) (*model.RatingSummary, error) {
+	var row struct {
+		Average *float64
+		Count   int32
+	}
+	if err := db.Model(&model.Review{}).
+		Select(fmt.Sprintf("AVG(%[1]s) AS average, COUNT(%[1]s) AS count", column)).
+		Where(condition, args...).
+		Where("hidden = ?", false).
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate ratings: %w", err)
+	}
+	return &model.RatingSummary{Average: row.Average, Count: row.Count}, nil
+}
+
+// Badges lists the skills a volunteer was endorsed for, most endorsed first. A badge is
+// verified once a verified nonprofit has endorsed the skill. Endorsements given in a review that
+// moderators hid do not count.
+func Badges(db *gorm.DB, volunteerID uint) ([]*model.SkillBadge, error) {
+	var rows []struct {
+		SkillID      uint
+		Endorsements int32
+		Verified     bool
+	}
+	if err := db.Model(&model.SkillEndorsement{}).
+		Select("skill_endorsements.skill_id, COUNT(*) AS endorsements, BOOL_OR(nonprofits.verified) AS verified").
+		Joins("JOIN nonprofits ON nonprofits.id = skill_endorsements.nonprofit_id").
+		Joins("JOIN reviews ON reviews.engagement_id = skill_endorsements.engagement_id AND reviews.direction = ? AND reviews.deleted_at IS NULL", model.ReviewOfVolunteer).
+		Where("skill_endorsements.volunteer_id = ? AND reviews.hidden = ?", volunteerID, false).
+		Group("skill_endorsements.skill_id").
+		Order("endorsements DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate endorsements: %w", err)
+	}
+	if len(rows) == 0 {
+		return []*model.SkillBadge{}, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.SkillID
+	}
+	var skills []model.Skill
+	if err := db.Where("id IN ?", ids).Find(&skills).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch skills: %w", err)
+	}
+	byID := make(map[uint]*model.Skill, len(skills))
+	for i := range skills {
+		byID[skills[i].ID] = &skills[i]
+	}
+
+	badges := make([]*model.SkillBadge, 0, len(rows))
+	for _, row := range rows {
+		if skill, ok := byID[row.SkillID]; ok {
+			badges = append(badges, &model.SkillBadge{Skill: skill, Endorsements: row.Endorsements, Verified: row.Verified})
+		}
+	}
+	return badges, nil
+}
@@ -0,0 +1,127 @@
+// Package reviews handles the two-way ratings left after an engagement, the skill endorsements
+// that come with them, and their moderation.
+package reviews
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+var ErrAlreadyReviewed = errors.New("you have already reviewed this engagement")
+
+// Submit records a review of a completed engagement, together with any skill endorsements on a
+// review of the volunteer. engagement must have its Project loaded. Run it inside a transaction.
+func Submit(tx *gorm.DB, engagement *model.Engagement, reviewer *model.User, direction model.ReviewDirection, input model.ReviewInput) (*model.Review, error) {
+	if engagement.Status != model.EngagementCompleted {
+		return nil, fmt.Errorf("only completed engagements can be reviewed (current status: %s)", engagement.Status)
+	}
+
+	var existing int64
+	if err := tx.Model(&model.Review{}).
+		Where("engagement_id = ? AND direction = ?", engagement.ID, direction).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing reviews: %w", err)
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyReviewed
+	}
+
+	review := model.Review{
+		EngagementID: engagement.ID,
+		Direction:    direction,
+		ReviewerID:   reviewer.ID,
+		VolunteerID:  engagement.VolunteerID,
+		NonprofitID:  engagement.Project.NonprofitID,
+		ProjectID:    engagement.ProjectID,
+		Rating:       int(input.Rating),
+		Comment:      input.Comment,
+	}
+	if input.ProjectRating != nil {
+		rating := int(*input.ProjectRating)
+		review.ProjectRating = &rating
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+
+	for _, name := range input.EndorsedSkills {
+		var skill model.Skill
+		if err := tx.Where("name = ?", name).First(&skill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("skill '%s' not found", name)
+			}
+			return nil, fmt.Errorf("failed to fetch skill: %w", err)
+		}
+		// Only skills the project asked for or the volunteer offers can be endorsed
+		var relevant int64
+		if err := tx.Raw(`SELECT
+				(SELECT COUNT(*) FROM project_skills WHERE project_id = ? AND skill_id = ?) +
+				(SELECT COUNT(*) FROM user_skills WHERE user_id = ? AND skill_id = ?)`,
+			engagement.ProjectID, skill.ID, engagement.VolunteerID, skill.ID).Scan(&relevant).Error; err != nil {
+			return nil, fmt.Errorf("failed to check skill: %w", err)
+		}
+		if relevant == 0 {
+			return nil, fmt.Errorf("skill '%s' is neither needed by the project nor listed by the volunteer", name)
+		}
+
+		endorsement := model.SkillEndorsement{
+			EngagementID: engagement.ID,
+			SkillID:      skill.ID,
+			VolunteerID:  engagement.VolunteerID,
+			NonprofitID:  engagement.Project.NonprofitID,
+			EndorserID:   reviewer.ID,
+		}
+		if err := tx.Create(&endorsement).Error; err != nil {
+			return nil, fmt.Errorf("failed to save endorsement for '%s': %w", name, err)
+		}
+	}
+	return &review, nil
+}
+
+// Flag reports a review for moderation. Each user can flag a review once.
+func Flag(db *gorm.DB, reviewID uint, user *model.User, reason string) error {
+	var count int64
+	if err := db.Model(&model.ReviewFlag{}).
+		Where("review_id = ? AND flagged_by_id = ?", reviewID, user.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check existing flags: %w", err)
+	}
+	if count > 0 {
+		return errors.New("you have already flagged this review")
+	}
+
+	flag := model.ReviewFlag{ReviewID: reviewID, FlaggedByID: user.ID, Reason: reason}
+	if err := db.Create(&flag).Error; err != nil {
+		return fmt.Errorf("failed to flag review: %w", err)
+	}
+	return nil
+}
+
+// Moderate hides or restores a review and resolves its open flags. Run it inside a transaction.
+func Moderate(tx *gorm.DB, review *model.Review, admin *model.User, hidden bool) error {
+	if err := tx.Model(review).Update("hidden", hidden).Error; err != nil {
+		return fmt.Errorf("failed to moderate review: %w", err)
+	}
+	if err := tx.Model(&model.ReviewFlag{}).
+		Where("review_id = ? AND resolved_at IS NULL", review.ID).
+		Updates(map[string]interface{}{"resolved_at": time.Now(), "resolved_by_id": admin.ID}).Error; err != nil {
+		return fmt.Errorf("failed to resolve review flags: %w", err)
+	}
+	return nil
+}
+
+// FlaggedReviews returns reviews with unresolved flags, most flagged first.
+func FlaggedReviews(db *gorm.DB) ([]*model.Review, error) {
+	var reviews []*model.Review
+	if err := db.Joins("JOIN review_flags ON review_flags.review_id = reviews.id AND review_flags.resolved_at IS NULL AND review_flags.deleted_at IS NULL").
+		Group("reviews.id").
+		Order("COUNT(review_flags.id) DESC, reviews.created_at ASC").
+		Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch flagged reviews: %w", err)
+	}
+	return reviews, nil
+}
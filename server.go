@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -12,10 +17,23 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
+	"github.com/prkagrawal/cosmos-bk2/audit"
 	"github.com/prkagrawal/cosmos-bk2/auth"
+	"github.com/prkagrawal/cosmos-bk2/certificates"
 	"github.com/prkagrawal/cosmos-bk2/database"
+	"github.com/prkagrawal/cosmos-bk2/digests"
+	"github.com/prkagrawal/cosmos-bk2/export"
 	"github.com/prkagrawal/cosmos-bk2/graph"
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+	"github.com/prkagrawal/cosmos-bk2/notifications"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/prkagrawal/cosmos-bk2/persisted"
+	"github.com/prkagrawal/cosmos-bk2/profiles"
+	"github.com/prkagrawal/cosmos-bk2/ratelimit"
+	"github.com/prkagrawal/cosmos-bk2/storage"
+	"github.com/prkagrawal/cosmos-bk2/webhooks"
 	"github.com/rs/zerolog"
 	"github.com/vektah/gqlparser/v2/ast"
 
@@ -45,26 +63,106 @@ func main() {
 		logger.Fatal().Err(err).Msg("Database migration failed")
 	}
 
+	// Initialize email delivery (logged by default, SMTP with MAILER_DRIVER=smtp) and notifications
+	mail, err := mailer.NewFromEnv(&logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Mailer initialization failed")
+	}
+	notifier := notifications.NewFromEnv(database.DB, mail, &logger)
+	hooks := webhooks.New(database.DB, &logger)
+
+	// "serve" (the default) runs the API, "worker" only processes background jobs
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	switch command {
+	case "serve":
+		serve(&logger, mail, notifier, hooks)
+	case "worker":
+		runWorker(&logger, mail, notifier, hooks)
+	default:
+		logger.Fatal().Msgf("Unknown command %q, expected serve or worker", command)
+	}
+}
+
+// serve runs the GraphQL API, with an embedded job worker, scheduler and outbox relay unless
+// JOBS_EMBEDDED_WORKER=false.
+func serve(logger *zerolog.Logger, mail mailer.Mailer, notifier *notifications.Notifier, hooks *webhooks.Dispatcher) {
+	if os.Getenv("JOBS_EMBEDDED_WORKER") != "false" {
+		worker, err := newWorker(logger, mail, notifier, hooks)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Job worker initialization failed")
+		}
+		go worker.Run(context.Background())
+
+		scheduler, err := newScheduler(logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Job scheduler initialization failed")
+		}
+		go scheduler.Run(context.Background())
+
+		go newRelay(logger, notifier, hooks).Run(context.Background())
+	}
+
+	// Initialize rate limiter store. Use Postgres when running multiple replicas so limits are shared.
+	var limitStore ratelimit.Store
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		limitStore = ratelimit.NewPostgresStore(database.DB)
+	} else {
+		memStore := ratelimit.NewMemoryStore()
+		go func() {
+			for range time.Tick(10 * time.Minute) {
+				memStore.Cleanup(ratelimit.MaxIdle)
+			}
+		}()
+		limitStore = memStore
+	}
+
+	// Only proxies we run may tell us who the client is, see ratelimit.RealIP
+	trustedProxies, err := ratelimit.TrustedProxiesFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+
 	// Create router
 	router := chi.NewRouter()
 
 	// Add middleware
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
-	router.Use(zerologLogger(&logger))
+	router.Use(ratelimit.RealIP(trustedProxies))
+	router.Use(ratelimit.Middleware(limitStore, ratelimit.PerMinute(300), logger))
+	router.Use(zerologLogger(logger))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
 	router.Use(auth.AuthMiddleware())
 
 	// Initialize auth service
-	authSvc := auth.NewAuthService(database.DB)
+	authSvc := auth.NewAuthService(database.DB, logger)
 
 	// Setup routes
 	router.HandleFunc("/auth/google", auth.GoogleLoginHandler)
 	router.HandleFunc("/auth/google/callback", auth.GoogleCallbackHandler(authSvc))
 
+	// Initialize file storage for uploads (local disk by default, S3-compatible with STORAGE_DRIVER=s3)
+	fileStore, err := storage.NewFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Storage initialization failed")
+	}
+	if localStore, ok := fileStore.(*storage.LocalStore); ok {
+		router.Handle("/uploads/*", http.StripPrefix("/uploads", localStore.Handler()))
+	}
+
 	// Create the main resolver, passing in dependencies
-	resolver := graph.NewResolver(database.DB)
+	resolver := graph.NewResolver(database.DB, fileStore, authSvc)
+
+	// Push new messages and project activity to the subscriptions on this replica, whichever
+	// replica they happened on
+	listener := outbox.NewListener(database.DB, logger)
+	listener.Handle(outbox.MessageSent, resolver.Messages.Relay(database.DB, logger))
+	resolver.Activity.Listen(listener, database.DB)
+	listener.Handle(outbox.PersistedQueriesChanged, resolver.PersistedQueries.Reload(logger))
+	go listener.Run(context.Background())
 
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
 
@@ -73,6 +171,45 @@ func main() {
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
 	srv.AddTransport(transport.MultipartForm{})
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			// Accept same-origin connections and those from the frontend
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || origin == strings.TrimRight(os.Getenv("FRONTEND_URL"), "/") {
+					return true
+				}
+				u, err := url.Parse(origin)
+				return err == nil && strings.EqualFold(u.Host, r.Host)
+			},
+		},
+		// Browsers can't set headers on websockets, so the token comes in the connection_init payload
+		InitFunc: func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			token := payload.Authorization()
+			if token == "" {
+				return ctx, nil, nil
+			}
+			ctx, err := auth.ContextWithToken(ctx, token)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid token: %w", err)
+			}
+			return ctx, nil, nil
+		},
+	})
+
+	// Load persisted operations registered through the admin mutation, plus the frontend's
+	// build-time manifest if one is configured
+	if err := resolver.PersistedQueries.LoadFromDB(context.Background()); err != nil {
+		logger.Fatal().Err(err).Msg("Loading persisted queries failed")
+	}
+	if manifestPath := os.Getenv("PERSISTED_QUERIES_MANIFEST"); manifestPath != "" {
+		n, err := resolver.PersistedQueries.LoadFile(manifestPath)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Loading persisted query manifest failed")
+		}
+		logger.Info().Int("operations", n).Str("path", manifestPath).Msg("Persisted query manifest loaded")
+	}
 
 	// Configure extensions
 	if os.Getenv("ENVIRONMENT") == "development" {
@@ -80,14 +217,51 @@ func main() {
 		srv.Use(extension.AutomaticPersistedQuery{
 			Cache: lru.New[string](100),
 		})
+	} else if mode := persisted.Mode(os.Getenv("PERSISTED_QUERIES_MODE")); mode != persisted.ModeOff {
+		// Hash-only requests are resolved from the allowlist; clients cannot register new hashes
+		srv.Use(extension.AutomaticPersistedQuery{
+			Cache: resolver.PersistedQueries,
+		})
+		srv.Use(persisted.Allowlist{
+			Store: resolver.PersistedQueries,
+			Mode:  mode,
+		})
 	}
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 	srv.Use(extension.FixedComplexityLimit(300))
 
+	// Per-user (or per-IP when unauthenticated) limits on individual operations
+	srv.Use(&ratelimit.Extension{
+		Store: limitStore,
+		Rules: map[string]ratelimit.Rule{
+			"login":             {Limit: ratelimit.PerMinute(10), Cost: 1},
+			"signup":            {Limit: ratelimit.PerHour(10), Cost: 1},
+			"refreshToken":      {Limit: ratelimit.PerMinute(10), Cost: 1},
+			"applyToProject":    {Limit: ratelimit.PerHour(30), Cost: 1},
+			"verifyCertificate": {Limit: ratelimit.PerMinute(30), Cost: 1},
+		},
+		Default: &ratelimit.Rule{Limit: ratelimit.PerMinute(120), Cost: 1},
+		Key: func(ctx context.Context) string {
+			if id, ok := auth.UserIDFromContext(ctx); ok {
+				return fmt.Sprintf("user:%d", id)
+			}
+			return ""
+		},
+		Logger: logger,
+	})
+
+	// Record every mutation in the audit log
+	srv.Use(&audit.Extension{DB: database.DB, Logger: logger})
+
 	// Setup GraphQL routes
 	router.Handle("/", playground.Handler("GraphQL Playground", "/query"))
 	router.Handle("/query", srv)
+	router.Get("/projects/{id}/applications.csv", export.ApplicationsCSVHandler(database.DB, resolver.Repos.Nonprofits))
+	router.Get("/certificates/{id}.pdf", certificates.PDFHandler(database.DB))
+	router.Get("/profiles/{slug}", profiles.Handler(database.DB))
+	router.HandleFunc(digests.UnsubscribePath, digests.UnsubscribeHandler(database.DB))
+	router.Handle("/debug/vars", auth.RequirePlatformAdmin(expvar.Handler()))
 
 	// Start server
 	port := os.Getenv("PORT")
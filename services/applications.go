@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// ApplicationService manages volunteers' applications to projects and the nonprofits' decisions
+// on them.
+type ApplicationService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewApplicationService(work UnitOfWork, repos Repositories) *ApplicationService {
+	return &ApplicationService{Work: work, Repos: repos}
+}
+
+// Apply applies volunteer to an active project, optionally for one of its role slots. answers
+// must fit the project's screening questions. Volunteers can apply to a project once.
+func (s *ApplicationService) Apply(ctx context.Context, volunteer *model.User, projectID uint, message *string, slotID *uint, answers []*model.AnswerInput) (*model.Application, error) {
+	if volunteer.Role != model.Volunteer {
+		return nil, errors.New("only volunteers can apply to projects")
+	}
+	if err := validation.ApplicationMessage(message); err != nil {
+		return nil, err
+	}
+
+	project, err := s.Repos.Projects.Find(projectID)
+	if err == nil && project.Status != model.Active {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("active project with ID %d not found or not accepting applications", projectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	_, err = s.Repos.Applications.ForVolunteer(volunteer.ID, project.ID)
+	if err == nil {
+		return nil, errors.New("you have already applied to this project")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("error checking existing application: %w", err)
+	}
+
+	questions, err := s.Repos.Projects.Questions(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch application questions: %w", err)
+	}
+	if err := validation.Answers(questions, answers); err != nil {
+		return nil, err
+	}
+
+	if slotID != nil {
+		slot, err := s.Repos.Projects.RoleSlot(project.ID, *slotID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("role slot with ID %d not found on this project", *slotID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch role slot: %w", err)
+		}
+		slotID = &slot.ID
+	}
+
+	application := model.Application{
+		VolunteerID: volunteer.ID,
+		ProjectID:   project.ID,
+		SlotID:      slotID,
+		Message:     message,
+		Status:      model.Pending,
+		AppliedAt:   time.Now(),
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&application).Error; err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+		for _, answer := range answers {
+			questionID, err := utils.IdToUint(answer.QuestionID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&model.ApplicationAnswer{
+				ApplicationID: application.ID,
+				QuestionID:    questionID,
+				Values:        model.Strings(answer.Values),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to save application answer: %w", err)
+			}
+		}
+		return outbox.Record(tx, outbox.ApplicationSubmitted, outbox.ApplicationPayload{
+			ApplicationID: application.ID,
+			ProjectID:     application.ProjectID,
+			VolunteerID:   application.VolunteerID,
+			To:            application.Status,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(application.ID)
+}
+
+// Accept accepts a pending or waitlisted application, or waitlists it if the project is full.
+// An accepted volunteer's engagement is started right away if autoStartEngagement is set.
+// Members of the project's nonprofit and platform admins can.
+func (s *ApplicationService) Accept(ctx context.Context, user *model.User, id uint, autoStartEngagement bool) (*model.Application, error) {
+	application, err := s.undecided(user, id, "accept applications")
+	if err != nil {
+		return nil, err
+	}
+
+	// Accepting and starting the engagement happen atomically, so a volunteer is never left
+	// accepted with a half-created engagement
+	return s.change(ctx, application, func(tx *gorm.DB) error {
+		if err := lifecycle.AcceptOrWaitlist(tx, application); err != nil {
+			return fmt.Errorf("failed to accept application: %w", err)
+		}
+		if application.Status == model.Accepted && autoStartEngagement {
+			if _, err := lifecycle.StartEngagement(tx, application.VolunteerID, application.ProjectID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reject rejects a pending or waitlisted application. Members of the project's nonprofit and
+// platform admins can.
+func (s *ApplicationService) Reject(ctx context.Context, user *model.User, id uint) (*model.Application, error) {
+	application, err := s.undecided(user, id, "reject applications")
+	if err != nil {
+		return nil, err
+	}
+	return s.change(ctx, application, func(tx *gorm.DB) error {
+		if err := lifecycle.TransitionApplication(tx, application, model.Rejected); err != nil {
+			return fmt.Errorf("failed to reject application: %w", err)
+		}
+		return nil
+	})
+}
+
+// Withdraw withdraws volunteer's own application, unless they have already started on the
+// project. An accepted volunteer stepping back frees a place for the next one on the waitlist.
+func (s *ApplicationService) Withdraw(ctx context.Context, volunteer *model.User, id uint) (*model.Application, error) {
+	application, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if application.VolunteerID != volunteer.ID {
+		return nil, errors.New("unauthorized: you can only withdraw your own applications")
+	}
+
+	// Once work has started the engagement has to be completed instead
+	active, err := s.Repos.Engagements.CountActive(volunteer.ID, application.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking existing engagement: %w", err)
+	}
+	if active > 0 {
+		return nil, errors.New("you have already started volunteering on this project; complete the engagement instead")
+	}
+
+	wasAccepted := application.Status == model.Accepted
+	return s.change(ctx, application, func(tx *gorm.DB) error {
+		if err := lifecycle.TransitionApplication(tx, application, model.Withdrawn); err != nil {
+			return fmt.Errorf("failed to withdraw application: %w", err)
+		}
+		if wasAccepted {
+			if _, err := lifecycle.PromoteFromWaitlist(tx, application.ProjectID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reopen puts a rejected or expired application back to pending, as long as the project still
+// takes volunteers. Members of the project's nonprofit and platform admins can. Withdrawn
+// applications stay withdrawn; the volunteer chose to leave.
+func (s *ApplicationService) Reopen(ctx context.Context, user *model.User, id uint) (*model.Application, error) {
+	application, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, application.Project.NonprofitID, "reopen applications"); err != nil {
+		return nil, err
+	}
+	if application.Status == model.Withdrawn {
+		return nil, errors.New("withdrawn applications cannot be reopened")
+	}
+	if project := application.Project; project.Status != model.Active && project.Status != model.InProgress {
+		return nil, fmt.Errorf("project is not accepting volunteers (current status: %s)", project.Status)
+	}
+
+	return s.change(ctx, application, func(tx *gorm.DB) error {
+		if err := lifecycle.TransitionApplication(tx, application, model.Pending); err != nil {
+			return fmt.Errorf("failed to reopen application: %w", err)
+		}
+		return nil
+	})
+}
+
+// Answers returns an application's answers to the project's screening questions. Only the
+// applicant, members of the project's nonprofit and platform admins see them; anyone else,
+// including a nil user, gets none.
+func (s *ApplicationService) Answers(user *model.User, id uint) ([]*model.ApplicationAnswer, error) {
+	if user == nil {
+		return []*model.ApplicationAnswer{}, nil
+	}
+	application, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if application.VolunteerID != user.ID {
+		_, ok, err := ProjectActor(s.Repos.Nonprofits, user, application.Project.NonprofitID)
+		if err != nil || !ok {
+			return []*model.ApplicationAnswer{}, err
+		}
+	}
+	answers, err := s.Repos.Applications.Answers(application.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch application answers: %w", err)
+	}
+	return answers, nil
+}
+
+func (s *ApplicationService) find(id uint) (*model.Application, error) {
+	application, err := s.Repos.Applications.Find(id)
+	return find(application, err, "application", id)
+}
+
+// undecided loads an application still waiting for a decision that user may make.
+func (s *ApplicationService) undecided(user *model.User, id uint, action string) (*model.Application, error) {
+	application, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, application.Project.NonprofitID, action); err != nil {
+		return nil, err
+	}
+	if application.Status != model.Pending && application.Status != model.Waitlisted {
+		return nil, fmt.Errorf("application is not pending (current status: %s)", application.Status)
+	}
+	return application, nil
+}
+
+// change runs apply in a transaction that records the change to application in the audit log.
+func (s *ApplicationService) change(ctx context.Context, application *model.Application, apply func(tx *gorm.DB) error) (*model.Application, error) {
+	before := *application
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := apply(tx); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Change("Application", application.ID, application.Project.NonprofitID, &before, application))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(application.ID)
+}
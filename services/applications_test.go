@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestApplicationServiceDecisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		decide  string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"member accepts", "accept", memberID, pendingApplicationID, ""},
+		{"admin accepts", "accept", adminID, pendingApplicationID, ""},
+		{"outsider cannot accept", "accept", outsiderID, pendingApplicationID, "unauthorized"},
+		{"applicant cannot accept", "accept", volunteerID, pendingApplicationID, "unauthorized"},
+		{"decided applications stay decided", "accept", memberID, rejectedApplicationID, "not pending"},
+		{"member rejects", "reject", memberID, pendingApplicationID, ""},
+		{"outsider cannot reject", "reject", outsiderID, pendingApplicationID, "unauthorized"},
+		{"accepted cannot be rejected", "reject", memberID, acceptedApplicationID, "not pending"},
+		{"member reopens rejected", "reopen", memberID, rejectedApplicationID, ""},
+		{"outsider cannot reopen", "reopen", outsiderID, rejectedApplicationID, "unauthorized"},
+		{"withdrawn stay withdrawn", "reopen", memberID, withdrawnApplicationID, "withdrawn applications cannot be reopened"},
+		{"closed projects take no one", "reopen", memberID, closedApplicationID, "not accepting volunteers"},
+		{"missing application", "accept", memberID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewApplicationService(work, store.repos())
+			ctx, user := context.Background(), store.user(tt.userID)
+			var err error
+			switch tt.decide {
+			case "accept":
+				_, err = s.Accept(ctx, user, tt.id, false)
+			case "reject":
+				_, err = s.Reject(ctx, user, tt.id)
+			case "reopen":
+				_, err = s.Reopen(ctx, user, tt.id)
+			}
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestApplicationServiceWithdraw(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		engaged bool
+		wantErr string
+	}{
+		{"applicant withdraws", volunteerID, pendingApplicationID, false, ""},
+		{"accepted applicant withdraws before starting", volunteerID, acceptedApplicationID, false, ""},
+		{"accepted applicant who started", volunteerID, acceptedApplicationID, true, "complete the engagement instead"},
+		{"member cannot withdraw for them", memberID, pendingApplicationID, false, "unauthorized"},
+		{"platform admin cannot withdraw for them", adminID, pendingApplicationID, false, "unauthorized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			if !tt.engaged {
+				delete(store.engagements, activeEngagementID)
+			}
+			s := NewApplicationService(work, store.repos())
+			_, err := s.Withdraw(context.Background(), store.user(tt.userID), tt.id)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestApplicationServiceAnswers(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID uint // 0 for an anonymous caller
+		want   int
+	}{
+		{"applicant", volunteerID, 1},
+		{"member", memberID, 1},
+		{"platform admin", adminID, 1},
+		{"outsider", outsiderID, 0},
+		{"anonymous", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			s := NewApplicationService(&fakeWork{}, store.repos())
+			var user *model.User
+			if tt.userID != 0 {
+				user = store.user(tt.userID)
+			}
+			answers, err := s.Answers(user, pendingApplicationID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(answers) != tt.want {
+				t.Fatalf("got %d answers, want %d", len(answers), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/certificates"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// EngagementService starts and ends volunteers' engagements on projects and signs off the hours
+// they log.
+type EngagementService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewEngagementService(work UnitOfWork, repos Repositories) *EngagementService {
+	return &EngagementService{Work: work, Repos: repos}
+}
+
+// Start starts volunteer's engagement on a project they were accepted to.
+func (s *EngagementService) Start(ctx context.Context, volunteer *model.User, projectID uint) (*model.Engagement, error) {
+	application, err := s.Repos.Applications.ForVolunteer(volunteer.ID, projectID)
+	if err == nil && application.Status != model.Accepted {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, errors.New("no accepted application found for you on this project, or you are not the volunteer")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accepted application: %w", err)
+	}
+
+	var engagement *model.Engagement
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		engagement, err = lifecycle.StartEngagement(tx, volunteer.ID, projectID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(engagement.ID)
+}
+
+// Complete completes volunteer's own active engagement, storing their feedback if given.
+func (s *EngagementService) Complete(ctx context.Context, volunteer *model.User, id uint, feedback *string) (*model.Engagement, error) {
+	engagement, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if engagement.VolunteerID != volunteer.ID {
+		return nil, errors.New("unauthorized to complete this engagement")
+	}
+	if engagement.Status != model.EngagementActive {
+		return nil, fmt.Errorf("engagement is not active (current status: %s)", engagement.Status)
+	}
+
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		return lifecycle.CompleteEngagement(tx, engagement, volunteer, feedback)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(engagement.ID)
+}
+
+// Cancel cancels an active engagement, giving the volunteer's place to the next one on the
+// waitlist. The volunteer, members of the project's nonprofit and platform admins can.
+func (s *EngagementService) Cancel(ctx context.Context, user *model.User, id uint) (*model.Engagement, error) {
+	engagement, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if engagement.VolunteerID != user.ID {
+		if err := RequireNonprofitMember(s.Repos.Nonprofits, user, engagement.Project.NonprofitID, "cancel engagements"); err != nil {
+			return nil, err
+		}
+	}
+	if engagement.Status != model.EngagementActive {
+		return nil, fmt.Errorf("engagement is not active (current status: %s)", engagement.Status)
+	}
+
+	before := *engagement
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := lifecycle.CancelEngagement(tx, engagement, user, nil); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Change("Engagement", engagement.ID, engagement.Project.NonprofitID, &before, engagement))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(engagement.ID)
+}
+
+// LogHours logs hours volunteer worked on their own active engagement. They stay unapproved until
+// the nonprofit reviews them.
+func (s *EngagementService) LogHours(ctx context.Context, volunteer *model.User, engagementID uint, hours float64, date string, description *string) (*model.HoursLogged, error) {
+	if err := validation.LogHours(hours, date, description); err != nil {
+		return nil, err
+	}
+
+	engagement, err := s.Repos.Engagements.Find(engagementID)
+	if err == nil && engagement.VolunteerID != volunteer.ID {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, errors.New("active engagement not found for you, or you are not the volunteer")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch engagement: %w", err)
+	}
+	if engagement.Status != model.EngagementActive {
+		return nil, errors.New("cannot log hours for an engagement that is not active")
+	}
+
+	dateUsable, err := utils.ParseDateTimeString(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date for hours logged: %w", err)
+	}
+
+	entry := model.HoursLogged{
+		EngagementID: engagement.ID,
+		Date:         dateUsable,
+		Hours:        hours,
+		Description:  description,
+		// Approved defaults to null/false depending on DB. Approval is a separate step.
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to log hours: %w", err)
+		}
+		return outbox.Record(tx, outbox.HoursLogged, outbox.HoursPayload{
+			HoursID:      entry.ID,
+			EngagementID: entry.EngagementID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findHours(entry.ID)
+}
+
+// ReviewHours approves or rejects logged hours. Hours are signed off by the nonprofit, never by
+// the volunteer themselves: members of the project's nonprofit and platform admins can.
+func (s *EngagementService) ReviewHours(ctx context.Context, user *model.User, id uint, approved bool) (*model.HoursLogged, error) {
+	entry, err := s.findHours(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, entry.Engagement.Project.NonprofitID, "review hours"); err != nil {
+		return nil, err
+	}
+	if entry.Engagement.VolunteerID == user.ID {
+		return nil, errors.New("unauthorized: you cannot review your own hours")
+	}
+
+	now := time.Now()
+	before := *entry
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(entry).Updates(map[string]interface{}{
+			"approved":       approved,
+			"approved_at":    now,
+			"approved_by_id": user.ID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to review hours: %w", err)
+		}
+		entry.Approved, entry.ApprovedAt, entry.ApprovedByID = &approved, &now, &user.ID
+		if err := audit.Record(ctx, tx, audit.Change("HoursLogged", entry.ID, entry.Engagement.Project.NonprofitID, &before, entry)); err != nil {
+			return err
+		}
+		return outbox.Record(tx, outbox.HoursReviewed, outbox.HoursPayload{
+			HoursID:      entry.ID,
+			EngagementID: entry.EngagementID,
+			Approved:     &approved,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findHours(entry.ID)
+}
+
+// IssueHoursStatement issues volunteer a certificate of their approved hours between from and to,
+// either of which may be nil for an open range.
+func (s *EngagementService) IssueHoursStatement(ctx context.Context, volunteer *model.User, from, to *time.Time) (*model.Certificate, error) {
+	var cert *model.Certificate
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		cert, err = certificates.Issue(tx, volunteer, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+func (s *EngagementService) find(id uint) (*model.Engagement, error) {
+	engagement, err := s.Repos.Engagements.Find(id)
+	return find(engagement, err, "engagement", id)
+}
+
+func (s *EngagementService) findHours(id uint) (*model.HoursLogged, error) {
+	entry, err := s.Repos.Hours.Find(id)
+	return find(entry, err, "hours entry", id)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEngagementServiceCancel(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"volunteer", volunteerID, activeEngagementID, ""},
+		{"member", memberID, activeEngagementID, ""},
+		{"platform admin", adminID, activeEngagementID, ""},
+		{"outsider", outsiderID, activeEngagementID, "unauthorized"},
+		{"completed engagement", volunteerID, completedEngagementID, "not active"},
+		{"missing engagement", volunteerID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewEngagementService(work, store.repos())
+			_, err := s.Cancel(context.Background(), store.user(tt.userID), tt.id)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestEngagementServiceLogHours(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.RFC3339)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		hours   float64
+		date    string
+		wantErr string
+	}{
+		{"volunteer", volunteerID, activeEngagementID, 3, yesterday, ""},
+		{"member cannot log for them", memberID, activeEngagementID, 3, yesterday, "not the volunteer"},
+		{"platform admin cannot log for them", adminID, activeEngagementID, 3, yesterday, "not the volunteer"},
+		{"completed engagement", volunteerID, completedEngagementID, 3, yesterday, "not active"},
+		{"missing engagement", volunteerID, missingID, 3, yesterday, "not the volunteer"},
+		{"no hours", volunteerID, activeEngagementID, 0, yesterday, "hours"},
+		{"more than a day", volunteerID, activeEngagementID, 25, yesterday, "hours"},
+		{"in the future", volunteerID, activeEngagementID, 3, tomorrow, "date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			// The fake unit of work assigns no IDs, so the new entry is reloaded as ID 0
+			store.hours[0] = store.hours[hoursID]
+			s := NewEngagementService(work, store.repos())
+			_, err := s.LogHours(context.Background(), store.user(tt.userID), tt.id, tt.hours, tt.date, nil)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestEngagementServiceReviewHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		selfLogged bool
+		id         uint
+		wantErr    string
+	}{
+		{"member", memberID, false, hoursID, ""},
+		{"platform admin", adminID, false, hoursID, ""},
+		{"outsider", outsiderID, false, hoursID, "unauthorized"},
+		{"volunteer", volunteerID, false, hoursID, "unauthorized"},
+		{"member reviewing their own hours", memberID, true, hoursID, "cannot review your own hours"},
+		{"missing entry", memberID, false, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			if tt.selfLogged {
+				store.hours[hoursID].Engagement.VolunteerID = tt.userID
+			}
+			s := NewEngagementService(work, store.repos())
+			_, err := s.ReviewHours(context.Background(), store.user(tt.userID), tt.id, true)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
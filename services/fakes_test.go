@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// fakeWork stands in for the database transaction. It records that a service went on to write
+// but does not run the writes, which need Postgres, so tests using it cover only the
+// authorization and validation done before them. The rules applied inside the transaction, such
+// as capacity and the waitlist, are tested against Postgres in transactions_test.go.
+type fakeWork struct{ calls int }
+
+func (w *fakeWork) Do(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	w.calls++
+	return nil
+}
+
+// IDs of the records in a newFakeStore.
+const (
+	adminID     uint = 1 // platform admin
+	memberID    uint = 2 // member of nonprofitID
+	outsiderID  uint = 3 // nonprofit admin of no nonprofit in particular
+	volunteerID uint = 4 // applied to and engaged on activeProjectID
+
+	nonprofitID uint = 10
+
+	activeProjectID    uint = 20
+	completedProjectID uint = 21
+	pendingProjectID   uint = 22
+
+	pendingApplicationID    uint = 30
+	rejectedApplicationID   uint = 31
+	withdrawnApplicationID  uint = 32
+	acceptedApplicationID   uint = 33
+	closedApplicationID     uint = 34 // rejected, on completedProjectID
+	waitlistedApplicationID uint = 35
+
+	activeEngagementID    uint = 40
+	completedEngagementID uint = 41
+
+	hoursID uint = 50
+
+	upcomingShiftID uint = 60
+	startedShiftID  uint = 61
+
+	reviewID       uint = 70
+	conversationID uint = 80
+	subscriptionID uint = 90
+	deliveryID     uint = 91
+
+	missingID uint = 999
+)
+
+// fakeStore is an in-memory database behind fake repositories. Lookups return copies, so a
+// service changing what it loaded does not change the store.
+type fakeStore struct {
+	users         map[uint]*model.User
+	nonprofits    map[uint]*model.Nonprofit
+	members       map[uint][]uint // user IDs by nonprofit ID
+	projects      map[uint]*model.Project
+	applications  map[uint]*model.Application
+	answers       map[uint][]*model.ApplicationAnswer // by application ID
+	engagements   map[uint]*model.Engagement
+	hours         map[uint]*model.HoursLogged
+	shifts        map[uint]*model.Shift
+	reviews       map[uint]*model.Review
+	conversations map[uint]*model.Conversation
+	subscriptions map[uint]*model.WebhookSubscription
+	deliveries    map[uint]*model.WebhookDelivery
+}
+
+func newFakeStore() *fakeStore {
+	user := func(id uint, role model.UserRole) *model.User {
+		return &model.User{Model: gorm.Model{ID: id}, Role: role}
+	}
+	project := func(id uint, status model.ProjectStatus) *model.Project {
+		return &model.Project{Model: gorm.Model{ID: id}, NonprofitID: nonprofitID, Status: status}
+	}
+	projects := map[uint]*model.Project{
+		activeProjectID:    project(activeProjectID, model.Active),
+		completedProjectID: project(completedProjectID, model.Completed),
+		pendingProjectID:   project(pendingProjectID, model.PendingReview),
+	}
+	application := func(id uint, projectID uint, status model.ApplicationStatus) *model.Application {
+		return &model.Application{Model: gorm.Model{ID: id}, VolunteerID: volunteerID, ProjectID: projectID, Project: *projects[projectID], Status: status}
+	}
+	engagement := func(id uint, status model.EngagementStatus) *model.Engagement {
+		return &model.Engagement{Model: gorm.Model{ID: id}, VolunteerID: volunteerID, ProjectID: activeProjectID, Project: *projects[activeProjectID], Status: status}
+	}
+	engagements := map[uint]*model.Engagement{
+		activeEngagementID:    engagement(activeEngagementID, model.EngagementActive),
+		completedEngagementID: engagement(completedEngagementID, model.EngagementCompleted),
+	}
+	shift := func(id uint, startsIn time.Duration) *model.Shift {
+		starts := time.Now().Add(startsIn)
+		return &model.Shift{Model: gorm.Model{ID: id}, ProjectID: activeProjectID, StartsAt: starts, EndsAt: starts.Add(time.Hour), Capacity: 5}
+	}
+
+	return &fakeStore{
+		users: map[uint]*model.User{
+			adminID:     user(adminID, model.PlatformAdmin),
+			memberID:    user(memberID, model.NonprofitAdmin),
+			outsiderID:  user(outsiderID, model.NonprofitAdmin),
+			volunteerID: user(volunteerID, model.Volunteer),
+		},
+		nonprofits: map[uint]*model.Nonprofit{
+			nonprofitID: {Model: gorm.Model{ID: nonprofitID}, Name: "Food Bank"},
+		},
+		members:  map[uint][]uint{nonprofitID: {memberID}},
+		projects: projects,
+		applications: map[uint]*model.Application{
+			pendingApplicationID:    application(pendingApplicationID, activeProjectID, model.Pending),
+			rejectedApplicationID:   application(rejectedApplicationID, activeProjectID, model.Rejected),
+			withdrawnApplicationID:  application(withdrawnApplicationID, activeProjectID, model.Withdrawn),
+			acceptedApplicationID:   application(acceptedApplicationID, activeProjectID, model.Accepted),
+			closedApplicationID:     application(closedApplicationID, completedProjectID, model.Rejected),
+			waitlistedApplicationID: application(waitlistedApplicationID, activeProjectID, model.Waitlisted),
+		},
+		answers: map[uint][]*model.ApplicationAnswer{
+			pendingApplicationID: {{ApplicationID: pendingApplicationID, Values: model.Strings{"Weekends"}}},
+		},
+		engagements: engagements,
+		hours: map[uint]*model.HoursLogged{
+			hoursID: {Model: gorm.Model{ID: hoursID}, EngagementID: activeEngagementID, Engagement: *engagements[activeEngagementID], Hours: 3},
+		},
+		shifts: map[uint]*model.Shift{
+			upcomingShiftID: shift(upcomingShiftID, 24*time.Hour),
+			startedShiftID:  shift(startedShiftID, -time.Hour),
+		},
+		reviews: map[uint]*model.Review{
+			reviewID: {Model: gorm.Model{ID: reviewID}, EngagementID: completedEngagementID, NonprofitID: nonprofitID, VolunteerID: volunteerID, Rating: 2},
+		},
+		conversations: map[uint]*model.Conversation{
+			conversationID: {Model: gorm.Model{ID: conversationID}, ProjectID: activeProjectID, NonprofitID: nonprofitID, VolunteerID: volunteerID},
+		},
+		subscriptions: map[uint]*model.WebhookSubscription{
+			subscriptionID: {Model: gorm.Model{ID: subscriptionID}, NonprofitID: nonprofitID, URL: "https://example.org/hooks", Secret: "whsec_test", Active: true},
+		},
+		deliveries: map[uint]*model.WebhookDelivery{
+			deliveryID: {Model: gorm.Model{ID: deliveryID}, SubscriptionID: subscriptionID, Event: model.WebhookHoursLogged, Payload: `{}`, Status: model.DeliveryFailed},
+		},
+	}
+}
+
+func (f *fakeStore) repos() Repositories {
+	return Repositories{
+		Users:         fakeUsers{f},
+		Nonprofits:    fakeNonprofits{f},
+		Projects:      fakeProjects{f},
+		Applications:  fakeApplications{f},
+		Engagements:   fakeEngagements{f},
+		Hours:         fakeHours{f},
+		Shifts:        fakeShifts{f},
+		Reviews:       fakeReviews{f},
+		Conversations: fakeConversations{f},
+		Webhooks:      fakeWebhooks{f},
+		Catalog:       fakeCatalog{},
+		Analytics:     fakeAnalytics{f},
+	}
+}
+
+// user returns a copy of a user of the store, for a service to act as.
+func (f *fakeStore) user(id uint) *model.User {
+	u := *f.users[id]
+	return &u
+}
+
+func get[T any](records map[uint]*T, id uint) (*T, error) {
+	record, ok := records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+type fakeUsers struct{ *fakeStore }
+
+func (f fakeUsers) Find(id uint) (*model.User, error) { return get(f.users, id) }
+
+type fakeNonprofits struct{ *fakeStore }
+
+func (f fakeNonprofits) Find(id uint) (*model.Nonprofit, error) { return get(f.nonprofits, id) }
+
+func (f fakeNonprofits) Exists(id uint) (bool, error) {
+	_, ok := f.nonprofits[id]
+	return ok, nil
+}
+
+func (f fakeNonprofits) IsMember(nonprofitID, userID uint) (bool, error) {
+	return slices.Contains(f.members[nonprofitID], userID), nil
+}
+
+type fakeProjects struct{ *fakeStore }
+
+func (f fakeProjects) Find(id uint) (*model.Project, error) { return get(f.projects, id) }
+
+// FindWithApplications returns the project with the store's applications and engagements on it.
+func (f fakeProjects) FindWithApplications(id uint) (*model.Project, error) {
+	project, err := get(f.projects, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, application := range f.applications {
+		if application.ProjectID == id {
+			project.Applications = append(project.Applications, *application)
+		}
+	}
+	for _, engagement := range f.engagements {
+		if engagement.ProjectID == id {
+			project.Engagements = append(project.Engagements, *engagement)
+		}
+	}
+	return project, nil
+}
+
+func (f fakeProjects) Questions(projectID uint) ([]model.ApplicationQuestion, error) {
+	return nil, nil
+}
+
+func (f fakeProjects) RoleSlot(projectID, slotID uint) (*model.ProjectRoleSlot, error) {
+	return nil, ErrNotFound
+}
+
+func (f fakeProjects) Waitlist(projectID uint) ([]*model.Application, error) {
+	var waitlist []*model.Application
+	for id, application := range f.applications {
+		if application.ProjectID == projectID && application.Status == model.Waitlisted {
+			a, _ := get(f.applications, id)
+			waitlist = append(waitlist, a)
+		}
+	}
+	return waitlist, nil
+}
+
+type fakeApplications struct{ *fakeStore }
+
+func (f fakeApplications) Find(id uint) (*model.Application, error) { return get(f.applications, id) }
+
+func (f fakeApplications) ForVolunteer(volunteerID, projectID uint) (*model.Application, error) {
+	for id, application := range f.applications {
+		if application.VolunteerID == volunteerID && application.ProjectID == projectID {
+			return get(f.applications, id)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f fakeApplications) Answers(applicationID uint) ([]*model.ApplicationAnswer, error) {
+	return f.answers[applicationID], nil
+}
+
+type fakeEngagements struct{ *fakeStore }
+
+func (f fakeEngagements) Find(id uint) (*model.Engagement, error) { return get(f.engagements, id) }
+
+func (f fakeEngagements) CountActive(volunteerID, projectID uint) (int64, error) {
+	var count int64
+	for _, engagement := range f.engagements {
+		if engagement.VolunteerID == volunteerID && engagement.ProjectID == projectID && engagement.Status == model.EngagementActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeHours struct{ *fakeStore }
+
+func (f fakeHours) Find(id uint) (*model.HoursLogged, error) { return get(f.hours, id) }
+
+type fakeShifts struct{ *fakeStore }
+
+func (f fakeShifts) Find(id uint) (*model.Shift, error) { return get(f.shifts, id) }
+
+type fakeReviews struct{ *fakeStore }
+
+func (f fakeReviews) Find(id uint) (*model.Review, error) { return get(f.reviews, id) }
+
+type fakeConversations struct{ *fakeStore }
+
+func (f fakeConversations) Find(id uint) (*model.Conversation, error) {
+	return get(f.conversations, id)
+}
+
+type fakeWebhooks struct{ *fakeStore }
+
+func (f fakeWebhooks) Subscription(id uint) (*model.WebhookSubscription, error) {
+	return get(f.subscriptions, id)
+}
+
+func (f fakeWebhooks) Delivery(id uint) (*model.WebhookDelivery, error) {
+	return get(f.deliveries, id)
+}
+
+// fakeCatalog knows every skill and cause there is.
+type fakeCatalog struct{}
+
+func (fakeCatalog) Skill(name string) (*model.Skill, error) {
+	return &model.Skill{Name: name}, nil
+}
+
+func (fakeCatalog) FindOrCreateSkills(names []string) ([]*model.Skill, error) {
+	var skills []*model.Skill
+	for _, name := range names {
+		skills = append(skills, &model.Skill{Name: name})
+	}
+	return skills, nil
+}
+
+func (fakeCatalog) Causes(names []string) ([]*model.Cause, error) {
+	return fakeCatalog{}.FindOrCreateCauses(names)
+}
+
+func (fakeCatalog) FindOrCreateCauses(names []string) ([]*model.Cause, error) {
+	var causes []*model.Cause
+	for _, name := range names {
+		causes = append(causes, &model.Cause{Name: name})
+	}
+	return causes, nil
+}
+
+// fakeAnalytics reports nothing and finds every user.
+type fakeAnalytics struct{ *fakeStore }
+
+func (f fakeAnalytics) NonprofitStats(nonprofit *model.Nonprofit, from, to time.Time, granularity model.Granularity) (*model.NonprofitStats, error) {
+	return &model.NonprofitStats{}, nil
+}
+
+func (f fakeAnalytics) SearchUsers(query string, role *model.UserRole, limit, offset int) ([]*model.User, error) {
+	var users []*model.User
+	for id := range f.users {
+		users = append(users, f.user(id))
+	}
+	return users, nil
+}
+
+// expect fails t unless err contains wantErr, or is nil when wantErr is "". A service that fails
+// must not have started writing, and one that succeeds must have.
+func expect(t *testing.T, err error, wantErr string, work *fakeWork) {
+	t.Helper()
+	expectErr(t, err, wantErr)
+	if wrote, want := work.calls > 0, wantErr == ""; wrote != want {
+		t.Fatalf("wrote = %v, want %v", wrote, want)
+	}
+}
+
+// expectErr is expect for reads, which never write.
+func expectErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+		t.Fatalf("error = %v, want one containing %q", err, wantErr)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/messaging"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// MessageService carries the conversations between volunteers and nonprofits. Only the volunteer
+// and members of the nonprofit take part in them, as messaging.IsParticipant says.
+type MessageService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewMessageService(work UnitOfWork, repos Repositories) *MessageService {
+	return &MessageService{Work: work, Repos: repos}
+}
+
+// Send posts a message to a conversation, or to the one about an application or engagement,
+// starting it if there is none yet.
+func (s *MessageService) Send(ctx context.Context, user *model.User, input model.SendMessageInput) (*model.Message, error) {
+	if err := validation.SendMessageInput(input); err != nil {
+		return nil, err
+	}
+
+	// start returns the conversation to post to once the sender is known to take part in it
+	var start func(tx *gorm.DB) (*model.Conversation, error)
+	switch {
+	case input.ConversationID != nil:
+		id, err := utils.IdToUint(*input.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		conversation, err := s.findConversation(id)
+		if err != nil {
+			return nil, err
+		}
+		start = func(tx *gorm.DB) (*model.Conversation, error) {
+			return conversation, messaging.Authorize(tx, user, conversation)
+		}
+
+	case input.ApplicationID != nil:
+		id, err := utils.IdToUint(*input.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+		application, err := s.Repos.Applications.Find(id)
+		if application, err = find(application, err, "application", id); err != nil {
+			return nil, err
+		}
+		start = func(tx *gorm.DB) (*model.Conversation, error) {
+			if err := participate(tx, user, application.Project.NonprofitID, application.VolunteerID); err != nil {
+				return nil, err
+			}
+			return messaging.ForApplication(tx, application)
+		}
+
+	default:
+		id, err := utils.IdToUint(*input.EngagementID)
+		if err != nil {
+			return nil, err
+		}
+		engagement, err := s.Repos.Engagements.Find(id)
+		if engagement, err = find(engagement, err, "engagement", id); err != nil {
+			return nil, err
+		}
+		start = func(tx *gorm.DB) (*model.Conversation, error) {
+			if err := participate(tx, user, engagement.Project.NonprofitID, engagement.VolunteerID); err != nil {
+				return nil, err
+			}
+			return messaging.ForEngagement(tx, engagement)
+		}
+	}
+
+	var message *model.Message
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		conversation, err := start(tx)
+		if err != nil {
+			return err
+		}
+		message, err = messaging.Send(tx, conversation, user, strings.TrimSpace(input.Body))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// MarkRead records that user has read a conversation up to now.
+func (s *MessageService) MarkRead(ctx context.Context, user *model.User, conversationID uint) (*model.Conversation, error) {
+	conversation, err := s.findConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := messaging.Authorize(tx, user, conversation); err != nil {
+			return err
+		}
+		return messaging.MarkRead(tx, conversation.ID, user.ID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+func (s *MessageService) findConversation(id uint) (*model.Conversation, error) {
+	conversation, err := s.Repos.Conversations.Find(id)
+	return find(conversation, err, "conversation", id)
+}
+
+// participate returns messaging.ErrNotParticipant unless user takes part in the thread between
+// the volunteer and the nonprofit.
+func participate(tx *gorm.DB, user *model.User, nonprofitID, volunteerID uint) error {
+	ok, err := messaging.IsParticipant(tx, user, nonprofitID, volunteerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return messaging.ErrNotParticipant
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestMessageServiceSend(t *testing.T) {
+	id := func(id uint) *string {
+		s := fmt.Sprint(id)
+		return &s
+	}
+	// Whether the sender takes part is checked in the transaction, so these only cover what
+	// comes before it
+	tests := []struct {
+		name    string
+		input   model.SendMessageInput
+		wantErr string
+	}{
+		{"to a conversation", model.SendMessageInput{ConversationID: id(conversationID), Body: "Hi"}, ""},
+		{"about an application", model.SendMessageInput{ApplicationID: id(pendingApplicationID), Body: "Hi"}, ""},
+		{"about an engagement", model.SendMessageInput{EngagementID: id(activeEngagementID), Body: "Hi"}, ""},
+		{"no target", model.SendMessageInput{Body: "Hi"}, "input"},
+		{"two targets", model.SendMessageInput{ConversationID: id(conversationID), ApplicationID: id(pendingApplicationID), Body: "Hi"}, "input"},
+		{"empty body", model.SendMessageInput{ConversationID: id(conversationID)}, "input.body"},
+		{"missing conversation", model.SendMessageInput{ConversationID: id(missingID), Body: "Hi"}, "not found"},
+		{"missing application", model.SendMessageInput{ApplicationID: id(missingID), Body: "Hi"}, "not found"},
+		{"missing engagement", model.SendMessageInput{EngagementID: id(missingID), Body: "Hi"}, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewMessageService(work, store.repos())
+			_, err := s.Send(context.Background(), store.user(volunteerID), tt.input)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
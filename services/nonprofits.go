@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/media"
+	"github.com/prkagrawal/cosmos-bk2/storage"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NonprofitService manages nonprofits and their verification.
+type NonprofitService struct {
+	Work    UnitOfWork
+	Repos   Repositories
+	Storage storage.Store
+}
+
+func NewNonprofitService(work UnitOfWork, repos Repositories, store storage.Store) *NonprofitService {
+	return &NonprofitService{Work: work, Repos: repos, Storage: store}
+}
+
+// Create creates an unverified nonprofit with creator as its first member.
+func (s *NonprofitService) Create(ctx context.Context, creator *model.User, input model.NonprofitInput) (*model.Nonprofit, error) {
+	if err := validation.NonprofitInput(input); err != nil {
+		return nil, err
+	}
+
+	logoURL, err := s.saveLogo(ctx, input.Logo)
+	if err != nil {
+		return nil, err
+	}
+	causes, err := s.Repos.Catalog.FindOrCreateCauses(input.Causes)
+	if err != nil {
+		return nil, err
+	}
+
+	nonprofit := model.Nonprofit{
+		Name:        input.Name,
+		Description: input.Description,
+		Website:     input.Website,
+		EIN:         input.Ein,
+		Size:        input.Size,
+		LogoURL:     logoURL,
+		Location:    location(input.Location),
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&nonprofit).Error; err != nil {
+			return fmt.Errorf("failed to create nonprofit: %w", err)
+		}
+		if len(causes) > 0 {
+			if err := tx.Model(&nonprofit).Association("Causes").Replace(causes); err != nil {
+				return fmt.Errorf("failed to set nonprofit causes: %w", err)
+			}
+		}
+		if err := tx.Model(&nonprofit).Association("Members").Append(creator); err != nil {
+			return fmt.Errorf("failed to add creator as nonprofit member: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("Nonprofit", nonprofit.ID, nonprofit.ID, nil, nonprofit))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(nonprofit.ID)
+}
+
+// Update replaces a nonprofit's details with input. The logo is only replaced when a new one is
+// uploaded, and the causes when input lists them. Members of the nonprofit and platform admins
+// can.
+func (s *NonprofitService) Update(ctx context.Context, user *model.User, id uint, input model.NonprofitInput) (*model.Nonprofit, error) {
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, id, "update it"); err != nil {
+		return nil, err
+	}
+	if err := validation.NonprofitInput(input); err != nil {
+		return nil, err
+	}
+	nonprofit, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *nonprofit
+	oldLogo := nonprofit.LogoURL
+	nonprofit.Name = input.Name
+	nonprofit.Description = input.Description
+	nonprofit.Website = input.Website
+	nonprofit.EIN = input.Ein
+	nonprofit.Size = input.Size
+	nonprofit.Location = location(input.Location)
+
+	if input.Logo != nil {
+		logoURL, err := s.saveLogo(ctx, input.Logo)
+		if err != nil {
+			return nil, err
+		}
+		nonprofit.LogoURL = logoURL
+	}
+
+	var causes []*model.Cause
+	if input.Causes != nil {
+		if causes, err = s.Repos.Catalog.FindOrCreateCauses(input.Causes); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if input.Causes != nil {
+			if err := tx.Model(nonprofit).Association("Causes").Replace(causes); err != nil {
+				return fmt.Errorf("failed to update nonprofit causes: %w", err)
+			}
+		}
+		// Causes were replaced above and members are only loaded for the response
+		if err := tx.Omit(clause.Associations).Save(nonprofit).Error; err != nil {
+			return fmt.Errorf("failed to update nonprofit: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("Nonprofit", nonprofit.ID, nonprofit.ID, before, *nonprofit))
+	})
+	if err != nil {
+		if nonprofit.LogoURL != oldLogo {
+			_ = media.DeleteVariants(ctx, s.Storage, nonprofit.LogoURL)
+		}
+		return nil, err
+	}
+	// Only once the new logo is saved, so the stored key never points at deleted images
+	if nonprofit.LogoURL != oldLogo {
+		_ = media.DeleteVariants(ctx, s.Storage, oldLogo)
+	}
+	return s.find(nonprofit.ID)
+}
+
+// Verify marks a nonprofit as verified. Only platform admins can.
+func (s *NonprofitService) Verify(ctx context.Context, admin *model.User, id uint) (*model.Nonprofit, error) {
+	if err := RequirePlatformAdmin(admin, "verify nonprofits"); err != nil {
+		return nil, err
+	}
+	nonprofit, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *nonprofit
+	nonprofit.Verified = true
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(nonprofit).Update("verified", true).Error; err != nil {
+			return fmt.Errorf("failed to verify nonprofit: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("Nonprofit", nonprofit.ID, nonprofit.ID, before, *nonprofit))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(nonprofit.ID)
+}
+
+// Stats builds the nonprofit's impact report between from and to, RFC 3339 times, bucketed by
+// granularity. Members of the nonprofit and platform admins can.
+func (s *NonprofitService) Stats(user *model.User, id uint, from, to string, granularity model.Granularity) (*model.NonprofitStats, error) {
+	nonprofit, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, nonprofit.ID, "view its stats"); err != nil {
+		return nil, err
+	}
+
+	v := validation.New()
+	fromTime := v.DateTime(from, "from")
+	toTime := v.DateTime(to, "to")
+	if fromTime != nil && toTime != nil {
+		v.Check(!toTime.Before(*fromTime), "to", "must not be before from")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return s.Repos.Analytics.NonprofitStats(nonprofit, *fromTime, *toTime, granularity)
+}
+
+func (s *NonprofitService) find(id uint) (*model.Nonprofit, error) {
+	nonprofit, err := s.Repos.Nonprofits.Find(id)
+	return find(nonprofit, err, "nonprofit", id)
+}
+
+// saveLogo validates, resizes and stores an uploaded logo, returning the base key of its
+// variants, or "" when nothing was uploaded.
+func (s *NonprofitService) saveLogo(ctx context.Context, logo *graphql.Upload) (string, error) {
+	if logo == nil {
+		return "", nil
+	}
+	key, err := media.ProcessUpload(ctx, s.Storage, &model.Upload{Filename: logo.Filename, File: logo.File}, media.Logo)
+	if errors.Is(err, media.ErrInvalidImage) {
+		return "", validation.Field("input.logo", err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to save nonprofit logo: %w", err)
+	}
+	return key, nil
+}
+
+func location(input *model.LocationInput) model.Location {
+	return model.Location{City: input.City, State: input.State, Country: input.Country, Remote: input.Remote}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func validNonprofitInput() model.NonprofitInput {
+	return model.NonprofitInput{
+		Name:        "Food Bank",
+		Description: "We feed the city.",
+		Website:     "https://foodbank.example.org",
+		Ein:         "12-3456789",
+		Size:        model.Small,
+		Location:    &model.LocationInput{Country: "US"},
+	}
+}
+
+func TestNonprofitServiceUpdate(t *testing.T) {
+	invalid := validNonprofitInput()
+	invalid.Name = ""
+
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		input   model.NonprofitInput
+		wantErr string
+	}{
+		{"member", memberID, nonprofitID, validNonprofitInput(), ""},
+		{"platform admin", adminID, nonprofitID, validNonprofitInput(), ""},
+		{"nonprofit admin of another nonprofit", outsiderID, nonprofitID, validNonprofitInput(), "unauthorized"},
+		{"volunteer", volunteerID, nonprofitID, validNonprofitInput(), "unauthorized"},
+		{"outsider sees no validation errors", outsiderID, nonprofitID, invalid, "unauthorized"},
+		{"member with invalid input", memberID, nonprofitID, invalid, "input.name"},
+		{"missing nonprofit", adminID, missingID, validNonprofitInput(), "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewNonprofitService(work, store.repos(), nil)
+			_, err := s.Update(context.Background(), store.user(tt.userID), tt.id, tt.input)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestNonprofitServiceVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"platform admin", adminID, nonprofitID, ""},
+		{"member", memberID, nonprofitID, "unauthorized"},
+		{"volunteer", volunteerID, nonprofitID, "unauthorized"},
+		{"missing nonprofit", adminID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewNonprofitService(work, store.repos(), nil)
+			_, err := s.Verify(context.Background(), store.user(tt.userID), tt.id)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestNonprofitServiceStats(t *testing.T) {
+	const from, to = "2026-01-01T00:00:00Z", "2026-03-31T23:59:59Z"
+	tests := []struct {
+		name     string
+		userID   uint
+		id       uint
+		from, to string
+		wantErr  string
+	}{
+		{"member", memberID, nonprofitID, from, to, ""},
+		{"platform admin", adminID, nonprofitID, from, to, ""},
+		{"nonprofit admin of another nonprofit", outsiderID, nonprofitID, from, to, "unauthorized"},
+		{"volunteer", volunteerID, nonprofitID, from, to, "unauthorized"},
+		{"outsider sees no validation errors", outsiderID, nonprofitID, "soon", to, "unauthorized"},
+		{"invalid from", memberID, nonprofitID, "soon", to, "from"},
+		{"to before from", memberID, nonprofitID, to, from, "to"},
+		{"missing nonprofit", adminID, missingID, from, to, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			s := NewNonprofitService(&fakeWork{}, store.repos(), nil)
+			_, err := s.Stats(store.user(tt.userID), tt.id, tt.from, tt.to, model.GranularityMonth)
+			expectErr(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/notifications"
+	"gorm.io/gorm"
+)
+
+// NotificationService keeps a user's notifications read and their preferences.
+type NotificationService struct {
+	Work UnitOfWork
+}
+
+func NewNotificationService(work UnitOfWork) *NotificationService {
+	return &NotificationService{Work: work}
+}
+
+// MarkRead marks user's notifications with the given IDs read, or all of them when ids is nil. It
+// returns how many were unread.
+func (s *NotificationService) MarkRead(ctx context.Context, user *model.User, ids []uint) (int64, error) {
+	var count int64
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		count, err = notifications.MarkRead(tx, user.ID, ids)
+		return err
+	})
+	return count, err
+}
+
+// SetPreference sets the channels user is notified of event on.
+func (s *NotificationService) SetPreference(ctx context.Context, user *model.User, event model.NotificationEvent, channels []model.NotificationChannel) (*model.NotificationPreference, error) {
+	var preference *model.NotificationPreference
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		preference, err = notifications.SetPreference(tx, user.ID, event, channels)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
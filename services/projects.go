@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/utils"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProjectService manages projects: their details, role slots and screening questions, and their
+// way through moderation and the rest of the project lifecycle.
+type ProjectService struct {
+	Work       UnitOfWork
+	Repos      Repositories
+	Moderation lifecycle.ModerationPolicy
+}
+
+func NewProjectService(work UnitOfWork, repos Repositories, moderation lifecycle.ModerationPolicy) *ProjectService {
+	return &ProjectService{Work: work, Repos: repos, Moderation: moderation}
+}
+
+// Create creates a draft project for the nonprofit named in input. Members of the nonprofit,
+// nonprofit admins and platform admins can.
+func (s *ProjectService) Create(ctx context.Context, creator *model.User, input model.ProjectInput) (*model.Project, error) {
+	if err := validation.ProjectInput(input); err != nil {
+		return nil, err
+	}
+	nonprofitID, err := utils.IdToUint(input.NonprofitID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonprofit ID: %w", err)
+	}
+
+	exists, err := s.Repos.Nonprofits.Exists(nonprofitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("nonprofit with ID %s not found", input.NonprofitID)
+	}
+	if creator.Role != model.PlatformAdmin && creator.Role != model.NonprofitAdmin {
+		member, err := s.Repos.Nonprofits.IsMember(nonprofitID, creator.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, errors.New("unauthorized: you must be an admin of the nonprofit or a platform admin to create projects")
+		}
+	}
+
+	project := model.Project{NonprofitID: nonprofitID, Status: model.Draft}
+	if err := applyProjectInput(&project, input); err != nil {
+		return nil, err
+	}
+	skills, err := s.Repos.Catalog.FindOrCreateSkills(input.SkillsNeeded)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		if err := lifecycle.RecordProjectStatus(tx, project.ID, nil, project.Status, creator, nil); err != nil {
+			return err
+		}
+		if len(skills) > 0 {
+			if err := tx.Model(&project).Association("SkillsNeeded").Replace(skills); err != nil {
+				return fmt.Errorf("failed to set project skills: %w", err)
+			}
+		}
+		if err := setRoleSlots(tx, project.ID, input.RoleSlots, skills); err != nil {
+			return err
+		}
+		return setQuestions(tx, project.ID, input.Questions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(project.ID)
+}
+
+// Update replaces a project's details with input. Skills, role slots and questions are only
+// replaced when input lists them. Waitlisted applicants move up if the project grew. Members of
+// the nonprofit and platform admins can.
+func (s *ProjectService) Update(ctx context.Context, user *model.User, id uint, input model.ProjectInput) (*model.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, project.NonprofitID, "update its projects"); err != nil {
+		return nil, err
+	}
+	if err := validation.ProjectInput(input); err != nil {
+		return nil, err
+	}
+	// A project stays with the nonprofit it was created for
+	if _, err := utils.IdToUint(input.NonprofitID); err != nil {
+		return nil, fmt.Errorf("invalid nonprofit ID in input: %w", err)
+	}
+	if err := applyProjectInput(project, input); err != nil {
+		return nil, err
+	}
+
+	var skills []*model.Skill
+	for i := range project.SkillsNeeded {
+		skills = append(skills, &project.SkillsNeeded[i])
+	}
+	if input.SkillsNeeded != nil {
+		if skills, err = s.Repos.Catalog.FindOrCreateSkills(input.SkillsNeeded); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if input.SkillsNeeded != nil {
+			if err := tx.Model(project).Association("SkillsNeeded").Replace(skills); err != nil {
+				return fmt.Errorf("failed to update project skills: %w", err)
+			}
+		}
+		if err := tx.Omit(clause.Associations).Save(project).Error; err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+		if input.RoleSlots != nil {
+			if err := setRoleSlots(tx, project.ID, input.RoleSlots, skills); err != nil {
+				return err
+			}
+		}
+		if input.Questions != nil {
+			if err := setQuestions(tx, project.ID, input.Questions); err != nil {
+				return err
+			}
+		}
+		_, err := lifecycle.PromoteFromWaitlist(tx, project.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(project.ID)
+}
+
+// ChangeStatus moves a project to another status. Members of the nonprofit and platform admins
+// can, as far as the lifecycle allows each of them.
+func (s *ProjectService) ChangeStatus(ctx context.Context, user *model.User, id uint, status model.ProjectStatus, reason *string) (*model.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok, err := ProjectActor(s.Repos.Nonprofits, user, project.NonprofitID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("unauthorized: you must be a member of the nonprofit or a platform admin to change project status")
+	}
+	return s.transition(ctx, project, status, actor, user, reason)
+}
+
+// SubmitForReview submits a draft project to the moderators. Projects from verified nonprofits
+// may be approved immediately, depending on the moderation policy.
+func (s *ProjectService) SubmitForReview(ctx context.Context, user *model.User, id uint) (*model.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok, err := ProjectActor(s.Repos.Nonprofits, user, project.NonprofitID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("unauthorized: you must be a member of the nonprofit to submit its projects for review")
+	}
+
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		_, err := lifecycle.SubmitForReview(tx, project, actor, user, s.Moderation)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(project.ID)
+}
+
+// Approve publishes a project pending review. Only platform admins can.
+func (s *ProjectService) Approve(ctx context.Context, admin *model.User, id uint, comment *string) (*model.Project, error) {
+	if err := RequirePlatformAdmin(admin, "approve projects"); err != nil {
+		return nil, err
+	}
+	project, err := s.pendingReview(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, project, model.Active, lifecycle.ActorPlatformAdmin, admin, comment)
+}
+
+// RequestChanges sends a project pending review back to DRAFT. The comment is kept as the reason
+// in its status history. Only platform admins can.
+func (s *ProjectService) RequestChanges(ctx context.Context, admin *model.User, id uint, comment string) (*model.Project, error) {
+	if err := RequirePlatformAdmin(admin, "request changes to projects"); err != nil {
+		return nil, err
+	}
+	if err := validation.ModerationComment(comment); err != nil {
+		return nil, err
+	}
+	project, err := s.pendingReview(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, project, model.Draft, lifecycle.ActorPlatformAdmin, admin, &comment)
+}
+
+// Waitlist returns the applications waiting for a place on project. Only members of the project's
+// nonprofit and platform admins see them; anyone else, including a nil user, gets none.
+func (s *ProjectService) Waitlist(user *model.User, project *model.Project) ([]*model.Application, error) {
+	if user == nil {
+		return []*model.Application{}, nil
+	}
+	if _, ok, err := ProjectActor(s.Repos.Nonprofits, user, project.NonprofitID); err != nil || !ok {
+		return []*model.Application{}, err
+	}
+	waitlist, err := s.Repos.Projects.Waitlist(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+	return waitlist, nil
+}
+
+// Get returns a project with its applications and engagements, or nil if there is none. Members
+// of the project's nonprofit and platform admins see all of them; anyone else only their own, and
+// a nil user none.
+func (s *ProjectService) Get(user *model.User, id uint) (*model.Project, error) {
+	project, err := s.Repos.Projects.FindWithApplications(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if user != nil {
+		_, ok, err := ProjectActor(s.Repos.Nonprofits, user, project.NonprofitID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return project, nil
+		}
+	}
+	project.Applications = slices.DeleteFunc(project.Applications, func(a model.Application) bool {
+		return user == nil || a.VolunteerID != user.ID
+	})
+	project.Engagements = slices.DeleteFunc(project.Engagements, func(e model.Engagement) bool {
+		return user == nil || e.VolunteerID != user.ID
+	})
+	return project, nil
+}
+
+func (s *ProjectService) find(id uint) (*model.Project, error) {
+	project, err := s.Repos.Projects.Find(id)
+	return find(project, err, "project", id)
+}
+
+func (s *ProjectService) pendingReview(id uint) (*model.Project, error) {
+	project, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if project.Status != model.PendingReview {
+		return nil, fmt.Errorf("project is not pending review (current status: %s)", project.Status)
+	}
+	return project, nil
+}
+
+// transition changes the project's status and records it in the audit log.
+func (s *ProjectService) transition(ctx context.Context, project *model.Project, to model.ProjectStatus, actor lifecycle.Actor, user *model.User, reason *string) (*model.Project, error) {
+	before := *project
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := lifecycle.TransitionProject(tx, project, to, actor, user, reason); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Change("Project", project.ID, project.NonprofitID, &before, project))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(project.ID)
+}
+
+// applyProjectInput copies the details in input to project.
+func applyProjectInput(project *model.Project, input model.ProjectInput) error {
+	startDate, err := utils.ParseNullableDateTimeString(input.StartDate)
+	if err != nil {
+		return fmt.Errorf("invalid start date: %w", err)
+	}
+	endDate, err := utils.ParseNullableDateTimeString(input.EndDate)
+	if err != nil {
+		return fmt.Errorf("invalid end date: %w", err)
+	}
+
+	project.Title = input.Title
+	project.Description = input.Description
+	project.TimeCommitment = input.TimeCommitment
+	project.Urgency = input.Urgency
+	project.StartDate = startDate
+	project.EndDate = endDate
+	if input.VolunteersNeeded != nil {
+		project.VolunteersNeeded = int(*input.VolunteersNeeded)
+	}
+	if input.Timezone != nil {
+		project.Timezone = *input.Timezone
+	}
+	return nil
+}
+
+// setRoleSlots makes a project's role slots match input, matched up by title so that slots
+// applicants already chose keep their IDs. skills are the project's SkillsNeeded, which a slot's
+// skill must come from.
+func setRoleSlots(tx *gorm.DB, projectID uint, input []*model.RoleSlotInput, skills []*model.Skill) error {
+	var existing []model.ProjectRoleSlot
+	if err := tx.Where("project_id = ?", projectID).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to fetch role slots: %w", err)
+	}
+	byTitle := make(map[string]model.ProjectRoleSlot, len(existing))
+	for _, slot := range existing {
+		byTitle[slot.Title] = slot
+	}
+	skillIDs := make(map[string]uint, len(skills))
+	for _, skill := range skills {
+		skillIDs[skill.Name] = skill.ID
+	}
+
+	for _, in := range input {
+		slot, ok := byTitle[in.Title]
+		delete(byTitle, in.Title)
+		if !ok {
+			slot = model.ProjectRoleSlot{ProjectID: projectID, Title: in.Title}
+		}
+		slot.Capacity = int(in.Capacity)
+		slot.SkillID = nil
+		if in.Skill != nil {
+			id := skillIDs[*in.Skill]
+			slot.SkillID = &id
+		}
+		if err := tx.Save(&slot).Error; err != nil {
+			return fmt.Errorf("failed to save role slot '%s': %w", in.Title, err)
+		}
+	}
+
+	// Slots left over were dropped from the input. Their applicants stay on the project, just
+	// no longer for a particular role
+	for _, slot := range byTitle {
+		if err := tx.Model(&model.Application{}).Where("slot_id = ?", slot.ID).Update("slot_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach applications from role slot '%s': %w", slot.Title, err)
+		}
+		if err := tx.Delete(&slot).Error; err != nil {
+			return fmt.Errorf("failed to remove role slot '%s': %w", slot.Title, err)
+		}
+	}
+	return nil
+}
+
+// setQuestions makes a project's application questions match input, in input order. Questions
+// are matched up by prompt so answers already given stay attached to them.
+func setQuestions(tx *gorm.DB, projectID uint, input []*model.ApplicationQuestionInput) error {
+	var existing []model.ApplicationQuestion
+	if err := tx.Where("project_id = ?", projectID).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to fetch application questions: %w", err)
+	}
+	byPrompt := make(map[string]model.ApplicationQuestion, len(existing))
+	for _, question := range existing {
+		byPrompt[question.Prompt] = question
+	}
+
+	for i, in := range input {
+		question, ok := byPrompt[in.Prompt]
+		delete(byPrompt, in.Prompt)
+		if !ok {
+			question = model.ApplicationQuestion{ProjectID: projectID, Prompt: in.Prompt}
+		}
+		question.Position = i
+		question.Type = in.Type
+		question.Required = in.Required
+		question.Options = model.Strings(in.Options)
+		if err := tx.Save(&question).Error; err != nil {
+			return fmt.Errorf("failed to save application question '%s': %w", in.Prompt, err)
+		}
+	}
+
+	for _, question := range byPrompt {
+		if err := tx.Delete(&question).Error; err != nil {
+			return fmt.Errorf("failed to remove application question '%s': %w", question.Prompt, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+)
+
+func validProjectInput() model.ProjectInput {
+	return model.ProjectInput{
+		NonprofitID:    fmt.Sprint(nonprofitID),
+		Title:          "Pantry shifts",
+		Description:    "Sort and hand out food.",
+		SkillsNeeded:   []string{"Logistics"},
+		TimeCommitment: model.LessThan5Hours,
+		Urgency:        model.Low,
+	}
+}
+
+func TestProjectServiceUpdate(t *testing.T) {
+	invalid := validProjectInput()
+	invalid.Title = ""
+
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		input   model.ProjectInput
+		wantErr string
+	}{
+		{"member", memberID, activeProjectID, validProjectInput(), ""},
+		{"platform admin", adminID, activeProjectID, validProjectInput(), ""},
+		{"nonprofit admin of another nonprofit", outsiderID, activeProjectID, validProjectInput(), "unauthorized"},
+		{"volunteer", volunteerID, activeProjectID, validProjectInput(), "unauthorized"},
+		{"outsider sees no validation errors", outsiderID, activeProjectID, invalid, "unauthorized"},
+		{"member with invalid input", memberID, activeProjectID, invalid, "input.title"},
+		{"missing project", adminID, missingID, validProjectInput(), "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewProjectService(work, store.repos(), lifecycle.ModerationPolicy{})
+			_, err := s.Update(context.Background(), store.user(tt.userID), tt.id, tt.input)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestProjectServiceChangeStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"member", memberID, activeProjectID, ""},
+		{"platform admin", adminID, activeProjectID, ""},
+		{"outsider", outsiderID, activeProjectID, "unauthorized"},
+		{"volunteer", volunteerID, activeProjectID, "unauthorized"},
+		{"missing project", memberID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewProjectService(work, store.repos(), lifecycle.ModerationPolicy{})
+			_, err := s.ChangeStatus(context.Background(), store.user(tt.userID), tt.id, model.InProgress, nil)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestProjectServiceModeration(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		approve bool
+		comment string
+		wantErr string
+	}{
+		{"admin approves", adminID, pendingProjectID, true, "", ""},
+		{"admin requests changes", adminID, pendingProjectID, false, "Add a start date.", ""},
+		{"member cannot approve", memberID, pendingProjectID, true, "", "unauthorized"},
+		{"member cannot request changes", memberID, pendingProjectID, false, "Add a start date.", "unauthorized"},
+		{"only pending projects are approved", adminID, activeProjectID, true, "", "not pending review"},
+		{"changes need a comment", adminID, pendingProjectID, false, "", "comment"},
+		{"missing project", adminID, missingID, true, "", "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewProjectService(work, store.repos(), lifecycle.ModerationPolicy{})
+			var err error
+			if tt.approve {
+				_, err = s.Approve(context.Background(), store.user(tt.userID), tt.id, nil)
+			} else {
+				_, err = s.RequestChanges(context.Background(), store.user(tt.userID), tt.id, tt.comment)
+			}
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestProjectServiceWaitlist(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID uint // 0 for an anonymous caller
+		want   int
+	}{
+		{"member", memberID, 1},
+		{"platform admin", adminID, 1},
+		{"outsider", outsiderID, 0},
+		{"volunteer", volunteerID, 0},
+		{"anonymous", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			s := NewProjectService(&fakeWork{}, store.repos(), lifecycle.ModerationPolicy{})
+			var user *model.User
+			if tt.userID != 0 {
+				user = store.user(tt.userID)
+			}
+			waitlist, err := s.Waitlist(user, store.projects[activeProjectID])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(waitlist) != tt.want {
+				t.Fatalf("got %d waitlisted applications, want %d", len(waitlist), tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectServiceGet(t *testing.T) {
+	tests := []struct {
+		name             string
+		userID           uint // 0 for an anonymous caller
+		wantApplications int
+		wantEngagements  int
+	}{
+		{"member sees everyone", memberID, 5, 2},
+		{"platform admin sees everyone", adminID, 5, 2},
+		{"volunteer sees their own", volunteerID, 4, 2},
+		{"outsider sees their own", outsiderID, 1, 0},
+		{"anonymous sees nobody", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			store.applications[pendingApplicationID].VolunteerID = outsiderID
+			s := NewProjectService(&fakeWork{}, store.repos(), lifecycle.ModerationPolicy{})
+			var user *model.User
+			if tt.userID != 0 {
+				user = store.user(tt.userID)
+			}
+			project, err := s.Get(user, activeProjectID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(project.Applications) != tt.wantApplications || len(project.Engagements) != tt.wantEngagements {
+				t.Fatalf("got %d applications and %d engagements, want %d and %d",
+					len(project.Applications), len(project.Engagements), tt.wantApplications, tt.wantEngagements)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		store := newFakeStore()
+		s := NewProjectService(&fakeWork{}, store.repos(), lifecycle.ModerationPolicy{})
+		if project, err := s.Get(store.user(memberID), missingID); project != nil || err != nil {
+			t.Fatalf("Get = %v, %v; want nil, nil", project, err)
+		}
+	})
+}
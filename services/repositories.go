@@ -0,0 +1,354 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/analytics"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// UserRepository reads users.
+type UserRepository interface {
+	// Find returns the user with their skills and causes.
+	Find(id uint) (*model.User, error)
+}
+
+// NonprofitRepository reads nonprofits and their membership.
+type NonprofitRepository interface {
+	// Find returns the nonprofit with its causes and members.
+	Find(id uint) (*model.Nonprofit, error)
+	Exists(id uint) (bool, error)
+	IsMember(nonprofitID, userID uint) (bool, error)
+}
+
+// ProjectRepository reads projects and what volunteers apply for on them.
+type ProjectRepository interface {
+	// Find returns the project with the skills it needs and its nonprofit.
+	Find(id uint) (*model.Project, error)
+	// FindWithApplications returns the project with the skills it needs, its applications and
+	// its engagements.
+	FindWithApplications(id uint) (*model.Project, error)
+	Questions(projectID uint) ([]model.ApplicationQuestion, error)
+	RoleSlot(projectID, slotID uint) (*model.ProjectRoleSlot, error)
+	// Waitlist returns the project's waitlisted applications with their volunteers, longest
+	// waiting first.
+	Waitlist(projectID uint) ([]*model.Application, error)
+}
+
+// ApplicationRepository reads applications.
+type ApplicationRepository interface {
+	// Find returns the application with its project and volunteer.
+	Find(id uint) (*model.Application, error)
+	// ForVolunteer returns the volunteer's application to the project; there is at most one.
+	ForVolunteer(volunteerID, projectID uint) (*model.Application, error)
+	// Answers returns the application's answers to screening questions, in the questions' order.
+	Answers(applicationID uint) ([]*model.ApplicationAnswer, error)
+}
+
+// EngagementRepository reads engagements.
+type EngagementRepository interface {
+	// Find returns the engagement with its project and volunteer.
+	Find(id uint) (*model.Engagement, error)
+	CountActive(volunteerID, projectID uint) (int64, error)
+}
+
+// HoursRepository reads logged hours.
+type HoursRepository interface {
+	// Find returns the hours entry with its engagement and project, and who approved it.
+	Find(id uint) (*model.HoursLogged, error)
+}
+
+// ShiftRepository reads shifts.
+type ShiftRepository interface {
+	Find(id uint) (*model.Shift, error)
+}
+
+// ReviewRepository reads reviews.
+type ReviewRepository interface {
+	Find(id uint) (*model.Review, error)
+}
+
+// ConversationRepository reads conversations.
+type ConversationRepository interface {
+	Find(id uint) (*model.Conversation, error)
+}
+
+// WebhookRepository reads nonprofits' webhook subscriptions and their deliveries.
+type WebhookRepository interface {
+	Subscription(id uint) (*model.WebhookSubscription, error)
+	Delivery(id uint) (*model.WebhookDelivery, error)
+}
+
+// CatalogRepository looks up the shared skills and causes by name.
+type CatalogRepository interface {
+	Skill(name string) (*model.Skill, error)
+	// FindOrCreateSkills returns the named skills, creating the ones that don't exist yet.
+	FindOrCreateSkills(names []string) ([]*model.Skill, error)
+	// Causes returns the named causes that exist, skipping the others.
+	Causes(names []string) ([]*model.Cause, error)
+	// FindOrCreateCauses returns the named causes, creating the ones that don't exist yet.
+	FindOrCreateCauses(names []string) ([]*model.Cause, error)
+}
+
+// AnalyticsRepository reads the reports built by the analytics package.
+type AnalyticsRepository interface {
+	NonprofitStats(nonprofit *model.Nonprofit, from, to time.Time, granularity model.Granularity) (*model.NonprofitStats, error)
+	// SearchUsers returns the users whose name, email, bio, profile links or skills contain
+	// query, optionally only those with role.
+	SearchUsers(query string, role *model.UserRole, limit, offset int) ([]*model.User, error)
+}
+
+// first loads the record matching query into dest, returning ErrNotFound if there is none.
+func first(query *gorm.DB, dest any, conds ...any) error {
+	err := query.First(dest, conds...).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r *gormUsers) Find(id uint) (*model.User, error) {
+	var user model.User
+	if err := first(r.db.Preload("Skills").Preload("Causes"), &user, id); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+type gormNonprofits struct{ db *gorm.DB }
+
+func (r *gormNonprofits) Find(id uint) (*model.Nonprofit, error) {
+	var nonprofit model.Nonprofit
+	if err := first(r.db.Preload("Causes").Preload("Members"), &nonprofit, id); err != nil {
+		return nil, err
+	}
+	return &nonprofit, nil
+}
+
+func (r *gormNonprofits) Exists(id uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Nonprofit{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormNonprofits) IsMember(nonprofitID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.Table("nonprofit_members").
+		Where("nonprofit_id = ? AND user_id = ?", nonprofitID, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check nonprofit membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+type gormProjects struct{ db *gorm.DB }
+
+func (r *gormProjects) Find(id uint) (*model.Project, error) {
+	var project model.Project
+	if err := first(r.db.Preload("SkillsNeeded").Preload("Nonprofit"), &project, id); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *gormProjects) FindWithApplications(id uint) (*model.Project, error) {
+	var project model.Project
+	if err := first(r.db.Preload("SkillsNeeded").Preload("Applications").Preload("Engagements"), &project, id); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *gormProjects) Questions(projectID uint) ([]model.ApplicationQuestion, error) {
+	var questions []model.ApplicationQuestion
+	if err := r.db.Where("project_id = ?", projectID).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *gormProjects) RoleSlot(projectID, slotID uint) (*model.ProjectRoleSlot, error) {
+	var slot model.ProjectRoleSlot
+	if err := first(r.db.Where("id = ? AND project_id = ?", slotID, projectID), &slot); err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (r *gormProjects) Waitlist(projectID uint) ([]*model.Application, error) {
+	var waitlist []*model.Application
+	if err := r.db.Where("project_id = ? AND status = ?", projectID, model.Waitlisted).
+		Preload("Volunteer").
+		Order("waitlisted_at ASC, id ASC").
+		Find(&waitlist).Error; err != nil {
+		return nil, err
+	}
+	return waitlist, nil
+}
+
+type gormApplications struct{ db *gorm.DB }
+
+func (r *gormApplications) Find(id uint) (*model.Application, error) {
+	var application model.Application
+	if err := first(r.db.Preload("Project").Preload("Volunteer"), &application, id); err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *gormApplications) ForVolunteer(volunteerID, projectID uint) (*model.Application, error) {
+	var application model.Application
+	if err := first(r.db.Where("volunteer_id = ? AND project_id = ?", volunteerID, projectID), &application); err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *gormApplications) Answers(applicationID uint) ([]*model.ApplicationAnswer, error) {
+	var answers []*model.ApplicationAnswer
+	if err := r.db.Joins("JOIN application_questions ON application_questions.id = application_answers.question_id").
+		Where("application_answers.application_id = ?", applicationID).
+		Order("application_questions.position ASC").
+		Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+type gormEngagements struct{ db *gorm.DB }
+
+func (r *gormEngagements) Find(id uint) (*model.Engagement, error) {
+	var engagement model.Engagement
+	if err := first(r.db.Preload("Project").Preload("Volunteer"), &engagement, id); err != nil {
+		return nil, err
+	}
+	return &engagement, nil
+}
+
+func (r *gormEngagements) CountActive(volunteerID, projectID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Engagement{}).
+		Where("volunteer_id = ? AND project_id = ? AND status = ?", volunteerID, projectID, model.EngagementActive).
+		Count(&count).Error
+	return count, err
+}
+
+type gormHours struct{ db *gorm.DB }
+
+func (r *gormHours) Find(id uint) (*model.HoursLogged, error) {
+	var entry model.HoursLogged
+	if err := first(r.db.Preload("Engagement.Project").Preload("ApprovedBy"), &entry, id); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+type gormShifts struct{ db *gorm.DB }
+
+func (r *gormShifts) Find(id uint) (*model.Shift, error) {
+	var shift model.Shift
+	if err := first(r.db, &shift, id); err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+type gormReviews struct{ db *gorm.DB }
+
+func (r *gormReviews) Find(id uint) (*model.Review, error) {
+	var review model.Review
+	if err := first(r.db, &review, id); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+type gormConversations struct{ db *gorm.DB }
+
+func (r *gormConversations) Find(id uint) (*model.Conversation, error) {
+	var conversation model.Conversation
+	if err := first(r.db, &conversation, id); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+type gormWebhooks struct{ db *gorm.DB }
+
+func (r *gormWebhooks) Subscription(id uint) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := first(r.db, &subscription, id); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *gormWebhooks) Delivery(id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := first(r.db, &delivery, id); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+type gormCatalog struct{ db *gorm.DB }
+
+func (r *gormCatalog) Skill(name string) (*model.Skill, error) {
+	var skill model.Skill
+	if err := first(r.db.Where("name = ?", name), &skill); err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func (r *gormCatalog) FindOrCreateSkills(names []string) ([]*model.Skill, error) {
+	var skills []*model.Skill
+	for _, name := range names {
+		var skill model.Skill
+		if err := r.db.Where("name = ?", name).FirstOrCreate(&skill, model.Skill{Name: name, Category: "Default"}).Error; err != nil {
+			return nil, fmt.Errorf("failed to find or create skill '%s': %w", name, err)
+		}
+		skills = append(skills, &skill)
+	}
+	return skills, nil
+}
+
+func (r *gormCatalog) Causes(names []string) ([]*model.Cause, error) {
+	var causes []*model.Cause
+	if len(names) == 0 {
+		return causes, nil
+	}
+	if err := r.db.Where("name IN ?", names).Find(&causes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch causes: %w", err)
+	}
+	return causes, nil
+}
+
+func (r *gormCatalog) FindOrCreateCauses(names []string) ([]*model.Cause, error) {
+	var causes []*model.Cause
+	for _, name := range names {
+		var cause model.Cause
+		if err := r.db.Where("name = ?", name).FirstOrCreate(&cause, model.Cause{Name: name}).Error; err != nil {
+			return nil, fmt.Errorf("failed to find or create cause '%s': %w", name, err)
+		}
+		causes = append(causes, &cause)
+	}
+	return causes, nil
+}
+
+type gormAnalytics struct{ db *gorm.DB }
+
+func (r *gormAnalytics) NonprofitStats(nonprofit *model.Nonprofit, from, to time.Time, granularity model.Granularity) (*model.NonprofitStats, error) {
+	return analytics.NonprofitStats(r.db, nonprofit, from, to, granularity)
+}
+
+func (r *gormAnalytics) SearchUsers(query string, role *model.UserRole, limit, offset int) ([]*model.User, error) {
+	return analytics.SearchUsers(r.db, query, role, limit, offset)
+}
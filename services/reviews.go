@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/reviews"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// ReviewService records reviews of completed engagements and their moderation.
+type ReviewService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewReviewService(work UnitOfWork, repos Repositories) *ReviewService {
+	return &ReviewService{Work: work, Repos: repos}
+}
+
+// Submit records user's review of a completed engagement. The volunteer reviews the nonprofit;
+// members of the project's nonprofit and platform admins review the volunteer.
+func (s *ReviewService) Submit(ctx context.Context, user *model.User, engagementID uint, input model.ReviewInput) (*model.Review, error) {
+	engagement, err := s.Repos.Engagements.Find(engagementID)
+	if engagement, err = find(engagement, err, "engagement", engagementID); err != nil {
+		return nil, err
+	}
+
+	direction := model.ReviewOfNonprofit
+	if engagement.VolunteerID != user.ID {
+		if err := RequireNonprofitMember(s.Repos.Nonprofits, user, engagement.Project.NonprofitID, "review volunteers"); err != nil {
+			return nil, err
+		}
+		direction = model.ReviewOfVolunteer
+	}
+	if err := validation.ReviewInput(input, direction); err != nil {
+		return nil, err
+	}
+
+	var review *model.Review
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		review, err = reviews.Submit(tx, engagement, user, direction, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Flag reports a review for moderation. Each user can flag a review once.
+func (s *ReviewService) Flag(ctx context.Context, user *model.User, id uint, reason string) error {
+	v := validation.New()
+	v.Required(reason, "reason")
+	v.MaxLength(reason, validation.MaxTextLength, "reason")
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	review, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	return s.Work.Do(ctx, func(tx *gorm.DB) error {
+		return reviews.Flag(tx, review.ID, user, reason)
+	})
+}
+
+// Moderate hides or restores a review and resolves its open flags. Only platform admins can.
+func (s *ReviewService) Moderate(ctx context.Context, admin *model.User, id uint, hidden bool) (*model.Review, error) {
+	if err := RequirePlatformAdmin(admin, "moderate reviews"); err != nil {
+		return nil, err
+	}
+	review, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *review
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := reviews.Moderate(tx, review, admin, hidden); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Change("Review", review.ID, review.NonprofitID, &before, review))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *ReviewService) find(id uint) (*model.Review, error) {
+	review, err := s.Repos.Reviews.Find(id)
+	return find(review, err, "review", id)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestReviewServiceSubmit(t *testing.T) {
+	four := int32(4)
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		input   model.ReviewInput
+		wantErr string
+	}{
+		{"volunteer reviews the nonprofit", volunteerID, completedEngagementID, model.ReviewInput{Rating: 5, ProjectRating: &four}, ""},
+		{"member reviews the volunteer", memberID, completedEngagementID, model.ReviewInput{Rating: 5, EndorsedSkills: []string{"Logistics"}}, ""},
+		{"platform admin reviews the volunteer", adminID, completedEngagementID, model.ReviewInput{Rating: 5}, ""},
+		{"outsider", outsiderID, completedEngagementID, model.ReviewInput{Rating: 5}, "unauthorized"},
+		{"member cannot rate the project", memberID, completedEngagementID, model.ReviewInput{Rating: 5, ProjectRating: &four}, "input.projectRating"},
+		{"volunteer cannot endorse skills", volunteerID, completedEngagementID, model.ReviewInput{Rating: 5, EndorsedSkills: []string{"Logistics"}}, "input.endorsedSkills"},
+		{"rating out of range", volunteerID, completedEngagementID, model.ReviewInput{Rating: 6}, "input.rating"},
+		{"missing engagement", volunteerID, missingID, model.ReviewInput{Rating: 5}, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewReviewService(work, store.repos())
+			_, err := s.Submit(context.Background(), store.user(tt.userID), tt.id, tt.input)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestReviewServiceFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      uint
+		reason  string
+		wantErr string
+	}{
+		{"flagged", reviewID, "Insulting", ""},
+		{"no reason", reviewID, "", "reason"},
+		{"missing review", missingID, "Insulting", "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewReviewService(work, store.repos())
+			err := s.Flag(context.Background(), store.user(outsiderID), tt.id, tt.reason)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestReviewServiceModerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"platform admin", adminID, reviewID, ""},
+		{"member of the reviewed nonprofit", memberID, reviewID, "unauthorized"},
+		{"volunteer", volunteerID, reviewID, "unauthorized"},
+		{"missing review", adminID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewReviewService(work, store.repos())
+			_, err := s.Moderate(context.Background(), store.user(tt.userID), tt.id, true)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package services holds the business rules behind the API's mutations: who may do what to
+// users, nonprofits, projects, applications, engagements and their hours, shifts, reviews,
+// messages, notifications and webhooks, and what else has to happen when they do. The GraphQL
+// resolvers only translate their arguments and results, so the same rules can be reused by the
+// CLI, the jobs or another API.
+//
+// Services read through the repository interfaces in Repositories and write through a
+// UnitOfWork, so the rules can be tested against fakes. A unit of work's transaction carries the
+// change together with the status history, outbox events and audit log entries of the lifecycle,
+// outbox and audit packages, which all work on a *gorm.DB.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned by repositories when the record asked for does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// Repositories bundles the data access the services need.
+type Repositories struct {
+	Users         UserRepository
+	Nonprofits    NonprofitRepository
+	Projects      ProjectRepository
+	Applications  ApplicationRepository
+	Engagements   EngagementRepository
+	Hours         HoursRepository
+	Shifts        ShiftRepository
+	Reviews       ReviewRepository
+	Conversations ConversationRepository
+	Webhooks      WebhookRepository
+	Catalog       CatalogRepository
+	Analytics     AnalyticsRepository
+}
+
+// NewRepositories returns the Repositories backed by db.
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:         &gormUsers{db},
+		Nonprofits:    &gormNonprofits{db},
+		Projects:      &gormProjects{db},
+		Applications:  &gormApplications{db},
+		Engagements:   &gormEngagements{db},
+		Hours:         &gormHours{db},
+		Shifts:        &gormShifts{db},
+		Reviews:       &gormReviews{db},
+		Conversations: &gormConversations{db},
+		Webhooks:      &gormWebhooks{db},
+		Catalog:       &gormCatalog{db},
+		Analytics:     &gormAnalytics{db},
+	}
+}
+
+// UnitOfWork runs the writes of one change in a single transaction.
+type UnitOfWork interface {
+	// Do runs fn in a transaction, committing it if fn returns nil and rolling it back otherwise.
+	Do(ctx context.Context, fn func(tx *gorm.DB) error) error
+}
+
+type gormUnitOfWork struct{ db *gorm.DB }
+
+// NewUnitOfWork returns the UnitOfWork running transactions on db.
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{db}
+}
+
+func (w *gormUnitOfWork) Do(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return w.db.WithContext(ctx).Transaction(fn)
+}
+
+// find wraps the error of a repository lookup, turning ErrNotFound into a "not found" error
+// naming the record.
+func find[T any](record *T, err error, name string, id uint) (*T, error) {
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%s with ID %d not found", name, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	return record, nil
+}
+
+// ProjectActor works out in which capacity user acts on projects of the given nonprofit. ok is
+// false when the user has no rights over them at all.
+func ProjectActor(nonprofits NonprofitRepository, user *model.User, nonprofitID uint) (actor lifecycle.Actor, ok bool, err error) {
+	if user.Role == model.PlatformAdmin {
+		return lifecycle.ActorPlatformAdmin, true, nil
+	}
+	member, err := nonprofits.IsMember(nonprofitID, user.ID)
+	if err != nil {
+		return "", false, err
+	}
+	if member {
+		return lifecycle.ActorNonprofitMember, true, nil
+	}
+	return "", false, nil
+}
+
+// RequireNonprofitMember returns an authorization error unless user is a member of the nonprofit
+// or a platform admin.
+func RequireNonprofitMember(nonprofits NonprofitRepository, user *model.User, nonprofitID uint, action string) error {
+	_, ok, err := ProjectActor(nonprofits, user, nonprofitID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("unauthorized: you must be a member of the nonprofit or a platform admin to %s", action)
+	}
+	return nil
+}
+
+// RequirePlatformAdmin returns an authorization error unless user is a platform admin.
+func RequirePlatformAdmin(user *model.User, action string) error {
+	if user.Role != model.PlatformAdmin {
+		return fmt.Errorf("unauthorized: only platform admins can %s", action)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+)
+
+func TestProjectActor(t *testing.T) {
+	store := newFakeStore()
+	tests := []struct {
+		name      string
+		userID    uint
+		nonprofit uint
+		want      lifecycle.Actor
+		wantOK    bool
+	}{
+		{"platform admin", adminID, nonprofitID, lifecycle.ActorPlatformAdmin, true},
+		{"platform admin of any nonprofit", adminID, missingID, lifecycle.ActorPlatformAdmin, true},
+		{"member", memberID, nonprofitID, lifecycle.ActorNonprofitMember, true},
+		{"member of another nonprofit", memberID, missingID, "", false},
+		{"nonprofit admin role alone", outsiderID, nonprofitID, "", false},
+		{"volunteer", volunteerID, nonprofitID, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor, ok, err := ProjectActor(store.repos().Nonprofits, store.user(tt.userID), tt.nonprofit)
+			if err != nil {
+				t.Fatalf("ProjectActor: %v", err)
+			}
+			if actor != tt.want || ok != tt.wantOK {
+				t.Fatalf("ProjectActor = %q, %v; want %q, %v", actor, ok, tt.want, tt.wantOK)
+			}
+
+			err = RequireNonprofitMember(store.repos().Nonprofits, store.user(tt.userID), tt.nonprofit, "do this")
+			if tt.wantOK != (err == nil) {
+				t.Fatalf("RequireNonprofitMember = %v, want allowed %v", err, tt.wantOK)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), "unauthorized: ") {
+				t.Fatalf("RequireNonprofitMember = %v, want an unauthorized error", err)
+			}
+		})
+	}
+}
+
+func TestRequirePlatformAdmin(t *testing.T) {
+	store := newFakeStore()
+	for _, id := range []uint{adminID, memberID, outsiderID, volunteerID} {
+		err := RequirePlatformAdmin(store.user(id), "do this")
+		if want := id == adminID; want != (err == nil) {
+			t.Errorf("RequirePlatformAdmin(%s) = %v, want allowed %v", store.users[id].Role, err, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// ShiftService schedules projects' shifts and signs volunteers up for them.
+type ShiftService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewShiftService(work UnitOfWork, repos Repositories) *ShiftService {
+	return &ShiftService{Work: work, Repos: repos}
+}
+
+// Create schedules a shift on a project that is still taking volunteers. Times are entered in the
+// project's timezone and stored as instants. Members of the project's nonprofit and platform
+// admins can.
+func (s *ShiftService) Create(ctx context.Context, user *model.User, projectID uint, input model.ShiftInput) (*model.Shift, error) {
+	project, err := s.findProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, project.NonprofitID, "schedule shifts"); err != nil {
+		return nil, err
+	}
+	if project.Status == model.Completed || project.Status == model.Cancelled {
+		return nil, fmt.Errorf("project is not accepting volunteers (current status: %s)", project.Status)
+	}
+
+	startsAt, endsAt, err := validation.ShiftInput(input, ProjectLocation(project))
+	if err != nil {
+		return nil, err
+	}
+
+	shift := model.Shift{
+		ProjectID: project.ID,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Capacity:  int(input.Capacity),
+	}
+	if input.Location != nil {
+		shift.Location = *input.Location
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&shift).Error; err != nil {
+			return fmt.Errorf("failed to create shift: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// Delete deletes a shift that has not started yet. Members of the project's nonprofit and
+// platform admins can.
+func (s *ShiftService) Delete(ctx context.Context, user *model.User, id uint) error {
+	shift, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	project, err := s.findProject(shift.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, project.NonprofitID, "delete shifts"); err != nil {
+		return err
+	}
+	if !shift.StartsAt.After(time.Now()) {
+		return lifecycle.ErrShiftStarted
+	}
+
+	return s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(shift).Error; err != nil {
+			return fmt.Errorf("failed to delete shift: %w", err)
+		}
+		return nil
+	})
+}
+
+// SignUp signs volunteer up for a shift of a project they are engaged on.
+func (s *ShiftService) SignUp(ctx context.Context, volunteer *model.User, id uint) (*model.Shift, error) {
+	shift, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		_, err := lifecycle.SignUpForShift(tx, shift.ID, volunteer)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// CancelSignup takes volunteer off a shift that has not started yet.
+func (s *ShiftService) CancelSignup(ctx context.Context, volunteer *model.User, id uint) (*model.Shift, error) {
+	shift, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		return lifecycle.CancelShiftSignup(tx, shift.ID, volunteer.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) find(id uint) (*model.Shift, error) {
+	shift, err := s.Repos.Shifts.Find(id)
+	return find(shift, err, "shift", id)
+}
+
+func (s *ShiftService) findProject(id uint) (*model.Project, error) {
+	project, err := s.Repos.Projects.Find(id)
+	return find(project, err, "project", id)
+}
+
+// ProjectLocation returns the timezone a project's shifts are scheduled in.
+func ProjectLocation(project *model.Project) *time.Location {
+	if project.Timezone != "" {
+		if loc, err := time.LoadLocation(project.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
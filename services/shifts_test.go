@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestShiftServiceCreate(t *testing.T) {
+	valid := model.ShiftInput{
+		Date:      time.Now().AddDate(0, 0, 2).Format("2006-01-02"),
+		StartTime: "09:00",
+		EndTime:   "12:00",
+		Capacity:  3,
+	}
+	backwards := valid
+	backwards.EndTime = "08:00"
+
+	tests := []struct {
+		name      string
+		userID    uint
+		projectID uint
+		input     model.ShiftInput
+		wantErr   string
+	}{
+		{"member", memberID, activeProjectID, valid, ""},
+		{"platform admin", adminID, activeProjectID, valid, ""},
+		{"outsider", outsiderID, activeProjectID, valid, "unauthorized"},
+		{"volunteer", volunteerID, activeProjectID, valid, "unauthorized"},
+		{"completed project", memberID, completedProjectID, valid, "not accepting volunteers"},
+		{"ends before it starts", memberID, activeProjectID, backwards, "input.endTime"},
+		{"missing project", memberID, missingID, valid, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewShiftService(work, store.repos())
+			_, err := s.Create(context.Background(), store.user(tt.userID), tt.projectID, tt.input)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestShiftServiceDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"member", memberID, upcomingShiftID, ""},
+		{"platform admin", adminID, upcomingShiftID, ""},
+		{"outsider", outsiderID, upcomingShiftID, "unauthorized"},
+		{"volunteer", volunteerID, upcomingShiftID, "unauthorized"},
+		{"started shift", memberID, startedShiftID, "started"},
+		{"missing shift", memberID, missingID, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewShiftService(work, store.repos())
+			err := s.Delete(context.Background(), store.user(tt.userID), tt.id)
+			expect(t, err, tt.wantErr, work)
+		})
+	}
+}
+
+func TestProjectLocation(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"Europe/Berlin", "Europe/Berlin"},
+		{"", "UTC"},
+		{"Not/AZone", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := ProjectLocation(&model.Project{Timezone: tt.timezone}).String(); got != tt.want {
+			t.Errorf("ProjectLocation(%q) = %s, want %s", tt.timezone, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The tests in this file run the services' writes, which the fakes skip, against Postgres. Each
+// works in a transaction that is rolled back when it ends.
+
+// testTx connects to the Postgres database in TEST_DATABASE_URL, skipping the test without one,
+// and returns a transaction that is rolled back when the test ends.
+func testTx(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&model.User{}, &model.Nonprofit{}, &model.Project{}, &model.ProjectStatusChange{},
+		&model.ProjectRoleSlot{}, &model.Application{}, &model.Engagement{}, &model.AuditEvent{},
+		&outbox.Event{},
+	); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// seedProject creates a project of a new nonprofit with room for volunteersNeeded, a member of
+// the nonprofit and two volunteers who applied to it.
+func seedProject(t *testing.T, tx *gorm.DB, volunteersNeeded int) (member *model.User, project *model.Project, applications [2]*model.Application) {
+	t.Helper()
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	user := func(name string, role model.UserRole) *model.User {
+		u := &model.User{Email: name + suffix + "@example.org", Role: role}
+		if err := tx.Create(u).Error; err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		return u
+	}
+	member = user("member", model.NonprofitAdmin)
+	nonprofit := &model.Nonprofit{Name: "Food Bank", EIN: suffix, Members: []model.User{*member}}
+	if err := tx.Create(nonprofit).Error; err != nil {
+		t.Fatalf("failed to create nonprofit: %v", err)
+	}
+	project = &model.Project{NonprofitID: nonprofit.ID, Title: "Pantry shifts", Status: model.Active, VolunteersNeeded: volunteersNeeded}
+	if err := tx.Create(project).Error; err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	for i := range applications {
+		volunteer := user(fmt.Sprintf("volunteer%d-", i), model.Volunteer)
+		applications[i] = &model.Application{VolunteerID: volunteer.ID, ProjectID: project.ID, Status: model.Pending, AppliedAt: time.Now()}
+		if err := tx.Create(applications[i]).Error; err != nil {
+			t.Fatalf("failed to create application: %v", err)
+		}
+	}
+	return member, project, applications
+}
+
+func TestApplicationServiceAcceptPastCapacity(t *testing.T) {
+	tx := testTx(t)
+	member, project, applications := seedProject(t, tx, 1)
+	s := NewApplicationService(NewUnitOfWork(tx), NewRepositories(tx))
+	ctx := context.Background()
+
+	first, err := s.Accept(ctx, member, applications[0].ID, false)
+	if err != nil {
+		t.Fatalf("accepting the first application: %v", err)
+	}
+	if first.Status != model.Accepted {
+		t.Fatalf("first application is %s, want ACCEPTED", first.Status)
+	}
+	second, err := s.Accept(ctx, member, applications[1].ID, false)
+	if err != nil {
+		t.Fatalf("accepting the second application: %v", err)
+	}
+	if second.Status != model.Waitlisted || second.WaitlistedAt == nil {
+		t.Fatalf("second application is %s, want WAITLISTED with a time", second.Status)
+	}
+
+	// The accepted volunteer stepping back makes room for the waitlisted one
+	volunteer := &model.User{Model: gorm.Model{ID: first.VolunteerID}, Role: model.Volunteer}
+	if _, err := s.Withdraw(ctx, volunteer, first.ID); err != nil {
+		t.Fatalf("withdrawing the first application: %v", err)
+	}
+	var promoted model.Application
+	if err := tx.First(&promoted, second.ID).Error; err != nil {
+		t.Fatalf("failed to reload the second application: %v", err)
+	}
+	if promoted.Status != model.Accepted {
+		t.Fatalf("second application is %s after the withdrawal, want ACCEPTED", promoted.Status)
+	}
+
+	var history int64
+	if err := tx.Model(&model.AuditEvent{}).Where("target_type = ? AND nonprofit_id = ?", "Application", project.NonprofitID).Count(&history).Error; err != nil {
+		t.Fatalf("failed to count audit events: %v", err)
+	}
+	if history != 3 {
+		t.Fatalf("recorded %d audit events, want one for each accept and the withdrawal", history)
+	}
+}
+
+func TestApplicationServiceAcceptAutoStart(t *testing.T) {
+	tx := testTx(t)
+	member, project, applications := seedProject(t, tx, 0)
+	s := NewApplicationService(NewUnitOfWork(tx), NewRepositories(tx))
+
+	application, err := s.Accept(context.Background(), member, applications[0].ID, true)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if application.Status != model.Accepted {
+		t.Fatalf("application is %s, want ACCEPTED", application.Status)
+	}
+
+	var engagement model.Engagement
+	if err := tx.Where("volunteer_id = ? AND project_id = ?", application.VolunteerID, project.ID).First(&engagement).Error; err != nil {
+		t.Fatalf("no engagement was started: %v", err)
+	}
+	if engagement.Status != model.EngagementActive {
+		t.Fatalf("engagement is %s, want ACTIVE", engagement.Status)
+	}
+	// The first engagement puts the project in progress
+	if err := tx.First(project, project.ID).Error; err != nil {
+		t.Fatalf("failed to reload project: %v", err)
+	}
+	if project.Status != model.InProgress {
+		t.Fatalf("project is %s, want IN_PROGRESS", project.Status)
+	}
+}
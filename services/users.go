@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/media"
+	"github.com/prkagrawal/cosmos-bk2/profiles"
+	"github.com/prkagrawal/cosmos-bk2/storage"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"gorm.io/gorm"
+)
+
+// UserService manages a user's own profile and lets platform admins look users up.
+type UserService struct {
+	Work    UnitOfWork
+	Repos   Repositories
+	Storage storage.Store
+}
+
+func NewUserService(work UnitOfWork, repos Repositories, store storage.Store) *UserService {
+	return &UserService{Work: work, Repos: repos, Storage: store}
+}
+
+// UpdateProfile applies the fields set in input to user's profile. Causes are matched by name;
+// unknown ones are ignored.
+func (s *UserService) UpdateProfile(ctx context.Context, user *model.User, input model.ProfileInput) (*model.User, error) {
+	if err := validation.ProfileInput(input); err != nil {
+		return nil, err
+	}
+
+	if input.FirstName != nil {
+		user.FirstName = *input.FirstName
+	}
+	if input.LastName != nil {
+		user.LastName = *input.LastName
+	}
+	if input.Bio != nil {
+		user.Bio = *input.Bio
+	}
+	if input.LinkedIn != nil {
+		user.LinkedInURL = *input.LinkedIn
+	}
+	if input.Portfolio != nil {
+		user.PortfolioURL = *input.Portfolio
+	}
+
+	oldAvatar := user.AvatarURL
+	if input.Avatar != nil {
+		// Validate, resize and store the uploaded image; we keep the base key of its variants
+		avatar := &model.Upload{
+			Filename: input.Avatar.Filename,
+			File:     input.Avatar.File,
+		}
+		avatarKey, err := media.ProcessUpload(ctx, s.Storage, avatar, media.Avatar)
+		if errors.Is(err, media.ErrInvalidImage) {
+			return nil, validation.Field("input.avatar", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save avatar: %w", err)
+		}
+		user.AvatarURL = avatarKey
+	}
+
+	var causes []*model.Cause
+	if input.Causes != nil {
+		var err error
+		if causes, err = s.Repos.Catalog.Causes(input.Causes); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if input.Causes != nil {
+			if err := tx.Model(user).Association("Causes").Replace(causes); err != nil {
+				return fmt.Errorf("failed to update user causes: %w", err)
+			}
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return fmt.Errorf("failed to update profile: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if user.AvatarURL != oldAvatar {
+			_ = media.DeleteVariants(ctx, s.Storage, user.AvatarURL)
+		}
+		return nil, err
+	}
+	// Only once the new avatar is saved; best effort, an orphaned old one is not worth failing for
+	if user.AvatarURL != oldAvatar {
+		_ = media.DeleteVariants(ctx, s.Storage, oldAvatar)
+	}
+	return user, nil
+}
+
+// AddSkills adds the named skills to user's, creating the ones that don't exist yet.
+func (s *UserService) AddSkills(ctx context.Context, user *model.User, names []string) (*model.User, error) {
+	skills, err := s.Repos.Catalog.FindOrCreateSkills(names)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Skills").Append(skills); err != nil {
+			return fmt.Errorf("failed to add skills: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(user)
+}
+
+// RemoveSkill removes the named skill from user's.
+func (s *UserService) RemoveSkill(ctx context.Context, user *model.User, name string) (*model.User, error) {
+	skill, err := s.Repos.Catalog.Skill(name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("skill '%s' not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find skill '%s': %w", name, err)
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Skills").Delete(skill); err != nil {
+			return fmt.Errorf("failed to remove skill: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(user)
+}
+
+// SetAvailability sets when user is available to volunteer.
+func (s *UserService) SetAvailability(ctx context.Context, user *model.User, input model.AvailabilityInput) (*model.User, error) {
+	if err := validation.AvailabilityInput(input); err != nil {
+		return nil, err
+	}
+
+	user.Availability.HoursPerWeek = int(input.HoursPerWeek)
+	user.Availability.DaysAvailable = model.Weekdays(input.DaysAvailable)
+	user.Availability.Timezone = input.Timezone
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return fmt.Errorf("failed to set availability: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetProfileVisibility sets who can see user's profile. A profile made public for the first time
+// gets its slug.
+func (s *UserService) SetProfileVisibility(ctx context.Context, user *model.User, visibility model.ProfileVisibility) (*model.User, error) {
+	updates := map[string]interface{}{"profile_visibility": visibility}
+	if visibility == model.ProfilePublic && user.ProfileSlug == nil {
+		updates["profile_slug"] = profiles.NewSlug()
+	}
+	return s.update(ctx, user, updates, "profile visibility")
+}
+
+// SetDigestFrequency sets how often user gets a digest of their notifications.
+func (s *UserService) SetDigestFrequency(ctx context.Context, user *model.User, frequency model.DigestFrequency) (*model.User, error) {
+	return s.update(ctx, user, map[string]interface{}{"digest_frequency": frequency}, "digest frequency")
+}
+
+// update writes the given columns of user, naming what changed in the error.
+// Search returns a page of the users matching query, see AnalyticsRepository.SearchUsers,
+// optionally only those with role. Platform admins can.
+func (s *UserService) Search(admin *model.User, query string, role *model.UserRole, limit, offset int) ([]*model.User, error) {
+	if err := RequirePlatformAdmin(admin, "search users"); err != nil {
+		return nil, err
+	}
+
+	v := validation.New()
+	v.MinLength(query, 2, "query")
+	v.MaxLength(query, validation.MaxSearchLength, "query")
+	v.Check(offset >= 0, "offset", "must not be negative")
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return s.Repos.Analytics.SearchUsers(query, role, limit, offset)
+}
+
+func (s *UserService) update(ctx context.Context, user *model.User, updates map[string]interface{}, what string) (*model.User, error) {
+	err := s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update %s: %w", what, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(user)
+}
+
+// reload fetches user again after a change to their associations.
+func (s *UserService) reload(user *model.User) (*model.User, error) {
+	reloaded, err := s.Repos.Users.Find(user.ID)
+	return find(reloaded, err, "user", user.ID)
+}
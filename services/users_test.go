@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestUserServiceSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		query   string
+		offset  int
+		wantErr string
+	}{
+		{"platform admin", adminID, "ann", 0, ""},
+		{"next page", adminID, "ann", 25, ""},
+		{"nonprofit member", memberID, "ann", 0, "unauthorized"},
+		{"volunteer", volunteerID, "ann", 0, "unauthorized"},
+		{"query too short", adminID, "a", 0, "query"},
+		{"negative offset", adminID, "ann", -1, "offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			s := NewUserService(&fakeWork{}, store.repos(), nil)
+			_, err := s.Search(store.user(tt.userID), tt.query, nil, 25, tt.offset)
+			expectErr(t, err, tt.wantErr)
+		})
+	}
+}
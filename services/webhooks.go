@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/prkagrawal/cosmos-bk2/audit"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/validation"
+	"github.com/prkagrawal/cosmos-bk2/webhooks"
+	"gorm.io/gorm"
+)
+
+// WebhookService manages nonprofits' webhook subscriptions and their deliveries. Members of the
+// nonprofit and platform admins can.
+type WebhookService struct {
+	Work  UnitOfWork
+	Repos Repositories
+}
+
+func NewWebhookService(work UnitOfWork, repos Repositories) *WebhookService {
+	return &WebhookService{Work: work, Repos: repos}
+}
+
+// Create subscribes a receiver to the nonprofit's events, with a new signing secret.
+func (s *WebhookService) Create(ctx context.Context, user *model.User, nonprofitID uint, input model.WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	exists, err := s.Repos.Nonprofits.Exists(nonprofitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nonprofit: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("nonprofit with ID %d not found", nonprofitID)
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, nonprofitID, "manage webhooks"); err != nil {
+		return nil, err
+	}
+	if err := validation.WebhookSubscriptionInput(input); err != nil {
+		return nil, err
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscription := model.WebhookSubscription{
+		NonprofitID: nonprofitID,
+		URL:         input.URL,
+		Secret:      secret,
+		Events:      webhookEvents(input.Events),
+		Active:      input.Active == nil || *input.Active,
+		CreatedByID: &user.ID,
+	}
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&subscription).Error; err != nil {
+			return fmt.Errorf("failed to create webhook subscription: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("WebhookSubscription", subscription.ID, subscription.NonprofitID, nil, &subscription))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Update replaces a subscription's receiver and events.
+func (s *WebhookService) Update(ctx context.Context, user *model.User, id uint, input model.WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	subscription, err := s.manage(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.WebhookSubscriptionInput(input); err != nil {
+		return nil, err
+	}
+
+	before := *subscription
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(subscription).Updates(map[string]interface{}{
+			"url":    input.URL,
+			"events": webhookEvents(input.Events),
+			"active": input.Active == nil || *input.Active,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update webhook subscription: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("WebhookSubscription", subscription.ID, subscription.NonprofitID, &before, subscription))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(id)
+}
+
+// Delete deletes a subscription. Queued deliveries notice it is gone and give up.
+func (s *WebhookService) Delete(ctx context.Context, user *model.User, id uint) error {
+	subscription, err := s.manage(user, id)
+	if err != nil {
+		return err
+	}
+	return s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(subscription).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook subscription: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("WebhookSubscription", subscription.ID, subscription.NonprofitID, subscription, nil))
+	})
+}
+
+// RotateSecret gives a subscription a new signing secret.
+func (s *WebhookService) RotateSecret(ctx context.Context, user *model.User, id uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.manage(user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	before := *subscription
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(subscription).Update("secret", secret).Error; err != nil {
+			return fmt.Errorf("failed to rotate webhook secret: %w", err)
+		}
+		return audit.Record(ctx, tx, audit.Change("WebhookSubscription", subscription.ID, subscription.NonprofitID, &before, subscription))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Redeliver queues the payload of a delivery again as a new delivery.
+func (s *WebhookService) Redeliver(ctx context.Context, user *model.User, deliveryID uint) (*model.WebhookDelivery, error) {
+	delivery, err := s.Repos.Webhooks.Delivery(deliveryID)
+	if delivery, err = find(delivery, err, "webhook delivery", deliveryID); err != nil {
+		return nil, err
+	}
+	if _, err := s.manage(user, delivery.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	var redelivery *model.WebhookDelivery
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		redelivery, err = webhooks.Redeliver(tx, delivery)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redelivery, nil
+}
+
+// Ping queues a PING delivery to a subscription to check its receiver.
+func (s *WebhookService) Ping(ctx context.Context, user *model.User, id uint) (*model.WebhookDelivery, error) {
+	subscription, err := s.manage(user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery *model.WebhookDelivery
+	err = s.Work.Do(ctx, func(tx *gorm.DB) error {
+		var err error
+		delivery, err = webhooks.Ping(tx, subscription)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// manage loads a subscription user is about to change.
+func (s *WebhookService) manage(user *model.User, id uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireNonprofitMember(s.Repos.Nonprofits, user, subscription.NonprofitID, "manage webhooks"); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *WebhookService) find(id uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.Repos.Webhooks.Subscription(id)
+	return find(subscription, err, "webhook subscription", id)
+}
+
+// webhookEvents converts subscribed events to their stored form, dropping duplicates.
+func webhookEvents(events []model.WebhookEvent) model.Strings {
+	stored := model.Strings{}
+	for _, event := range events {
+		if !slices.Contains(stored, string(event)) {
+			stored = append(stored, string(event))
+		}
+	}
+	return stored
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+func TestWebhookServiceCreate(t *testing.T) {
+	valid := model.WebhookSubscriptionInput{
+		URL:    "https://example.org/hooks",
+		Events: []model.WebhookEvent{model.WebhookHoursLogged, model.WebhookHoursLogged},
+	}
+	plainHTTP := valid
+	plainHTTP.URL = "http://example.org/hooks"
+	ping := valid
+	ping.Events = []model.WebhookEvent{model.WebhookPing}
+
+	tests := []struct {
+		name      string
+		userID    uint
+		nonprofit uint
+		input     model.WebhookSubscriptionInput
+		wantErr   string
+	}{
+		{"member", memberID, nonprofitID, valid, ""},
+		{"platform admin", adminID, nonprofitID, valid, ""},
+		{"outsider", outsiderID, nonprofitID, valid, "unauthorized"},
+		{"volunteer", volunteerID, nonprofitID, valid, "unauthorized"},
+		{"plain http", memberID, nonprofitID, plainHTTP, "input.url"},
+		{"subscribing to ping", memberID, nonprofitID, ping, "input.events"},
+		{"missing nonprofit", adminID, missingID, valid, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, work := newFakeStore(), &fakeWork{}
+			s := NewWebhookService(work, store.repos())
+			subscription, err := s.Create(context.Background(), store.user(tt.userID), tt.nonprofit, tt.input)
+			expect(t, err, tt.wantErr, work)
+			if err != nil {
+				return
+			}
+			if subscription.Secret == "" || !slices.Equal(subscription.Events, model.Strings{string(model.WebhookHoursLogged)}) {
+				t.Fatalf("subscription has secret %q and events %v, want a secret and HOURS_LOGGED once", subscription.Secret, subscription.Events)
+			}
+		})
+	}
+}
+
+func TestWebhookServiceManage(t *testing.T) {
+	input := model.WebhookSubscriptionInput{URL: "https://example.org/v2/hooks", Events: []model.WebhookEvent{model.WebhookHoursLogged}}
+	actions := map[string]func(s *WebhookService, user *model.User, id uint) error{
+		"update": func(s *WebhookService, user *model.User, id uint) error {
+			_, err := s.Update(context.Background(), user, id, input)
+			return err
+		},
+		"delete": func(s *WebhookService, user *model.User, id uint) error {
+			return s.Delete(context.Background(), user, id)
+		},
+		"rotate secret": func(s *WebhookService, user *model.User, id uint) error {
+			_, err := s.RotateSecret(context.Background(), user, id)
+			return err
+		},
+		"ping": func(s *WebhookService, user *model.User, id uint) error {
+			_, err := s.Ping(context.Background(), user, id)
+			return err
+		},
+		"redeliver": func(s *WebhookService, user *model.User, id uint) error {
+			if id == subscriptionID {
+				id = deliveryID
+			}
+			_, err := s.Redeliver(context.Background(), user, id)
+			return err
+		},
+	}
+	tests := []struct {
+		name    string
+		userID  uint
+		id      uint
+		wantErr string
+	}{
+		{"member", memberID, subscriptionID, ""},
+		{"platform admin", adminID, subscriptionID, ""},
+		{"outsider", outsiderID, subscriptionID, "unauthorized"},
+		{"volunteer", volunteerID, subscriptionID, "unauthorized"},
+		{"missing", memberID, missingID, "not found"},
+	}
+	for action, do := range actions {
+		for _, tt := range tests {
+			t.Run(action+"/"+tt.name, func(t *testing.T) {
+				store, work := newFakeStore(), &fakeWork{}
+				s := NewWebhookService(work, store.repos())
+				err := do(s, store.user(tt.userID), tt.id)
+				expect(t, err, tt.wantErr, work)
+			})
+		}
+	}
+}
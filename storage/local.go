@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps files on the local filesystem under Dir and serves them itself (see Handler).
+// Files do not survive a redeploy unless Dir is on a persistent volume.
+type LocalStore struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocalStore(dir, publicURL string) *LocalStore {
+	return &LocalStore{Dir: dir, PublicURL: strings.TrimSuffix(publicURL, "/")}
+}
+
+// path maps a key to a file path, refusing keys that would escape Dir.
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial upload
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to copy uploaded file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) URL(ctx context.Context, key string) (string, error) {
+	return s.PublicURL + "/" + strings.TrimPrefix(key, "/"), nil
+}
+
+// Handler serves stored files. Mount it with the PublicURL path stripped, e.g.
+// router.Handle("/uploads/*", http.StripPrefix("/uploads", store.Handler())).
+// Directory listings are disabled so keys cannot be enumerated.
+func (s *LocalStore) Handler() http.Handler {
+	fs := http.FileServer(http.Dir(s.Dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	})
+}
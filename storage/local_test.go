@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorePutGetDelete(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "/uploads/")
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "avatars/abc.png", strings.NewReader("png bytes"), 9, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := store.Get(ctx, "avatars/abc.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(got) != "png bytes" {
+		t.Fatalf("Get returned %q", got)
+	}
+
+	if u, _ := store.URL(ctx, "avatars/abc.png"); u != "/uploads/avatars/abc.png" {
+		t.Fatalf("URL returned %q", u)
+	}
+
+	if err := store.Delete(ctx, "avatars/abc.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "avatars/abc.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "avatars/abc.png"); err != nil {
+		t.Fatalf("second Delete: %v", err)
+	}
+}
+
+func TestLocalStoreKeysStayInDir(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "/uploads")
+
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"avatars/abc.png", false},
+		{"../escape.png", false}, // cleaned to /escape.png inside Dir
+		{"/", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		p, err := store.path(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("path(%q): err = %v, wantErr %v", tt.key, err, tt.wantErr)
+		}
+		if err == nil && !strings.HasPrefix(p, dir) {
+			t.Fatalf("path(%q) = %q escapes %q", tt.key, p, dir)
+		}
+	}
+}
+
+func TestLocalStoreHandlerHidesDirectories(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "/uploads")
+	if err := store.Put(context.Background(), "avatars/abc.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/avatars/abc.png", http.StatusOK},
+		{"/avatars/", http.StatusNotFound},
+		{"/avatars/missing.png", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s: %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
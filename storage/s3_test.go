@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testBucket    = "uploads"
+	testRegion    = "eu-west-1"
+)
+
+// fakeS3 is a path-style, MinIO-like object store that checks the SigV4 signature of every
+// request, from the Authorization header or a presigned query string.
+type fakeS3 struct {
+	t       *testing.T
+	secret  string
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{t: t, secret: testSecretKey, objects: make(map[string]fakeObject)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.verify(r); err != nil {
+		http.Error(w, "SignatureDoesNotMatch: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if int64(len(body)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.body)
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify recomputes the request's signature from scratch.
+func (f *fakeS3) verify(r *http.Request) error {
+	query := r.URL.Query()
+	var credential, signedHeaders, signature, amzDate, payloadHash string
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		fields := map[string]string{}
+		for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+			k, v, _ := strings.Cut(part, "=")
+			fields[k] = v
+		}
+		credential, signedHeaders, signature = fields["Credential"], fields["SignedHeaders"], fields["Signature"]
+		amzDate = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	} else {
+		credential, signedHeaders, signature = query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		payloadHash = "UNSIGNED-PAYLOAD"
+		query.Del("X-Amz-Signature")
+	}
+	if signature == "" {
+		return errors.New("missing signature")
+	}
+	scope := strings.SplitN(credential, "/", 2)
+	if len(scope) != 2 || scope[0] != testAccessKey {
+		return errors.New("unknown access key")
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, url.QueryEscape(k)+"="+strings.ReplaceAll(url.QueryEscape(query.Get(k)), "+", "%20"))
+	}
+	canonical := strings.Join([]string{r.Method, r.URL.EscapedPath(), strings.Join(pairs, "&"), headers.String(), signedHeaders, payloadHash}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope[1], hex.EncodeToString(sum[:])}, "\n")
+
+	parts := strings.Split(scope[1], "/") // date/region/service/aws4_request
+	key := []byte("AWS4" + f.secret)
+	for _, p := range parts {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(p))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	if want := hex.EncodeToString(mac.Sum(nil)); !hmac.Equal([]byte(want), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func newTestS3Store(t *testing.T, endpoint, secret string) *S3Store {
+	t.Helper()
+	store, err := NewS3Store(S3Config{
+		Endpoint:        endpoint,
+		Region:          testRegion,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: secret,
+		UsePathStyle:    true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	return store
+}
+
+func TestS3StorePutGetDelete(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		body string
+	}{
+		{"plain key", "avatars/abc.png", "png bytes"},
+		{"key needing escaping", "logos/with space+plus(1).webp", "webp bytes"},
+		{"empty object", "avatars/empty.png", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, srv := newFakeS3(t)
+			store := newTestS3Store(t, srv.URL, testSecretKey)
+			ctx := context.Background()
+
+			if err := store.Put(ctx, tt.key, strings.NewReader(tt.body), int64(len(tt.body)), "image/png"); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if got := fake.objects[tt.key]; string(got.body) != tt.body || got.contentType != "image/png" {
+				t.Fatalf("stored %q (%s), want %q (image/png)", got.body, got.contentType, tt.body)
+			}
+
+			rc, err := store.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			got, _ := io.ReadAll(rc)
+			rc.Close()
+			if !bytes.Equal(got, []byte(tt.body)) {
+				t.Fatalf("Get returned %q, want %q", got, tt.body)
+			}
+
+			if err := store.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := store.Get(ctx, tt.key); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get after Delete: got %v, want ErrNotFound", err)
+			}
+			// Deleting again is not an error
+			if err := store.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("second Delete: %v", err)
+			}
+		})
+	}
+}
+
+func TestS3StoreRejectedSignature(t *testing.T) {
+	_, srv := newFakeS3(t)
+	store := newTestS3Store(t, srv.URL, "not-the-secret")
+
+	err := store.Put(context.Background(), "avatars/abc.png", strings.NewReader("x"), 1, "image/png")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Put with a wrong secret: got %v, want a 403 error", err)
+	}
+}
+
+func TestS3StorePresign(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	store := newTestS3Store(t, srv.URL, testSecretKey)
+	fake.objects["avatars/abc.png"] = fakeObject{body: []byte("png bytes"), contentType: "image/png"}
+
+	u, err := store.URL(context.Background(), "avatars/abc.png")
+	if err != nil {
+		t.Fatalf("URL: %v", err)
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET presigned URL: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "png bytes" {
+		t.Fatalf("GET presigned URL: %s %q", resp.Status, body)
+	}
+}
+
+func TestS3StoreSignsWithCurrentTime(t *testing.T) {
+	_, srv := newFakeS3(t)
+	store := newTestS3Store(t, srv.URL, testSecretKey)
+	store.now = func() time.Time { return time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC) }
+
+	if err := store.Put(context.Background(), "a.txt", strings.NewReader("a"), 1, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+}
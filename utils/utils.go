@@ -3,14 +3,8 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
-
-	"github.com/google/uuid"
-	"github.com/prkagrawal/cosmos-bk2/graph/model"
 )
 
 func IdToUint(idStr string) (uint, error) {
@@ -74,41 +68,6 @@ func ParseNullableDateTimeString(dateTimeStr *string) (*time.Time, error) {
 	return &t, nil
 }
 
-func SaveUpload(upload *model.Upload, subDir string) (string, error) {
-	if upload == nil {
-		return "", nil // No upload provided
-	}
-
-	// Create a unique filename to prevent overwrites
-	ext := filepath.Ext(upload.Filename)
-	uniqueFilename := uuid.New().String() + ext
-
-	// Define the path to save. Ensure 'uploads' directory and 'subDir' exist.
-	uploadDirPath := filepath.Join("uploads", subDir)
-	if err := os.MkdirAll(uploadDirPath, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create upload directory: %w", err)
-	}
-	filePath := filepath.Join(uploadDirPath, uniqueFilename)
-
-	// Create the destination file
-	dst, err := os.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer dst.Close()
-
-	// Copy the uploaded file content to the destination file
-	if _, err := io.Copy(dst, upload.File); err != nil {
-		return "", fmt.Errorf("failed to copy uploaded file: %w", err)
-	}
-
-	// Return a URL or path. For local, it might be a relative path.
-	// For a web server, you'd return a URL like "/uploads/avatars/filename.jpg"
-	// This path needs to be configurable and accessible by your frontend/clients.
-	// Example: return "/" + filePath, // if "uploads" is served statically
-	return filePath, nil // Returning raw filepath for simplicity here
-}
-
 // func (r *userResolver) Applications(ctx context.Context, obj *model.User) ([]*model.Application, error) {
 // 	// Authorization: Generally, a user can see their own applications.
 // 	// If `obj` is not the currently authenticated user, consider if this data should be public.
@@ -0,0 +1,344 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+)
+
+// Limits shared by the input validators below.
+const (
+	MinPasswordLength = 8
+	MaxPasswordBytes  = 72 // bcrypt ignores anything past 72 bytes
+	MaxNameLength     = 100
+	MaxTitleLength    = 200
+	MaxTextLength     = 5000
+	MaxHoursPerEntry  = 24
+	MaxHoursPerWeek   = 168
+	MaxQuestions      = 20
+	MaxSearchLength   = 100
+)
+
+// SignupInput validates the signup mutation input.
+func SignupInput(input model.SignupInput) error {
+	v := New()
+	v.Required(input.Email, "input.email")
+	v.Email(input.Email, "input.email")
+	v.MinLength(input.Password, MinPasswordLength, "input.password")
+	v.Check(len(input.Password) <= MaxPasswordBytes, "input.password", fmt.Sprintf("must be at most %d bytes", MaxPasswordBytes))
+	v.Required(input.FirstName, "input.firstName")
+	v.MaxLength(input.FirstName, MaxNameLength, "input.firstName")
+	v.Required(input.LastName, "input.lastName")
+	v.MaxLength(input.LastName, MaxNameLength, "input.lastName")
+	// Platform admins are provisioned by other admins, never through self-service signup.
+	v.Check(input.Role == model.Volunteer || input.Role == model.NonprofitAdmin, "input.role", "must be VOLUNTEER or NONPROFIT_ADMIN")
+	return v.Err()
+}
+
+// ProfileInput validates the updateProfile mutation input. Only fields that are set are checked.
+func ProfileInput(input model.ProfileInput) error {
+	v := New()
+	if input.FirstName != nil {
+		v.Required(*input.FirstName, "input.firstName")
+		v.MaxLength(*input.FirstName, MaxNameLength, "input.firstName")
+	}
+	if input.LastName != nil {
+		v.Required(*input.LastName, "input.lastName")
+		v.MaxLength(*input.LastName, MaxNameLength, "input.lastName")
+	}
+	if input.Bio != nil {
+		v.MaxLength(*input.Bio, MaxTextLength, "input.bio")
+	}
+	// Empty strings are allowed so users can clear their links.
+	if input.LinkedIn != nil && *input.LinkedIn != "" {
+		v.URL(*input.LinkedIn, "input.linkedIn", "linkedin.com")
+	}
+	if input.Portfolio != nil && *input.Portfolio != "" {
+		v.URL(*input.Portfolio, "input.portfolio")
+	}
+	return v.Err()
+}
+
+// AvailabilityInput validates the setAvailability mutation input.
+func AvailabilityInput(input model.AvailabilityInput) error {
+	v := New()
+	v.Range(float64(input.HoursPerWeek), 0, MaxHoursPerWeek, "input.hoursPerWeek")
+	v.Timezone(input.Timezone, "input.timezone")
+	return v.Err()
+}
+
+// NonprofitInput validates the createNonprofit and updateNonprofit mutation input.
+func NonprofitInput(input model.NonprofitInput) error {
+	v := New()
+	v.Required(input.Name, "input.name")
+	v.MaxLength(input.Name, MaxTitleLength, "input.name")
+	v.Required(input.Description, "input.description")
+	v.MaxLength(input.Description, MaxTextLength, "input.description")
+	v.URL(input.Website, "input.website")
+	v.EIN(input.Ein, "input.ein")
+	for _, cause := range input.Causes {
+		v.Required(cause, "input.causes")
+	}
+	if input.Location != nil {
+		v.MaxLength(input.Location.City, MaxNameLength, "input.location.city")
+		v.MaxLength(input.Location.State, MaxNameLength, "input.location.state")
+		v.MaxLength(input.Location.Country, MaxNameLength, "input.location.country")
+		if !input.Location.Remote {
+			v.Required(input.Location.Country, "input.location.country")
+		}
+	}
+	return v.Err()
+}
+
+// ProjectInput validates the createProject and updateProject mutation input.
+func ProjectInput(input model.ProjectInput) error {
+	v := New()
+	v.Required(input.NonprofitID, "input.nonprofitId")
+	v.Required(input.Title, "input.title")
+	v.MaxLength(input.Title, MaxTitleLength, "input.title")
+	v.Required(input.Description, "input.description")
+	v.MaxLength(input.Description, MaxTextLength, "input.description")
+	for _, skill := range input.SkillsNeeded {
+		v.Required(skill, "input.skillsNeeded")
+	}
+
+	var start, end *time.Time
+	if input.StartDate != nil && *input.StartDate != "" {
+		start = v.DateTime(*input.StartDate, "input.startDate")
+	}
+	if input.EndDate != nil && *input.EndDate != "" {
+		end = v.DateTime(*input.EndDate, "input.endDate")
+	}
+	if start != nil && end != nil {
+		v.Check(!end.Before(*start), "input.endDate", "must not be before startDate")
+	}
+
+	if input.Timezone != nil {
+		v.Timezone(*input.Timezone, "input.timezone")
+	}
+
+	needed := 0
+	if input.VolunteersNeeded != nil {
+		needed = int(*input.VolunteersNeeded)
+		v.Check(needed >= 0, "input.volunteersNeeded", "must not be negative")
+	}
+	skills := make(map[string]bool, len(input.SkillsNeeded))
+	for _, skill := range input.SkillsNeeded {
+		skills[skill] = true
+	}
+	titles := make(map[string]bool, len(input.RoleSlots))
+	total := 0
+	for i, slot := range input.RoleSlots {
+		path := fmt.Sprintf("input.roleSlots[%d]", i)
+		v.Required(slot.Title, path+".title")
+		v.MaxLength(slot.Title, MaxNameLength, path+".title")
+		v.Check(!titles[slot.Title], path+".title", "must be unique within the project")
+		titles[slot.Title] = true
+		v.Check(slot.Capacity >= 1, path+".capacity", "must be at least 1")
+		if slot.Skill != nil {
+			v.Check(skills[*slot.Skill], path+".skill", "must be one of skillsNeeded")
+		}
+		total += int(slot.Capacity)
+	}
+	if len(input.RoleSlots) > 0 {
+		v.Check(needed > 0, "input.volunteersNeeded", "must be set when role slots are given")
+		v.Check(total <= needed, "input.roleSlots", "total capacity must not exceed volunteersNeeded")
+	}
+
+	v.Check(len(input.Questions) <= MaxQuestions, "input.questions", fmt.Sprintf("must have at most %d questions", MaxQuestions))
+	prompts := make(map[string]bool, len(input.Questions))
+	for i, question := range input.Questions {
+		path := fmt.Sprintf("input.questions[%d]", i)
+		v.Required(question.Prompt, path+".prompt")
+		v.MaxLength(question.Prompt, MaxTitleLength, path+".prompt")
+		v.Check(!prompts[question.Prompt], path+".prompt", "must be unique within the project")
+		prompts[question.Prompt] = true
+
+		switch question.Type {
+		case model.QuestionSingleChoice, model.QuestionMultipleChoice:
+			v.Check(len(question.Options) >= 2, path+".options", "must list at least 2 options")
+			options := make(map[string]bool, len(question.Options))
+			for _, option := range question.Options {
+				v.Required(option, path+".options")
+				v.MaxLength(option, MaxNameLength, path+".options")
+				v.Check(!options[option], path+".options", "must not contain duplicates")
+				options[option] = true
+			}
+		default:
+			v.Check(len(question.Options) == 0, path+".options", "are only allowed for choice questions")
+		}
+	}
+	return v.Err()
+}
+
+// Answers validates applyToProject answers against the project's questions: every answer must
+// be for one of the questions and fit its type, and every required question must be answered.
+func Answers(questions []model.ApplicationQuestion, answers []*model.AnswerInput) error {
+	v := New()
+	byID := make(map[string]model.ApplicationQuestion, len(questions))
+	for _, question := range questions {
+		byID[fmt.Sprintf("%d", question.ID)] = question
+	}
+
+	answered := make(map[string]bool, len(answers))
+	for i, answer := range answers {
+		path := fmt.Sprintf("answers[%d]", i)
+		question, ok := byID[answer.QuestionID]
+		if !ok {
+			v.Add(path+".questionId", "is not a question of this project")
+			continue
+		}
+		if answered[answer.QuestionID] {
+			v.Add(path+".questionId", "is answered more than once")
+			continue
+		}
+		answered[answer.QuestionID] = true
+
+		path += ".values"
+		if question.Type != model.QuestionMultipleChoice && len(answer.Values) > 1 {
+			v.Add(path, "must have a single value")
+			continue
+		}
+		for _, value := range answer.Values {
+			switch question.Type {
+			case model.QuestionText:
+				v.MaxLength(value, MaxTextLength, path)
+			case model.QuestionURL:
+				v.URL(value, path)
+			case model.QuestionYesNo:
+				v.Check(value == "true" || value == "false", path, `must be "true" or "false"`)
+			case model.QuestionSingleChoice, model.QuestionMultipleChoice:
+				v.Check(contains(question.Options, value), path, "must be one of the question's options")
+			}
+		}
+	}
+
+	for _, question := range questions {
+		id := fmt.Sprintf("%d", question.ID)
+		if question.Required && !answered[id] {
+			v.Add("answers", fmt.Sprintf("question %s is required", id))
+		}
+	}
+	for i, answer := range answers {
+		if question, ok := byID[answer.QuestionID]; ok && question.Required {
+			empty := len(answer.Values) == 0 || (len(answer.Values) == 1 && answer.Values[0] == "")
+			v.Check(!empty, fmt.Sprintf("answers[%d].values", i), "is required")
+		}
+	}
+	return v.Err()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftInput validates the createShift mutation input and returns the shift's start and end in
+// loc, the project's timezone. Shifts end on the day they start.
+func ShiftInput(input model.ShiftInput, loc *time.Location) (start, end time.Time, err error) {
+	v := New()
+	v.Check(input.Capacity >= 1, "input.capacity", "must be at least 1")
+	if input.Location != nil {
+		v.MaxLength(*input.Location, MaxTitleLength, "input.location")
+	}
+
+	start, startErr := time.ParseInLocation("2006-01-02 15:04", input.Date+" "+input.StartTime, loc)
+	end, endErr := time.ParseInLocation("2006-01-02 15:04", input.Date+" "+input.EndTime, loc)
+	if _, err := time.Parse("2006-01-02", input.Date); err != nil {
+		v.Add("input.date", "must be a date such as 2025-06-14")
+	} else {
+		v.Check(startErr == nil, "input.startTime", "must be a time such as 09:00")
+		v.Check(endErr == nil, "input.endTime", "must be a time such as 12:00")
+		if startErr == nil && endErr == nil {
+			v.Check(end.After(start), "input.endTime", "must be after startTime")
+			v.Check(start.After(time.Now()), "input.date", "must be in the future")
+		}
+	}
+	return start, end, v.Err()
+}
+
+// ReviewInput validates the reviewEngagement mutation input for a review in the given direction.
+func ReviewInput(input model.ReviewInput, direction model.ReviewDirection) error {
+	v := New()
+	v.Range(float64(input.Rating), 1, 5, "input.rating")
+	if input.ProjectRating != nil {
+		v.Check(direction == model.ReviewOfNonprofit, "input.projectRating", "can only be given by the volunteer")
+		v.Range(float64(*input.ProjectRating), 1, 5, "input.projectRating")
+	}
+	if input.Comment != nil {
+		v.MaxLength(*input.Comment, MaxTextLength, "input.comment")
+	}
+	if len(input.EndorsedSkills) > 0 {
+		v.Check(direction == model.ReviewOfVolunteer, "input.endorsedSkills", "can only be given by the nonprofit")
+	}
+	return v.Err()
+}
+
+// LogHours validates the arguments of the logHours mutation.
+func LogHours(hours float64, date string, description *string) error {
+	v := New()
+	v.Check(hours > 0, "hours", "must be greater than 0")
+	v.Check(hours <= MaxHoursPerEntry, "hours", "must be at most 24 for a single day")
+	if d := v.DateTime(date, "date"); d != nil {
+		v.Check(!d.After(time.Now()), "date", "must not be in the future")
+	}
+	if description != nil {
+		v.MaxLength(*description, MaxTextLength, "description")
+	}
+	return v.Err()
+}
+
+// ApplicationMessage validates the optional message sent with applyToProject.
+func ApplicationMessage(message *string) error {
+	v := New()
+	if message != nil {
+		v.MaxLength(*message, MaxTextLength, "message")
+	}
+	return v.Err()
+}
+
+// ModerationComment validates the comment a platform admin sends back with requestProjectChanges.
+func ModerationComment(comment string) error {
+	v := New()
+	v.Required(comment, "comment")
+	v.MaxLength(comment, MaxTextLength, "comment")
+	return v.Err()
+}
+
+// SendMessageInput validates the sendMessage mutation input. Exactly one of conversationId,
+// applicationId and engagementId picks the thread.
+func SendMessageInput(input model.SendMessageInput) error {
+	v := New()
+	targets := 0
+	for _, id := range []*string{input.ConversationID, input.ApplicationID, input.EngagementID} {
+		if id != nil {
+			targets++
+		}
+	}
+	v.Check(targets == 1, "input", "exactly one of conversationId, applicationId and engagementId is required")
+	v.Required(input.Body, "input.body")
+	v.MaxLength(input.Body, MaxTextLength, "input.body")
+	return v.Err()
+}
+
+// WebhookSubscriptionInput validates a webhook subscription. Payloads carry volunteers' personal
+// data, so receivers must use https. PING is sent on request only, so it cannot be subscribed to.
+func WebhookSubscriptionInput(input model.WebhookSubscriptionInput) error {
+	v := New()
+	v.URL(input.URL, "input.url")
+	if u, err := url.Parse(input.URL); err == nil && u.Scheme == "http" {
+		v.Add("input.url", "must use https")
+	}
+	v.MaxLength(input.URL, 2048, "input.url")
+	v.Check(len(input.Events) > 0, "input.events", "must list at least one event")
+	for _, event := range input.Events {
+		v.Check(event != model.WebhookPing, "input.events", "PING is sent by pingWebhook and cannot be subscribed to")
+	}
+	return v.Err()
+}
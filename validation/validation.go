@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// CodeValidation is the value of the "code" extension on every error returned by this package.
+// Clients can switch on it to render field-level messages next to the offending inputs.
+const CodeValidation = "VALIDATION"
+
+// FieldError describes a single invalid input path, e.g. "input.endDate".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Validator collects field errors so a single response can list every invalid path at once,
+// instead of failing on the first problem and making the client round-trip for each one.
+type Validator struct {
+	errors []FieldError
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check records message against path when ok is false.
+func (v *Validator) Check(ok bool, path, message string) {
+	if !ok {
+		v.Add(path, message)
+	}
+}
+
+func (v *Validator) Add(path, message string) {
+	v.errors = append(v.errors, FieldError{Path: path, Message: message})
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// Err returns nil when no checks failed. Otherwise it returns a *gqlerror.Error carrying
+// {"code": "VALIDATION", "fields": [...]} in its extensions; gqlgen attaches the resolver path.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+
+	fields := make([]map[string]interface{}, 0, len(v.errors))
+	paths := make([]string, 0, len(v.errors))
+	for _, fe := range v.errors {
+		fields = append(fields, map[string]interface{}{"path": fe.Path, "message": fe.Message})
+		paths = append(paths, fe.Path)
+	}
+
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("validation failed: %s", strings.Join(paths, ", ")),
+		Extensions: map[string]interface{}{
+			"code":   CodeValidation,
+			"fields": fields,
+		},
+	}
+}
+
+// Field wraps a single error as a VALIDATION error on path, for checks that happen outside
+// this package (e.g. image decoding).
+func Field(path string, err error) error {
+	v := New()
+	v.Add(path, err.Error())
+	return v.Err()
+}
+
+// --- Rule helpers ---
+
+var (
+	emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	// EINs are issued by the IRS as two digits, a dash and seven digits (the dash is optional on input).
+	einPattern = regexp.MustCompile(`^\d{2}-?\d{7}$`)
+)
+
+func (v *Validator) Required(value, path string) {
+	v.Check(strings.TrimSpace(value) != "", path, "is required")
+}
+
+func (v *Validator) MinLength(value string, min int, path string) {
+	v.Check(utf8.RuneCountInString(value) >= min, path, fmt.Sprintf("must be at least %d characters", min))
+}
+
+func (v *Validator) MaxLength(value string, max int, path string) {
+	v.Check(utf8.RuneCountInString(value) <= max, path, fmt.Sprintf("must be at most %d characters", max))
+}
+
+func (v *Validator) Email(value, path string) {
+	v.Check(len(value) <= 254 && emailPattern.MatchString(value), path, "must be a valid email address")
+}
+
+func (v *Validator) EIN(value, path string) {
+	v.Check(einPattern.MatchString(value), path, "must be a valid EIN (e.g. 12-3456789)")
+}
+
+// URL checks value is an absolute http(s) URL. When hosts are given, the URL's host must be
+// one of them or a subdomain of one of them.
+func (v *Validator) URL(value, path string, hosts ...string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.Add(path, "must be a valid http(s) URL")
+		return
+	}
+	if len(hosts) == 0 {
+		return
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return
+		}
+	}
+	v.Add(path, fmt.Sprintf("must be a %s URL", strings.Join(hosts, " or ")))
+}
+
+func (v *Validator) Range(value, min, max float64, path string) {
+	v.Check(value >= min && value <= max, path, fmt.Sprintf("must be between %g and %g", min, max))
+}
+
+// DateTime parses an RFC3339 value, recording an error and returning nil when it is malformed.
+func (v *Validator) DateTime(value, path string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.Add(path, "must be an RFC3339 date-time")
+		return nil
+	}
+	return &t
+}
+
+func (v *Validator) Timezone(value, path string) {
+	if _, err := time.LoadLocation(value); err != nil || value == "" {
+		v.Add(path, "must be a valid IANA timezone (e.g. America/New_York)")
+	}
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"gorm.io/gorm"
+)
+
+// The data of each event. Volunteers are included so receivers can match them to their contacts.
+
+type Volunteer struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+type Project struct {
+	ID        uint                `json:"id"`
+	Title     string              `json:"title"`
+	Status    model.ProjectStatus `json:"status"`
+	StartDate *time.Time          `json:"startDate,omitempty"`
+	EndDate   *time.Time          `json:"endDate,omitempty"`
+}
+
+type Application struct {
+	ID        uint                    `json:"id"`
+	Status    model.ApplicationStatus `json:"status"`
+	Message   *string                 `json:"message,omitempty"`
+	AppliedAt time.Time               `json:"appliedAt"`
+	DecidedAt *time.Time              `json:"decidedAt,omitempty"`
+	Project   Project                 `json:"project"`
+	Volunteer *Volunteer              `json:"volunteer,omitempty"`
+}
+
+type Engagement struct {
+	ID        uint                   `json:"id"`
+	Status    model.EngagementStatus `json:"status"`
+	StartDate time.Time              `json:"startDate"`
+	EndDate   *time.Time             `json:"endDate,omitempty"`
+	Project   Project                `json:"project"`
+	Volunteer *Volunteer             `json:"volunteer,omitempty"`
+}
+
+type Hours struct {
+	ID           uint      `json:"id"`
+	EngagementID uint      `json:"engagementId"`
+	Date         time.Time `json:"date"`
+	Hours        float64   `json:"hours"`
+	Description  *string   `json:"description,omitempty"`
+	// Approved is null until the entry is reviewed
+	Approved  *bool      `json:"approved"`
+	Project   Project    `json:"project"`
+	Volunteer *Volunteer `json:"volunteer,omitempty"`
+}
+
+func projectData(project *model.Project) Project {
+	return Project{
+		ID:        project.ID,
+		Title:     project.Title,
+		Status:    project.Status,
+		StartDate: project.StartDate,
+		EndDate:   project.EndDate,
+	}
+}
+
+// volunteer loads a volunteer for an event. A lookup failure leaves the volunteer out rather
+// than dropping the event.
+func (d *Dispatcher) volunteer(tx *gorm.DB, id uint) *Volunteer {
+	var user model.User
+	if err := tx.First(&user, id).Error; err != nil {
+		d.Logger.Warn().Err(err).Uint("user_id", id).Msg("Loading webhook volunteer failed")
+		return nil
+	}
+	return &Volunteer{ID: user.ID, FirstName: user.FirstName, LastName: user.LastName, Email: user.Email}
+}
@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"gorm.io/gorm"
+)
+
+// ConsumerName is the name HandleEvent consumes the outbox under.
+const ConsumerName = "webhooks"
+
+// HandleEvent is the outbox handler that queues webhook deliveries for a domain event. Events
+// without a webhook counterpart are ignored, as are events about anything since deleted.
+func (d *Dispatcher) HandleEvent(tx *gorm.DB, event *outbox.Event) error {
+	err := d.handleEvent(tx, event)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (d *Dispatcher) handleEvent(tx *gorm.DB, event *outbox.Event) error {
+	switch event.Type {
+	case outbox.ApplicationSubmitted, outbox.ApplicationStatusChanged:
+		var payload outbox.ApplicationPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var application model.Application
+		if err := tx.Preload("Project").First(&application, payload.ApplicationID).Error; err != nil {
+			return fmt.Errorf("failed to fetch application: %w", err)
+		}
+		kind := model.WebhookApplicationStatusChanged
+		if event.Type == outbox.ApplicationSubmitted {
+			kind = model.WebhookApplicationSubmitted
+		}
+		return publish(tx, application.Project.NonprofitID, kind, func() any {
+			return Application{
+				ID:        application.ID,
+				Status:    payload.To,
+				Message:   application.Message,
+				AppliedAt: application.AppliedAt,
+				DecidedAt: application.DecidedAt,
+				Project:   projectData(&application.Project),
+				Volunteer: d.volunteer(tx, application.VolunteerID),
+			}
+		})
+
+	case outbox.EngagementStarted, outbox.EngagementCompleted, outbox.EngagementCancelled:
+		var payload outbox.EngagementPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var engagement model.Engagement
+		if err := tx.Preload("Project").First(&engagement, payload.EngagementID).Error; err != nil {
+			return fmt.Errorf("failed to fetch engagement: %w", err)
+		}
+		kind := map[string]model.WebhookEvent{
+			outbox.EngagementStarted:   model.WebhookEngagementStarted,
+			outbox.EngagementCompleted: model.WebhookEngagementCompleted,
+			outbox.EngagementCancelled: model.WebhookEngagementCancelled,
+		}[event.Type]
+		return publish(tx, engagement.Project.NonprofitID, kind, func() any {
+			return Engagement{
+				ID:        engagement.ID,
+				Status:    engagement.Status,
+				StartDate: engagement.StartDate,
+				EndDate:   engagement.EndDate,
+				Project:   projectData(&engagement.Project),
+				Volunteer: d.volunteer(tx, engagement.VolunteerID),
+			}
+		})
+
+	case outbox.HoursLogged, outbox.HoursReviewed:
+		var payload outbox.HoursPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var entry model.HoursLogged
+		if err := tx.Preload("Engagement.Project").First(&entry, payload.HoursID).Error; err != nil {
+			return fmt.Errorf("failed to fetch hours: %w", err)
+		}
+		kind := model.WebhookHoursLogged
+		if event.Type == outbox.HoursReviewed {
+			kind = model.WebhookHoursReviewed
+		}
+		project := &entry.Engagement.Project
+		return publish(tx, project.NonprofitID, kind, func() any {
+			return Hours{
+				ID:           entry.ID,
+				EngagementID: entry.EngagementID,
+				Date:         entry.Date,
+				Hours:        entry.Hours,
+				Description:  entry.Description,
+				Approved:     entry.Approved,
+				Project:      projectData(project),
+				Volunteer:    d.volunteer(tx, entry.Engagement.VolunteerID),
+			}
+		})
+
+	case outbox.ProjectStatusChanged:
+		var payload outbox.ProjectPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		var project model.Project
+		if err := tx.First(&project, payload.ProjectID).Error; err != nil {
+			return fmt.Errorf("failed to fetch project: %w", err)
+		}
+		project.Status = payload.To
+		return publish(tx, project.NonprofitID, model.WebhookProjectStatusChanged, func() any {
+			return projectData(&project)
+		})
+	}
+	return nil
+}
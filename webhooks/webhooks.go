@@ -0,0 +1,360 @@
+// Package webhooks pushes a nonprofit's events to the URLs it subscribed, e.g. to keep a CRM in
+// sync. Every event becomes a WebhookDelivery per matching subscription, sent by the job workers
+// and retried with backoff until the receiver answers with a 2xx status.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// KindDeliver is the job that sends one WebhookDelivery.
+const KindDeliver = "webhooks.deliver"
+
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body, keyed with the
+	// subscription's secret.
+	SignatureHeader = "X-Signature-256"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// MaxAttempts is how often a delivery is tried before it is marked FAILED, about two hours with
+// the job queue's backoff.
+const MaxAttempts = 8
+
+// maxResponseBody is how much of a receiver's response is read, so the connection can be reused.
+// None of it is kept: only the status code goes into the delivery log.
+const maxResponseBody = 1024
+
+// ErrForbiddenAddress is returned for receivers on loopback, link-local, private or unspecified
+// addresses. Subscriptions are set up by nonprofits, so a webhook must not be a way to make the
+// platform call into its own network.
+var ErrForbiddenAddress = errors.New("webhook receiver address is not allowed")
+
+// Envelope is the JSON body of every webhook request. ID identifies the event and stays the same
+// when a delivery is retried or redelivered, so receivers can deduplicate on it.
+type Envelope struct {
+	ID          string             `json:"id"`
+	Event       model.WebhookEvent `json:"event"`
+	NonprofitID uint               `json:"nonprofitId"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Data        any                `json:"data"`
+}
+
+// Events lists the events a subscription can receive. PING is only sent by Ping.
+var Events = []model.WebhookEvent{
+	model.WebhookApplicationSubmitted,
+	model.WebhookApplicationStatusChanged,
+	model.WebhookEngagementStarted,
+	model.WebhookEngagementCompleted,
+	model.WebhookEngagementCancelled,
+	model.WebhookHoursLogged,
+	model.WebhookHoursReviewed,
+	model.WebhookProjectStatusChanged,
+}
+
+// Dispatcher queues and sends webhook deliveries.
+type Dispatcher struct {
+	DB     *gorm.DB
+	Client *http.Client
+	Logger *zerolog.Logger
+}
+
+func New(db *gorm.DB, logger *zerolog.Logger) *Dispatcher {
+	// The address is checked once resolved, at every connection, so a receiver's DNS cannot
+	// point it inwards after the subscription was validated. There is no proxy, as the dialer
+	// would only get to check the proxy's address
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}
+	return &Dispatcher{
+		DB: db,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// A redirect is reported as the receiver's answer rather than followed
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		Logger: logger,
+	}
+}
+
+// forbiddenPrefixes are the address ranges receivers may not be on: those that are not globally
+// reachable in the IANA special-purpose address registries, plus multicast and the deprecated
+// ranges that tunnel to other addresses.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, including cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and limited broadcast
+	netip.MustParsePrefix("::/128"),          // unspecified
+	netip.MustParsePrefix("::1/128"),         // loopback
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// nat64 is the well-known NAT64 prefix, whose addresses reach the IPv4 address in their last
+// four bytes.
+var nat64 = netip.MustParsePrefix("64:ff9b::/96")
+
+// publicOnly is the dialer's Control hook, refusing to connect to anything but public addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !public(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// public reports whether ip is outside forbiddenPrefixes, checking IPv4 addresses embedded in
+// IPv4-mapped and NAT64 addresses as themselves.
+func public(ip netip.Addr) bool {
+	ip = ip.WithZone("").Unmap()
+	if nat64.Contains(ip) {
+		b := ip.As16()
+		ip = netip.AddrFrom4([4]byte(b[12:]))
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Register makes w send webhook deliveries.
+func (d *Dispatcher) Register(w *jobs.Worker) {
+	w.Register(KindDeliver, d.deliver)
+}
+
+// NewSecret generates a signing secret for a subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// publish queues event for every active subscription of the nonprofit that wants it, building
+// the data only when some subscription does.
+func publish(tx *gorm.DB, nonprofitID uint, event model.WebhookEvent, data func() any) error {
+	var subscriptions []model.WebhookSubscription
+	if err := tx.Where("nonprofit_id = ? AND active", nonprofitID).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to fetch webhook subscriptions: %w", err)
+	}
+	subscriptions = slices.DeleteFunc(subscriptions, func(s model.WebhookSubscription) bool {
+		return !slices.Contains(s.Events, string(event))
+	})
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := encode(nonprofitID, event, data())
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		if _, err := queue(tx, subscription.ID, event, payload, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping queues a PING delivery to subscription, whatever its events, to check the receiver. Run it
+// inside a transaction.
+func Ping(tx *gorm.DB, subscription *model.WebhookSubscription) (*model.WebhookDelivery, error) {
+	payload, err := encode(subscription.NonprofitID, model.WebhookPing, map[string]any{"subscriptionId": subscription.ID})
+	if err != nil {
+		return nil, err
+	}
+	return queue(tx, subscription.ID, model.WebhookPing, payload, nil)
+}
+
+// Redeliver queues the payload of delivery again as a new delivery. Run it inside a transaction.
+func Redeliver(tx *gorm.DB, delivery *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	return queue(tx, delivery.SubscriptionID, delivery.Event, delivery.Payload, &delivery.ID)
+}
+
+func encode(nonprofitID uint, event model.WebhookEvent, data any) (string, error) {
+	body, err := json.Marshal(Envelope{
+		ID:          uuid.New().String(),
+		Event:       event,
+		NonprofitID: nonprofitID,
+		CreatedAt:   time.Now().UTC(),
+		Data:        data,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return string(body), nil
+}
+
+type deliverPayload struct {
+	DeliveryID uint `json:"deliveryId"`
+}
+
+// queue creates a delivery and the job that sends it.
+func queue(tx *gorm.DB, subscriptionID uint, event model.WebhookEvent, payload string, redeliveryOf *uint) (*model.WebhookDelivery, error) {
+	delivery := model.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Payload:        payload,
+		Status:         model.DeliveryPending,
+		RedeliveryOfID: redeliveryOf,
+	}
+	if err := tx.Create(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	if _, err := jobs.Enqueue(tx, KindDeliver, deliverPayload{DeliveryID: delivery.ID}, jobs.MaxAttempts(MaxAttempts)); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// deliver sends a delivery and records the outcome. An error makes the job queue retry it; the
+// delivery is marked FAILED once the job is out of attempts.
+func (d *Dispatcher) deliver(ctx context.Context, job *jobs.Job) error {
+	var payload deliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	var delivery model.WebhookDelivery
+	if err := d.DB.First(&delivery, payload.DeliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch webhook delivery: %w", err)
+	}
+	if delivery.Status != model.DeliveryPending {
+		return nil
+	}
+
+	var subscription model.WebhookSubscription
+	err := d.DB.First(&subscription, delivery.SubscriptionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The subscription was deleted while the delivery was queued
+		return d.record(&delivery, nil, errors.New("subscription deleted"), true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook subscription: %w", err)
+	}
+	if !subscription.Active && delivery.Event != model.WebhookPing {
+		return d.record(&delivery, nil, errors.New("subscription disabled"), true)
+	}
+
+	status, sendErr := d.send(ctx, &subscription, &delivery)
+	last := job.Attempts >= job.MaxAttempts
+	if err := d.record(&delivery, status, sendErr, last); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send posts delivery to the subscription's URL and returns the receiver's status code.
+func (d *Dispatcher) send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (*int32, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	// Subscriptions made before https was required are not sent to
+	if req.URL.Scheme != "https" {
+		return nil, errors.New("webhook URL must use https")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cosmos-webhooks/1")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	status := int32(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &status, fmt.Errorf("webhook receiver returned %s", resp.Status)
+	}
+	return &status, nil
+}
+
+// record stores the outcome of an attempt. A failed attempt leaves the delivery PENDING unless it
+// was the last.
+func (d *Dispatcher) record(delivery *model.WebhookDelivery, status *int32, sendErr error, last bool) error {
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": status,
+		"error":           nil,
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = model.DeliverySucceeded
+		updates["delivered_at"] = time.Now()
+	case last:
+		updates["status"] = model.DeliveryFailed
+		updates["error"] = sendErr.Error()
+	default:
+		updates["error"] = sendErr.Error()
+	}
+	if err := d.DB.Model(delivery).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/prkagrawal/cosmos-bk2/graph/model"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/rs/zerolog"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// receiver is a webhook endpoint that answers with the next of its statuses, repeating the last
+// one, and keeps every request it got.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	rec := &receiver{statuses: statuses}
+	srv := httptest.NewTLSServer(rec)
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (rec *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests = append(rec.requests, receivedRequest{header: r.Header.Clone(), body: body})
+	status := rec.statuses[0]
+	if len(rec.statuses) > 1 {
+		rec.statuses = rec.statuses[1:]
+	}
+	if status == http.StatusFound {
+		w.Header().Set("Location", "https://example.org/")
+	}
+	w.WriteHeader(status)
+	w.Write([]byte("receiver says hello"))
+}
+
+func (rec *receiver) received() []receivedRequest {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]receivedRequest(nil), rec.requests...)
+}
+
+// testDispatcher returns a dispatcher whose client trusts srv, which listens on loopback and so
+// would be refused by the dispatcher's own client.
+func testDispatcher(db *gorm.DB, srv *httptest.Server) *Dispatcher {
+	logger := zerolog.Nop()
+	d := New(db, &logger)
+	d.Client = srv.Client()
+	d.Client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return d
+}
+
+func TestPublicOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:4700:4700::1111]:443", true},
+		{"127.0.0.1:443", false},
+		{"[::1]:443", false},
+		{"10.1.2.3:443", false},
+		{"172.16.0.1:443", false},
+		{"192.168.1.10:8080", false},
+		{"[fd00::1]:443", false},
+		{"169.254.169.254:80", false}, // cloud metadata
+		{"[fe80::1]:443", false},
+		{"0.0.0.0:443", false},
+		{"[::]:443", false},
+		{"[::ffff:127.0.0.1]:443", false},
+		{"224.0.0.1:443", false},
+		{"100.64.0.1:443", false}, // carrier-grade NAT
+		{"100.127.255.254:443", false},
+		{"192.0.0.8:443", false},  // IETF protocol assignments
+		{"198.18.0.1:443", false}, // benchmarking
+		{"198.19.255.255:443", false},
+		{"192.0.2.1:443", false}, // documentation
+		{"203.0.113.5:443", false},
+		{"0.1.2.3:443", false},
+		{"240.0.0.1:443", false},
+		{"255.255.255.255:443", false},
+		{"100.128.0.1:443", true},          // just past carrier-grade NAT
+		{"198.20.0.1:443", true},           // just past benchmarking
+		{"[64:ff9b::a00:1]:443", false},    // NAT64 to 10.0.0.1
+		{"[64:ff9b::5db8:d822]:443", true}, // NAT64 to 93.184.216.34
+		{"[2001:db8::1]:443", false},
+		{"[2002:a00:1::1]:443", false}, // 6to4
+		{"[fec0::1]:443", false},
+		{"[fe80::1%eth0]:443", false},
+		{"[ff02::1]:443", false},
+		{"not-an-ip:443", false},
+	}
+	for _, tt := range tests {
+		err := publicOnly("tcp", tt.address, nil)
+		if tt.allowed && err != nil {
+			t.Errorf("publicOnly(%s) = %v, want allowed", tt.address, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("publicOnly(%s) = %v, want ErrForbiddenAddress", tt.address, err)
+		}
+	}
+}
+
+func TestSendRefusesInternalReceivers(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusOK)
+	logger := zerolog.Nop()
+	d := New(nil, &logger) // the real client
+
+	subscription := &model.WebhookSubscription{URL: srv.URL, Secret: "whsec_test"}
+	_, err := d.send(context.Background(), subscription, &model.WebhookDelivery{Payload: `{}`})
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("send to %s: got %v, want ErrForbiddenAddress", srv.URL, err)
+	}
+	if n := len(rec.received()); n != 0 {
+		t.Fatalf("receiver got %d requests, want none", n)
+	}
+}
+
+func TestSendRequiresHTTPS(t *testing.T) {
+	rec := &receiver{statuses: []int{http.StatusOK}}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	d := testDispatcher(nil, srv)
+
+	subscription := &model.WebhookSubscription{URL: srv.URL, Secret: "whsec_test"}
+	if _, err := d.send(context.Background(), subscription, &model.WebhookDelivery{Payload: `{}`}); err == nil {
+		t.Fatalf("send to %s succeeded, want an error", srv.URL)
+	}
+	if n := len(rec.received()); n != 0 {
+		t.Fatalf("receiver got %d requests, want none", n)
+	}
+}
+
+func TestSendSignsAndReportsStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"accepted", http.StatusNoContent, false},
+		{"server error", http.StatusServiceUnavailable, true},
+		{"redirect is not followed", http.StatusFound, true},
+		{"client error", http.StatusGone, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newReceiver(t, tt.status)
+			d := testDispatcher(nil, srv)
+			subscription := &model.WebhookSubscription{URL: srv.URL, Secret: "whsec_test"}
+			delivery := &model.WebhookDelivery{Model: gorm.Model{ID: 42}, Event: model.WebhookHoursLogged, Payload: `{"id":"abc"}`}
+
+			status, err := d.send(context.Background(), subscription, delivery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status == nil || int(*status) != tt.status {
+				t.Fatalf("send: status = %v, want %d", status, tt.status)
+			}
+
+			got := rec.received()
+			if len(got) != 1 {
+				t.Fatalf("receiver got %d requests, want 1", len(got))
+			}
+			req := got[0]
+			if string(req.body) != delivery.Payload {
+				t.Errorf("body = %q, want %q", req.body, delivery.Payload)
+			}
+			if sig := req.header.Get(SignatureHeader); sig != Sign("whsec_test", req.body) {
+				t.Errorf("%s = %q, want %q", SignatureHeader, sig, Sign("whsec_test", req.body))
+			}
+			if event := req.header.Get(EventHeader); event != string(model.WebhookHoursLogged) {
+				t.Errorf("%s = %q", EventHeader, event)
+			}
+			if id := req.header.Get(DeliveryHeader); id != "42" {
+				t.Errorf("%s = %q, want 42", DeliveryHeader, id)
+			}
+		})
+	}
+}
+
+// testDB connects to the Postgres database in TEST_DATABASE_URL, skipping the test without one.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.WebhookSubscription{}, &model.WebhookDelivery{}, &jobs.Job{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// ping queues a ping to subscription the way the pingWebhook mutation does.
+func ping(t *testing.T, db *gorm.DB, subscription *model.WebhookSubscription) *model.WebhookDelivery {
+	t.Helper()
+	var delivery *model.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		delivery, err = Ping(tx, subscription)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	return delivery
+}
+
+// attempt runs the delivery job the way the job queue's attempt'th try would.
+func attempt(t *testing.T, d *Dispatcher, delivery *model.WebhookDelivery, n int) error {
+	t.Helper()
+	job := &jobs.Job{
+		Kind:        KindDeliver,
+		Payload:     fmt.Sprintf(`{"deliveryId":%d}`, delivery.ID),
+		Attempts:    n,
+		MaxAttempts: MaxAttempts,
+	}
+	err := d.deliver(context.Background(), job)
+	if err := d.DB.First(delivery, delivery.ID).Error; err != nil {
+		t.Fatalf("failed to reload delivery: %v", err)
+	}
+	return err
+}
+
+func TestDeliveryEndToEnd(t *testing.T) {
+	db := testDB(t)
+	rec, srv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	d := testDispatcher(db, srv)
+
+	subscription := model.WebhookSubscription{URL: srv.URL, Secret: "whsec_test", Events: model.Strings{string(model.WebhookHoursLogged)}, Active: true}
+	if err := db.Create(&subscription).Error; err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("subscription_id = ?", subscription.ID).Delete(&model.WebhookDelivery{})
+		db.Unscoped().Delete(&subscription)
+	})
+
+	// Ping is sent and retried after each 5xx until the receiver accepts it
+	delivery := ping(t, db, &subscription)
+	for n, wantStatus := range []int{http.StatusInternalServerError, http.StatusBadGateway} {
+		if err := attempt(t, d, delivery, n+1); err == nil {
+			t.Fatalf("attempt %d: want an error so the job is retried", n+1)
+		}
+		if delivery.Status != model.DeliveryPending || delivery.ResponseStatus == nil || int(*delivery.ResponseStatus) != wantStatus {
+			t.Fatalf("attempt %d: delivery %s with status %v, want PENDING with %d", n+1, delivery.Status, delivery.ResponseStatus, wantStatus)
+		}
+	}
+	if err := attempt(t, d, delivery, 3); err != nil {
+		t.Fatalf("attempt 3: %v", err)
+	}
+	if delivery.Status != model.DeliverySucceeded || delivery.Attempts != 3 || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery %s after %d attempts, want SUCCEEDED after 3", delivery.Status, delivery.Attempts)
+	}
+
+	got := rec.received()
+	if len(got) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(got))
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(got[0].body, &envelope); err != nil || envelope.Event != model.WebhookPing {
+		t.Fatalf("ping body %q: %v", got[0].body, err)
+	}
+	for _, req := range got {
+		if req.header.Get(SignatureHeader) != Sign(subscription.Secret, req.body) {
+			t.Fatalf("request not signed with the subscription's secret")
+		}
+	}
+
+	// A receiver that keeps failing marks the delivery FAILED on the last attempt
+	rec.mu.Lock()
+	rec.statuses = []int{http.StatusServiceUnavailable}
+	rec.mu.Unlock()
+	failing := ping(t, db, &subscription)
+	for n := 1; n <= MaxAttempts; n++ {
+		if err := attempt(t, d, failing, n); err == nil {
+			t.Fatalf("attempt %d succeeded against a failing receiver", n)
+		}
+		want := model.DeliveryPending
+		if n == MaxAttempts {
+			want = model.DeliveryFailed
+		}
+		if failing.Status != want {
+			t.Fatalf("attempt %d: delivery %s, want %s", n, failing.Status, want)
+		}
+	}
+	if failing.Error == nil {
+		t.Fatal("failed delivery has no error recorded")
+	}
+
+	// Redelivering sends the same event again as a new delivery
+	rec.mu.Lock()
+	rec.statuses = []int{http.StatusOK}
+	rec.mu.Unlock()
+	var redelivery *model.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		redelivery, err = Redeliver(tx, failing)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivery.ID == failing.ID || redelivery.RedeliveryOfID == nil || *redelivery.RedeliveryOfID != failing.ID {
+		t.Fatalf("redelivery %d points at %v, want a new delivery of %d", redelivery.ID, redelivery.RedeliveryOfID, failing.ID)
+	}
+	if err := attempt(t, d, redelivery, 1); err != nil {
+		t.Fatalf("redelivery attempt: %v", err)
+	}
+	if redelivery.Status != model.DeliverySucceeded {
+		t.Fatalf("redelivery %s, want SUCCEEDED", redelivery.Status)
+	}
+	got = rec.received()
+	if first, last := got[3].body, got[len(got)-1].body; string(first) != string(last) {
+		t.Fatalf("redelivered body %q differs from the original %q", last, first)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prkagrawal/cosmos-bk2/analytics"
+	"github.com/prkagrawal/cosmos-bk2/database"
+	"github.com/prkagrawal/cosmos-bk2/digests"
+	"github.com/prkagrawal/cosmos-bk2/jobs"
+	"github.com/prkagrawal/cosmos-bk2/lifecycle"
+	"github.com/prkagrawal/cosmos-bk2/mailer"
+	"github.com/prkagrawal/cosmos-bk2/maintenance"
+	"github.com/prkagrawal/cosmos-bk2/notifications"
+	"github.com/prkagrawal/cosmos-bk2/outbox"
+	"github.com/prkagrawal/cosmos-bk2/ratelimit"
+	"github.com/prkagrawal/cosmos-bk2/webhooks"
+	"github.com/rs/zerolog"
+)
+
+// newWorker builds a job worker with a handler for every kind of background job.
+func newWorker(logger *zerolog.Logger, mail mailer.Mailer, notifier *notifications.Notifier, hooks *webhooks.Dispatcher) (*jobs.Worker, error) {
+	worker, err := jobs.NewWorker(database.DB, logger)
+	if err != nil {
+		return nil, err
+	}
+	notifier.Register(worker)
+	hooks.Register(worker)
+	maintenance.New(database.DB, logger).Register(worker)
+	worker.Register(outbox.KindPrune, outbox.PruneHandler(database.DB))
+	worker.Register(jobs.KindPrune, jobs.PruneHandler(database.DB))
+	// Idle buckets pile up in Postgres when RATE_LIMIT_STORE=postgres; otherwise there are none
+	worker.Register(ratelimit.KindCleanup, ratelimit.NewPostgresStore(database.DB).CleanupHandler())
+	worker.Register(digests.KindSendDue, digests.Handler(database.DB, mail, logger))
+	worker.Register(lifecycle.KindPrefillShiftHours, func(ctx context.Context, job *jobs.Job) error {
+		n, err := lifecycle.PrefillShiftHours(database.DB, logger, time.Now())
+		if n > 0 {
+			logger.Info().Int("shifts", n).Msg("Pre-filled hours for ended shifts")
+		}
+		return err
+	})
+	worker.Register(analytics.KindRefreshRecent, func(ctx context.Context, job *jobs.Job) error {
+		return analytics.RefreshRecent(database.DB, time.Now())
+	})
+	return worker, nil
+}
+
+// newScheduler builds the scheduler for recurring jobs. Every process running a worker also runs
+// a scheduler; only one of them enqueues each run. Times are UTC.
+func newScheduler(logger *zerolog.Logger) (*jobs.Scheduler, error) {
+	scheduler := jobs.NewScheduler(database.DB, logger)
+	for _, s := range []struct{ spec, kind string }{
+		// Pre-fill hours for volunteers once their shifts end
+		{"*/5 * * * *", lifecycle.KindPrefillShiftHours},
+		// Keep the daily analytics rollup current for large nonprofits' reports
+		{"0 * * * *", analytics.KindRefreshRecent},
+		// Email digests of new matching projects, see digests.CheckInterval
+		{"0 * * * *", digests.KindSendDue},
+		{"0 2 * * *", maintenance.KindCompleteEndedProjects},
+		{"15 2 * * *", maintenance.KindExpireStaleApplications},
+		{"30 2 * * *", maintenance.KindIdleEngagements},
+		{"0 17 * * 5", maintenance.KindHoursReminders},
+		{"45 3 * * *", outbox.KindPrune},
+		{"50 3 * * *", jobs.KindPrune},
+		{"*/10 * * * *", ratelimit.KindCleanup},
+	} {
+		if err := scheduler.Add(s.kind, s.spec, s.kind, nil); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
+
+// newRelay builds the outbox relay handing domain events to notifications, webhooks and the
+// replicas' message subscriptions. Like the scheduler, it runs wherever a worker runs.
+func newRelay(logger *zerolog.Logger, notifier *notifications.Notifier, hooks *webhooks.Dispatcher) *outbox.Relay {
+	relay := outbox.NewRelay(database.DB, logger)
+	relay.Consume(notifications.ConsumerName, notifier.HandleEvent)
+	relay.Consume(webhooks.ConsumerName, hooks.HandleEvent)
+	relay.Consume(outbox.ConsumerPubSub, outbox.Broadcast)
+	return relay
+}
+
+// runWorker processes background jobs and runs the scheduler and outbox relay until the process
+// is interrupted, letting the jobs in progress finish first.
+func runWorker(logger *zerolog.Logger, mail mailer.Mailer, notifier *notifications.Notifier, hooks *webhooks.Dispatcher) {
+	worker, err := newWorker(logger, mail, notifier, hooks)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Job worker initialization failed")
+	}
+	scheduler, err := newScheduler(logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Job scheduler initialization failed")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go scheduler.Run(ctx)
+	go newRelay(logger, notifier, hooks).Run(ctx)
+	worker.Run(ctx)
+}